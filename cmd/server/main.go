@@ -1,41 +1,139 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"exercise-login-back-go/internal/api"
 	"exercise-login-back-go/internal/config"
-	"fmt"
-	"log"
+	"exercise-login-back-go/internal/logging"
+	"exercise-login-back-go/internal/tracing"
+	"exercise-login-back-go/pkg/db"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 )
 
 func main() {
+	// Log JSON records from the start; the level is known once the
+	// configuration is loaded.
+	level := new(slog.LevelVar)
+	slog.SetDefault(logging.New(os.Stdout, level))
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatal("Error loading configuration: ", err)
+		fatal("Error loading configuration", err)
+	}
+	level.Set(cfg.LogLevel)
+
+	// "server migrate ..." manages the schema instead of starting the server.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			fatal("Error running migrations", err)
+		}
+		return
+	}
+
+	if err := run(cfg); err != nil {
+		fatal("Web server stopped", err)
+	}
+}
+
+// run serves the API until the process receives SIGINT or SIGTERM, then
+// drains it and releases the database and the tracer before returning.
+func run(cfg config.Config) error {
+	// Trace the requests before the database is opened, so its queries are
+	// traced too.
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingExporter)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Error flushing traces", "error", err)
+		}
+	}()
+
+	database, err := db.InitializeDatabase(cfg.DBDriver, cfg.DBSource)
+	if err != nil {
+		return err
 	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			slog.Error("Error closing the database", "error", err)
+		}
+	}()
+
 	// Create a Gin router
 	r := mux.NewRouter()
 
 	// Set up routes passing the database connection
-	err = api.SetupRoutes(&cfg, r)
+	healthService, err := api.SetupRoutes(&cfg, database, r)
 	if err != nil {
-		log.Fatal("Error setting up routes: ", err)
+		return err
 	}
 	// Define allowed headers, methods, and origins for CORS responses
 	headersOk := handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"})
-	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
+	methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS"})
 	originsOk := handlers.AllowedOrigins([]string{"*"}) // Adjust this to be more restrictive if necessary
 
 	// Wrap the router with CORS middleware
 	corsRouter := handlers.CORS(originsOk, headersOk, methodsOk)(r)
 
+	server := &http.Server{
+		Addr:              cfg.HTTPServer.Addr,
+		Handler:           corsRouter,
+		ReadHeaderTimeout: cfg.HTTPServer.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTPServer.ReadTimeout,
+		WriteTimeout:      cfg.HTTPServer.WriteTimeout,
+		IdleTimeout:       cfg.HTTPServer.IdleTimeout,
+		MaxHeaderBytes:    cfg.HTTPServer.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Start the HTTP server
-	port := ":80"
-	fmt.Println("Web server started on port", port)
-	if err := http.ListenAndServe(port, corsRouter); err != nil {
-		log.Println(err)
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Web server started", "address", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
 	}
+	// A second signal stops the process without waiting for the drain.
+	stop()
+
+	// Report not ready and keep serving while the load balancer notices,
+	// then stop accepting connections and wait for the in-flight requests.
+	slog.Info("Shutting down, draining the web server", "drainPeriod", cfg.HTTPServer.DrainPeriod.String())
+	healthService.Drain()
+	time.Sleep(cfg.HTTPServer.DrainPeriod)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-serverErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("Web server stopped")
+	return nil
+}
+
+// fatal logs an error that keeps the server from running and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"exercise-login-back-go/internal/config"
+	"exercise-login-back-go/internal/migrations"
+	"exercise-login-back-go/pkg/db"
+	"fmt"
+	"strconv"
+)
+
+const migrateUsage = "usage: server migrate up | down [steps] | status | baseline <version>"
+
+// runMigrate runs the "migrate" subcommand, which manages the schema
+// migrations without starting the server.
+func runMigrate(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	database, err := db.InitializeDatabase(cfg.DBDriver, cfg.DBSource)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	migrator, err := migrations.New(database, cfg.DBDriver)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("the database is up to date")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return errors.New(migrateUsage)
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+		return nil
+	case "baseline":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.New(migrateUsage)
+		}
+		return migrator.Baseline(ctx, version)
+	}
+	return errors.New(migrateUsage)
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// errorStatuses maps each kind of service error to its HTTP status
+var errorStatuses = map[services.ErrorKind]int{
+	services.KindValidation:   http.StatusBadRequest,
+	services.KindUnauthorized: http.StatusUnauthorized,
+	services.KindForbidden:    http.StatusForbidden,
+	services.KindNotFound:     http.StatusNotFound,
+	services.KindConflict:     http.StatusConflict,
+	services.KindLocked:       http.StatusLocked,
+	services.KindUpstream:     http.StatusBadGateway,
+	services.KindUnavailable:  http.StatusServiceUnavailable,
+	services.KindInternal:     http.StatusInternalServerError,
+}
+
+// timedOut reports whether a request failed because it ran out of time.
+// Drivers do not always return the context error of a cancelled query, so a
+// failure after the deadline of the request counts too.
+func timedOut(r *http.Request, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(r.Context().Err(), context.DeadlineExceeded)
+}
+
+// serviceError returns the HTTP status and the typed error behind err.
+// Requests that ran out of time are reported as unavailable, so clients can
+// retry them. Untyped errors are unexpected failures: they are logged and
+// reported as internal errors so their details never reach the client.
+func serviceError(r *http.Request, err error) (int, *services.Error) {
+	if timedOut(r, err) {
+		slog.WarnContext(r.Context(), "Request timed out", "error", err)
+		return http.StatusServiceUnavailable, services.ErrTimeout
+	}
+	var serviceErr *services.Error
+	if !errors.As(err, &serviceErr) {
+		slog.ErrorContext(r.Context(), "Request failed", "error", err)
+		return http.StatusInternalServerError, &services.Error{Kind: services.KindInternal, Code: "internal_error", Key: "internal_error"}
+	}
+	if serviceErr.Kind == services.KindInternal {
+		slog.ErrorContext(r.Context(), "Request failed", "error", err)
+	}
+	status, ok := errorStatuses[serviceErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	return status, serviceErr
+}
+
+// respondWithServiceError sends the status, code and message of a service
+// error as problem details
+func respondWithServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	status, serviceErr := serviceError(r, err)
+	problem := model.Problem{
+		Status:        status,
+		Code:          serviceErr.Code,
+		MessageKey:    serviceErr.Key,
+		MessageParams: serviceErr.Params,
+	}
+	if serviceErr.Field != "" {
+		problem.InvalidParams = []model.InvalidParam{{Name: serviceErr.Field, Key: serviceErr.Key, Params: serviceErr.Params}}
+	}
+	respondWithProblem(w, r, problem)
+}
+
+// respondWithInternalError reports an unexpected failure with the message of
+// key, or as unavailable when the request ran out of time
+func respondWithInternalError(w http.ResponseWriter, r *http.Request, err error, key string) {
+	if timedOut(r, err) {
+		respondWithServiceError(w, r, err)
+		return
+	}
+	respondWithError(w, r, http.StatusInternalServerError, key)
+}
+
+// respondWithError sends the message of key as problem details. The code of
+// the problem is the key without the variant.
+func respondWithError(w http.ResponseWriter, r *http.Request, status int, key string) {
+	respondWithLocalizedError(w, r, status, key, nil)
+}
+
+// respondWithLocalizedError sends the message of key, with its placeholders
+// replaced by params, as problem details
+func respondWithLocalizedError(w http.ResponseWriter, r *http.Request, status int, key string, params i18n.Params) {
+	code, _, _ := strings.Cut(key, ".")
+	respondWithProblem(w, r, model.Problem{Status: status, Code: code, MessageKey: key, MessageParams: params})
+}
+
+// respondWithInvalidParams rejects a request with the reason each invalid
+// parameter was rejected
+func respondWithInvalidParams(w http.ResponseWriter, r *http.Request, params []model.InvalidParam) {
+	respondWithProblem(w, r, model.Problem{
+		Status:        http.StatusBadRequest,
+		Code:          "invalid_request",
+		MessageKey:    "invalid_request.params",
+		InvalidParams: params,
+	})
+}
+
+// respondWithProblem sends an RFC 7807 problem details response. Problems
+// without a type are described by their status, as "about:blank" requires.
+// The detail and the reasons of the invalid parameters are the messages of
+// their keys in the locale of the request.
+func respondWithProblem(w http.ResponseWriter, r *http.Request, problem model.Problem) {
+	if problem.Detail == "" && problem.MessageKey != "" {
+		problem.Detail = localize(r, problem.MessageKey, problem.MessageParams)
+	}
+	for i, param := range problem.InvalidParams {
+		if param.Reason == "" && param.Key != "" {
+			problem.InvalidParams[i].Reason = localize(r, param.Key, param.Params)
+		}
+		if param.Code == "" && param.Key != "" {
+			problem.InvalidParams[i].Code, _, _ = strings.Cut(param.Key, ".")
+		}
+	}
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.Instance == "" {
+		problem.Instance = r.URL.Path
+	}
+	problem.RequestID = requestIDFromContext(r.Context())
+
+	response, _ := json.Marshal(problem)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("Content-Language", string(i18n.FromContext(r.Context())))
+	w.WriteHeader(problem.Status)
+	w.Write(response)
+}
+
+// redirectError returns the error code and description sent to the frontend
+// redirect URL when a sign in with an upstream provider fails
+func redirectError(r *http.Request, err error) (string, string) {
+	_, serviceErr := serviceError(r, err)
+	if serviceErr.Kind == services.KindInternal {
+		return "server_error", localize(r, "server_error", nil)
+	}
+	return serviceErr.Code, localize(r, serviceErr.Key, serviceErr.Params)
+}
+
+// localize returns the message of key in the locale of the request
+func localize(r *http.Request, key string, params i18n.Params) string {
+	return i18n.Message(i18n.FromContext(r.Context()), key, params)
+}
+
+// notFound answers requests that match no route
+func notFound(w http.ResponseWriter, r *http.Request) {
+	respondWithError(w, r, http.StatusNotFound, "not_found")
+}
+
+// methodNotAllowed answers requests whose route does not accept the method
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	respondWithError(w, r, http.StatusMethodNotAllowed, "method_not_allowed")
+}
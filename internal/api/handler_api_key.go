@@ -0,0 +1,76 @@
+package api
+
+import (
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyService
+}
+
+// apiKeyCreatedResponse includes the full key, which is only shown once
+type apiKeyCreatedResponse struct {
+	model.APIKey
+	Key string `json:"key"`
+}
+
+// NewAPIKeyHandler creates a new instance of APIKeyHandler
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// CreateAPIKey issues a new API key for the authenticated user
+func (ah *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req model.APIKeyCreateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if !validateRequest(w, r, req) {
+		return
+	}
+
+	claims := claimsFromContext(r.Context())
+	key, secret, err := ah.apiKeyService.CreateAPIKey(r.Context(), claims.UserID, claims.Username, req, requestMetadata(r))
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, apiKeyCreatedResponse{APIKey: *key, Key: secret})
+}
+
+// GetAPIKeys lists the API keys of the authenticated user
+func (ah *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	keys, err := ah.apiKeyService.GetAPIKeys(r.Context(), claims.UserID)
+	if err != nil {
+		respondWithInternalError(w, r, err, "internal_error.api_keys")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes one of the API keys of the authenticated user
+func (ah *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithServiceError(w, r, services.ErrAPIKeyNotFound)
+		return
+	}
+
+	claims := claimsFromContext(r.Context())
+	err = ah.apiKeyService.RevokeAPIKey(r.Context(), claims.UserID, claims.Username, keyID, requestMetadata(r))
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
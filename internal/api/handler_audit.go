@@ -0,0 +1,76 @@
+package api
+
+import (
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AuditHandler struct {
+	auditLogger services.AuditLogger
+}
+
+// NewAuditHandler creates a new instance of AuditHandler
+func NewAuditHandler(auditLogger services.AuditLogger) *AuditHandler {
+	return &AuditHandler{auditLogger: auditLogger}
+}
+
+// GetAuditEvents lists audit events, optionally filtered by user and time range
+func (ah *AuditHandler) GetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	filter, errs := parseAuditEventFilter(r)
+	if len(errs) > 0 {
+		respondWithInvalidParams(w, r, errs)
+		return
+	}
+
+	events, err := ah.auditLogger.GetEvents(r.Context(), filter)
+	if err != nil {
+		respondWithInternalError(w, r, err, "internal_error.audit_events")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, events)
+}
+
+// parseAuditEventFilter reads the userId, from, to and limit query parameters
+func parseAuditEventFilter(r *http.Request) (model.AuditEventFilter, []model.InvalidParam) {
+	var filter model.AuditEventFilter
+	var errs []model.InvalidParam
+	query := r.URL.Query()
+
+	if value := query.Get("userId"); value != "" {
+		userID, err := strconv.Atoi(value)
+		if err != nil || userID <= 0 {
+			errs = append(errs, model.InvalidParam{Name: "userId", Key: "invalid_param", Params: map[string]string{"name": "userId"}})
+		}
+		filter.UserID = userID
+	}
+	if value := query.Get("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errs = append(errs, model.InvalidParam{Name: "from", Key: "invalid_param.timestamp", Params: map[string]string{"name": "from"}})
+		}
+		filter.From = from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errs = append(errs, model.InvalidParam{Name: "to", Key: "invalid_param.timestamp", Params: map[string]string{"name": "to"}})
+		}
+		filter.To = to
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			errs = append(errs, model.InvalidParam{Name: "limit", Key: "invalid_param", Params: map[string]string{"name": "limit"}})
+		}
+		filter.Limit = limit
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		errs = append(errs, model.InvalidParam{Name: "to", Key: "invalid_param.range"})
+	}
+
+	return filter, errs
+}
@@ -0,0 +1,50 @@
+package api_test
+
+import (
+	"exercise-login-back-go/internal/api"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAuditEvents(t *testing.T) {
+	t.Run("success with filters", func(t *testing.T) {
+		mockAuditLogger := new(mocks.AuditLogger)
+		handler := api.NewAuditHandler(mockAuditLogger)
+
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		mockAuditLogger.On("GetEvents", mock.Anything, model.AuditEventFilter{UserID: 7, From: from, To: to, Limit: 10}).
+			Return([]model.AuditEvent{{ID: 1, EventType: model.AuditEventLogin, UserID: 7, Outcome: model.AuditOutcomeSuccess}}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/admin/audit-events?userId=7&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&limit=10", nil)
+		resp := httptest.NewRecorder()
+
+		handler.GetAuditEvents(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"eventType":"login"`)
+		mockAuditLogger.AssertExpectations(t)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		mockAuditLogger := new(mocks.AuditLogger)
+		handler := api.NewAuditHandler(mockAuditLogger)
+
+		req, _ := http.NewRequest("GET", "/api/admin/audit-events?userId=abc&from=yesterday", nil)
+		resp := httptest.NewRecorder()
+
+		handler.GetAuditEvents(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "userId")
+		assert.Contains(t, resp.Body.String(), "from")
+		mockAuditLogger.AssertNotCalled(t, "GetEvents", mock.Anything)
+	})
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+)
+
+type HealthHandler struct {
+	healthService services.HealthService
+}
+
+// NewHealthHandler creates a new instance of HealthHandler
+func NewHealthHandler(healthService services.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// Liveness answers while the process can serve requests at all, without
+// checking its dependencies: restarting the server would not fix them.
+func (hh *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	setNoStore(w)
+	respondWithJSON(w, http.StatusOK, model.HealthReport{Status: model.HealthStatusOK})
+}
+
+// Readiness reports whether the server can take traffic, with the status of
+// each dependency. It answers 503 while a dependency is unavailable or the
+// server is draining.
+func (hh *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := hh.healthService.Readiness(r.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	setNoStore(w)
+	respondWithJSON(w, status, report)
+}
@@ -0,0 +1,52 @@
+package api_test
+
+import (
+	"exercise-login-back-go/internal/api"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHealth(t *testing.T) {
+	t.Run("liveness does not check the dependencies", func(t *testing.T) {
+		mockService := new(mocks.HealthService)
+		resp := httptest.NewRecorder()
+
+		api.NewHealthHandler(mockService).Liveness(resp, httptest.NewRequest("GET", "/healthz", nil))
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, `{"status":"ok"}`, resp.Body.String())
+		mockService.AssertNotCalled(t, "Readiness", mock.Anything)
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		mockService := new(mocks.HealthService)
+		mockService.On("Readiness", mock.Anything).Return(model.HealthReport{
+			Status: model.HealthStatusOK,
+			Checks: map[string]model.HealthCheck{"database": {Status: model.HealthStatusOK, DurationMs: 3}},
+		})
+		resp := httptest.NewRecorder()
+
+		api.NewHealthHandler(mockService).Readiness(resp, httptest.NewRequest("GET", "/readyz", nil))
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "no-store", resp.Header().Get("Cache-Control"))
+		assert.JSONEq(t, `{"status":"ok","checks":{"database":{"status":"ok","durationMs":3}}}`, resp.Body.String())
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		mockService := new(mocks.HealthService)
+		mockService.On("Readiness", mock.Anything).Return(model.HealthReport{Status: model.HealthStatusDraining})
+		resp := httptest.NewRecorder()
+
+		api.NewHealthHandler(mockService).Readiness(resp, httptest.NewRequest("GET", "/readyz", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+		assert.JSONEq(t, `{"status":"draining"}`, resp.Body.String())
+	})
+}
@@ -2,10 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"exercise-login-back-go/internal/i18n"
 	"exercise-login-back-go/internal/model"
 	"exercise-login-back-go/internal/services"
 	"net/http"
-	"strings"
 )
 
 type UserHandler struct {
@@ -21,51 +21,42 @@ func NewUserHandler(userService services.UserService) *UserHandler {
 func (uh *UserHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	// req is the incoming request body
 	var req model.UserRegistrationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Error al decodificar la solicitud")
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// validate the incoming request
-	if errs := validateRegistrationRequest(req); len(errs) > 0 {
-		respondWithMultipleErrors(w, http.StatusBadRequest, errs)
+	if !validateRequest(w, r, req) {
 		return
 	}
 
 	// register the user
-	if err := uh.userService.RegisterUser(req); err != nil {
-		respondWithError(w, http.StatusConflict, err.Error())
+	if err := uh.userService.RegisterUser(r.Context(), req, requestMetadata(r)); err != nil {
+		respondWithServiceError(w, r, err)
 		return
 	}
 
 	// return a successful response
-	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Usuario registrado exitosamente"})
+	respondWithMessage(w, r, http.StatusOK, "user_registered")
 }
 
 // LoginUser handles user login requests
 func (uh *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	// req is the incoming request body
 	var loginReq model.UserLoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&loginReq); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Error al decodificar el cuerpo de la solicitud")
+	if !decodeJSON(w, r, &loginReq) {
 		return
 	}
 
 	// validate request
-	if strings.TrimSpace(loginReq.EmailOrUsername) == "" {
-		respondWithError(w, http.StatusBadRequest, "Falta el campo email o nombre de usuario")
-		return
-	}
-	if strings.TrimSpace(loginReq.Password) == "" {
-		respondWithError(w, http.StatusBadRequest, "Falta el campo contraseña")
+	if !validateRequest(w, r, loginReq) {
 		return
 	}
 
 	// attempt to login user
-	token, err := uh.userService.LoginUser(loginReq.EmailOrUsername, loginReq.Password)
+	token, err := uh.userService.LoginUser(r.Context(), loginReq.EmailOrUsername, loginReq.Password, requestMetadata(r))
 	if err != nil {
-		// Manejar error.
-		respondWithError(w, http.StatusUnauthorized, "usuario / contraseña incorrectos")
+		respondWithServiceError(w, r, err)
 		return
 	}
 
@@ -73,32 +64,33 @@ func (uh *UserHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, map[string]string{"token": token})
 }
 
-// validateRegistrationRequest validates the incoming user registration request
-func validateRegistrationRequest(req model.UserRegistrationRequest) []string {
-	var errs []string
-	if strings.TrimSpace(req.Username) == "" {
-		errs = append(errs, "Falta el campo nombre de usuario")
-	}
-	if strings.TrimSpace(req.Email) == "" {
-		errs = append(errs, "Falta el campo email")
-	}
-	if strings.TrimSpace(req.Phone) == "" {
-		errs = append(errs, "Falta el campo teléfono")
+// UpdateLocale saves the language the authenticated user prefers for messages
+func (uh *UserHandler) UpdateLocale(w http.ResponseWriter, r *http.Request) {
+	var req model.UserLocaleRequest
+	if !decodeJSON(w, r, &req) {
+		return
 	}
-	if strings.TrimSpace(req.Password) == "" {
-		errs = append(errs, "Falta el campo contraseña")
+
+	claims := claimsFromContext(r.Context())
+	if err := uh.userService.UpdateLocale(r.Context(), claims.UserID, req.Locale); err != nil {
+		respondWithServiceError(w, r, err)
+		return
 	}
-	return errs
-}
 
-// respondWithError sends a response with an error message in Json format
-func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"message": message})
+	// Answer in the new language, or in the one of the request when the
+	// preference was removed.
+	locale := i18n.MatchAcceptLanguage(r.Header.Get("Accept-Language"))
+	if preferred, ok := i18n.ParseLocale(req.Locale); ok {
+		locale = preferred
+	}
+	respondWithMessage(w, r.WithContext(i18n.NewContext(r.Context(), locale)), http.StatusOK, "locale_updated")
 }
 
-// respondWithMultipleErrors sends a response with multiple error messages in JSON format
-func respondWithMultipleErrors(w http.ResponseWriter, code int, errs []string) {
-	respondWithJSON(w, code, map[string][]string{"errors": errs})
+// respondWithMessage sends the message of key in the locale of the request,
+// together with the key so clients can localize it themselves
+func respondWithMessage(w http.ResponseWriter, r *http.Request, code int, key string) {
+	w.Header().Set("Content-Language", string(i18n.FromContext(r.Context())))
+	respondWithJSON(w, code, map[string]string{"message": localize(r, key, nil), "messageKey": key})
 }
 
 // respondWithJSON sends a response with a JSON payload.
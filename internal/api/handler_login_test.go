@@ -2,13 +2,19 @@ package api_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"exercise-login-back-go/internal/api"
+	"exercise-login-back-go/internal/i18n"
 	"exercise-login-back-go/internal/mocks"
 	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/repositories"
+	"exercise-login-back-go/internal/services"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,15 +26,15 @@ func TestRegisterUser(t *testing.T) {
 	handler := api.NewUserHandler(mockUserService)
 
 	t.Run("success", func(t *testing.T) {
-		mockUserService.On("RegisterUser", mock.AnythingOfType("model.UserRegistrationRequest")).Return(nil)
+		mockUserService.On("RegisterUser", mock.Anything, mock.AnythingOfType("model.UserRegistrationRequest"), mock.AnythingOfType("model.RequestMetadata")).Return(nil)
 
 		body, _ := json.Marshal(model.UserRegistrationRequest{
 			Username: "testuser",
 			Email:    "test@example.com",
 			Phone:    "1234567890",
-			Password: "Password!23",
+			Password: "Password@23",
 		})
-		req, _ := http.NewRequest("POST", "/api/users/register", bytes.NewBuffer(body))
+		req := newJSONRequest("/api/users/register", body)
 		resp := httptest.NewRecorder()
 
 		handler.RegisterUser(resp, req)
@@ -41,7 +47,7 @@ func TestRegisterUser(t *testing.T) {
 
 	t.Run("error decoding", func(t *testing.T) {
 		body := []byte(`{bad json}`)
-		req, _ := http.NewRequest("POST", "/api/users/register", bytes.NewBuffer(body))
+		req := newJSONRequest("/api/users/register", body)
 		resp := httptest.NewRecorder()
 
 		handler.RegisterUser(resp, req)
@@ -52,15 +58,13 @@ func TestRegisterUser(t *testing.T) {
 	})
 
 	t.Run("validation errors", func(t *testing.T) {
-		mockUserService.On("RegisterUser", mock.AnythingOfType("model.UserRegistrationRequest")).Return(errors.New("validation failed"))
-
 		body, _ := json.Marshal(model.UserRegistrationRequest{
 			Username: "testuser",
 			Email:    "",
 			Phone:    "1234567890",
-			Password: "Password!23",
+			Password: "Password@23",
 		})
-		req, _ := http.NewRequest("POST", "/api/users/register", bytes.NewBuffer(body))
+		req := newJSONRequest("/api/users/register", body)
 		resp := httptest.NewRecorder()
 
 		handler.RegisterUser(resp, req)
@@ -68,6 +72,39 @@ func TestRegisterUser(t *testing.T) {
 		responseBody := resp.Body.String()
 		t.Log("Validation error response body:", responseBody)
 		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+
+		var problem model.Problem
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+		assert.Equal(t, "/api/users/register", problem.Instance)
+		assert.Equal(t, []model.InvalidParam{{Name: "email", Reason: "Falta el campo email", Code: "missing_field", Key: "missing_field", Params: map[string]string{"field": "email"}}}, problem.InvalidParams)
+	})
+
+	t.Run("every invalid field is reported", func(t *testing.T) {
+		body, _ := json.Marshal(model.UserRegistrationRequest{
+			Email:    "not-an-email",
+			Phone:    "123",
+			Password: "short",
+		})
+		req := newJSONRequest("/api/users/register", body)
+		resp := httptest.NewRecorder()
+
+		handler.RegisterUser(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		var problem model.Problem
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+		assert.Equal(t, "invalid_request", problem.Code)
+		codes := map[string]string{}
+		for _, param := range problem.InvalidParams {
+			codes[param.Name] = param.Code
+		}
+		assert.Equal(t, map[string]string{
+			"username": "missing_field",
+			"email":    "invalid_email",
+			"phone":    "invalid_phone",
+			"password": "invalid_password",
+		}, codes)
 	})
 }
 
@@ -77,13 +114,13 @@ func TestLoginUser(t *testing.T) {
 
 	t.Run("login success", func(t *testing.T) {
 		expectedToken := "fakeToken123"
-		mockUserService.On("LoginUser", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(expectedToken, nil)
+		mockUserService.On("LoginUser", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("model.RequestMetadata")).Return(expectedToken, nil)
 
 		body, _ := json.Marshal(model.UserLoginRequest{
 			EmailOrUsername: "test@example.com",
-			Password:        "Password!23",
+			Password:        "Password@23",
 		})
-		req, _ := http.NewRequest("POST", "/api/users/login", bytes.NewBuffer(body))
+		req := newJSONRequest("/api/users/login", body)
 		resp := httptest.NewRecorder()
 
 		handler.LoginUser(resp, req)
@@ -94,27 +131,27 @@ func TestLoginUser(t *testing.T) {
 
 	t.Run("error decoding request body", func(t *testing.T) {
 		body := []byte(`{bad json}`)
-		req, _ := http.NewRequest("POST", "/api/users/login", bytes.NewBuffer(body))
+		req := newJSONRequest("/api/users/login", body)
 		resp := httptest.NewRecorder()
 
 		handler.LoginUser(resp, req)
 
 		assert.Equal(t, http.StatusBadRequest, resp.Code)
-		assert.Contains(t, resp.Body.String(), "Error al decodificar el cuerpo de la solicitud")
+		assert.Contains(t, resp.Body.String(), "JSON inválido en la línea 1, columna 2")
 	})
 
 	t.Run("missing email or username", func(t *testing.T) {
 		body, _ := json.Marshal(model.UserLoginRequest{
 			EmailOrUsername: "",
-			Password:        "Password!23",
+			Password:        "Password@23",
 		})
-		req, _ := http.NewRequest("POST", "/api/users/login", bytes.NewBuffer(body))
+		req := newJSONRequest("/api/users/login", body)
 		resp := httptest.NewRecorder()
 
 		handler.LoginUser(resp, req)
 
 		assert.Equal(t, http.StatusBadRequest, resp.Code)
-		assert.Contains(t, resp.Body.String(), "Falta el campo email o nombre de usuario")
+		assert.Contains(t, resp.Body.String(), "Falta el campo emailOrUsername")
 	})
 
 	t.Run("missing password", func(t *testing.T) {
@@ -122,30 +159,205 @@ func TestLoginUser(t *testing.T) {
 			EmailOrUsername: "test@example.com",
 			Password:        "",
 		})
-		req, _ := http.NewRequest("POST", "/api/users/login", bytes.NewBuffer(body))
+		req := newJSONRequest("/api/users/login", body)
 		resp := httptest.NewRecorder()
 
 		handler.LoginUser(resp, req)
 
 		assert.Equal(t, http.StatusBadRequest, resp.Code)
-		assert.Contains(t, resp.Body.String(), "Falta el campo contraseña")
+		assert.Contains(t, resp.Body.String(), "Falta el campo password")
 	})
 
 	t.Run("invalid credentials", func(t *testing.T) {
 		mockUserService.ExpectedCalls = nil
 		mockUserService.Calls = nil
 
-		mockUserService.On("LoginUser", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return("", errors.New("usuario / contraseña incorrectos"))
+		mockUserService.On("LoginUser", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("string"), mock.AnythingOfType("model.RequestMetadata")).Return("", services.ErrInvalidCredentials.Wrap(errors.New("contraseña incorrecta")))
+
+		body, _ := json.Marshal(model.UserLoginRequest{
+			EmailOrUsername: "wrong@example.com",
+			Password:        "wrongPassword",
+		})
+		req := newJSONRequest("/api/users/login", body)
+		resp := httptest.NewRecorder()
+
+		handler.LoginUser(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Unauthorized",
+			"status": 401,
+			"detail": "usuario / contraseña incorrectos",
+			"instance": "/api/users/login",
+			"code": "invalid_credentials",
+			"messageKey": "invalid_credentials"
+		}`, resp.Body.String())
+	})
 
+	t.Run("invalid credentials in english", func(t *testing.T) {
 		body, _ := json.Marshal(model.UserLoginRequest{
 			EmailOrUsername: "wrong@example.com",
 			Password:        "wrongPassword",
 		})
-		req, _ := http.NewRequest("POST", "/api/users/login", bytes.NewBuffer(body))
+		req := newJSONRequest("/api/users/login", body)
+		req = req.WithContext(i18n.NewContext(req.Context(), i18n.English))
 		resp := httptest.NewRecorder()
 
 		handler.LoginUser(resp, req)
 		assert.Equal(t, http.StatusUnauthorized, resp.Code)
-		assert.Contains(t, resp.Body.String(), "usuario / contraseña incorrectos")
+		assert.Equal(t, "en", resp.Header().Get("Content-Language"))
+		assert.Contains(t, resp.Body.String(), `"detail":"incorrect username / password"`)
+		assert.Contains(t, resp.Body.String(), `"messageKey":"invalid_credentials"`)
 	})
 }
+
+func TestUserHandlerServiceErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"conflict", services.ErrUserAlreadyExists, http.StatusConflict, "user_already_exists"},
+		{"disabled user", services.ErrUserDisabled, http.StatusLocked, "user_disabled"},
+		{"directory down", services.ErrDirectoryUnavailable, http.StatusBadGateway, "directory_unavailable"},
+		{"database outage", errors.New("dial tcp: connection refused"), http.StatusInternalServerError, "internal_error"},
+		{"database timeout", fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusServiceUnavailable, "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserService := new(mocks.UserService)
+			handler := api.NewUserHandler(mockUserService)
+			mockUserService.On("RegisterUser", mock.Anything, mock.Anything, mock.Anything).Return(tt.err)
+			mockUserService.On("LoginUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("", tt.err)
+
+			body, _ := json.Marshal(model.UserRegistrationRequest{Username: "jdoe", Email: "jdoe@example.com", Phone: "5512345678", Password: "Password@1"})
+			resp := httptest.NewRecorder()
+			handler.RegisterUser(resp, newJSONRequest("/api/users/register", body))
+			assert.Equal(t, tt.status, resp.Code)
+			assert.Contains(t, resp.Body.String(), `"code":"`+tt.code+`"`)
+			assert.NotContains(t, resp.Body.String(), "connection refused")
+
+			body, _ = json.Marshal(model.UserLoginRequest{EmailOrUsername: "jdoe", Password: "Password@1"})
+			resp = httptest.NewRecorder()
+			handler.LoginUser(resp, newJSONRequest("/api/users/login", body))
+			assert.Equal(t, tt.status, resp.Code)
+			assert.Contains(t, resp.Body.String(), `"code":"`+tt.code+`"`)
+		})
+	}
+}
+
+func TestLoginUserRequestBody(t *testing.T) {
+	handler := api.NewUserHandler(new(mocks.UserService))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		key         string
+		params      map[string]string
+		field       string
+	}{
+		{"missing content type", "", `{}`, http.StatusUnsupportedMediaType, "unsupported_media_type", map[string]string{"mediaType": "application/json"}, ""},
+		{"form content type", "application/x-www-form-urlencoded", `{}`, http.StatusUnsupportedMediaType, "unsupported_media_type", map[string]string{"mediaType": "application/json"}, ""},
+		{"empty body", "application/json", " \n", http.StatusBadRequest, "invalid_body.empty", nil, ""},
+		{"syntax error", "application/json; charset=utf-8", "{\n  \"password\": \"x\",\n  oops\n}", http.StatusBadRequest, "invalid_body.syntax", map[string]string{"line": "3", "column": "3"}, ""},
+		{"truncated body", "application/json", `{"password": "x"`, http.StatusBadRequest, "invalid_body.syntax", map[string]string{"line": "1", "column": "17"}, ""},
+		{"unknown field", "application/json", `{"emailOrUsername": "jdoe", "pasword": "x"}`, http.StatusBadRequest, "invalid_body.unknown_field", map[string]string{"field": "pasword"}, "pasword"},
+		{"wrong type", "application/json", `{"emailOrUsername": 42}`, http.StatusBadRequest, "invalid_body.type", map[string]string{"field": "emailOrUsername", "type": "string", "line": "1", "column": "22"}, "emailOrUsername"},
+		{"not an object", "application/json", `["jdoe"]`, http.StatusBadRequest, "invalid_body.root_type", map[string]string{"type": "object", "line": "1", "column": "1"}, ""},
+		{"trailing value", "application/json", `{"emailOrUsername": "jdoe"} {"password": "x"}`, http.StatusBadRequest, "invalid_body.trailing_data", map[string]string{"line": "1", "column": "29"}, ""},
+		{"trailing garbage", "application/json", "{}\n]", http.StatusBadRequest, "invalid_body.trailing_data", map[string]string{"line": "2", "column": "1"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/users/login", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			resp := httptest.NewRecorder()
+			handler.LoginUser(resp, req)
+
+			assert.Equal(t, tt.status, resp.Code)
+			var problem model.Problem
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+			assert.Equal(t, tt.key, problem.MessageKey)
+			assert.Equal(t, tt.params, problem.MessageParams)
+			if tt.field != "" {
+				assert.Len(t, problem.InvalidParams, 1)
+				assert.Equal(t, tt.field, problem.InvalidParams[0].Name)
+			}
+		})
+	}
+
+	t.Run("body too large", func(t *testing.T) {
+		req := newJSONRequest("/api/users/login", []byte(`{"emailOrUsername": "jdoe", "password": "Password@23"}`))
+		resp := httptest.NewRecorder()
+		req.Body = http.MaxBytesReader(resp, req.Body, 16)
+		handler.LoginUser(resp, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+		assert.Contains(t, resp.Body.String(), "El cuerpo de la solicitud supera el máximo de 16 bytes")
+	})
+}
+
+// newJSONRequest builds a POST request with a JSON body
+func TestRegisterAndLoginWithMemoryRepository(t *testing.T) {
+	repo := repositories.NewMemoryUserRepository()
+	mockSessions := new(mocks.SessionService)
+	mockAlerts := new(mocks.LoginAlertService)
+	mockAudit := new(mocks.AuditLogger)
+	userService := services.NewUserService(repo, services.NewPasswordAuthenticator(repo), mockSessions, mockAlerts, mockAudit, "dummySecret")
+	handler := api.NewUserHandler(userService)
+	mockAudit.On("LogEvent", mock.Anything, mock.Anything).Return()
+
+	register := func(req model.UserRegistrationRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		resp := httptest.NewRecorder()
+		handler.RegisterUser(resp, newJSONRequest("/api/users/register", body))
+		return resp
+	}
+
+	t.Run("register", func(t *testing.T) {
+		resp := register(model.UserRegistrationRequest{Username: "jdoe", Email: "jdoe@example.com", Phone: "5512345678", Password: "Password@1"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("duplicate email", func(t *testing.T) {
+		resp := register(model.UserRegistrationRequest{Username: "other", Email: "jdoe@example.com", Phone: "5587654321", Password: "Password@1"})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"code":"user_already_exists"`)
+	})
+
+	t.Run("duplicate phone", func(t *testing.T) {
+		resp := register(model.UserRegistrationRequest{Username: "other", Email: "other@example.com", Phone: "5512345678", Password: "Password@1"})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("login", func(t *testing.T) {
+		mockAlerts.On("CheckLogin", mock.Anything, mock.AnythingOfType("model.User"), mock.AnythingOfType("model.RequestMetadata")).Return()
+		mockSessions.On("CreateSession", mock.Anything, mock.MatchedBy(func(user model.User) bool { return user.Username == "jdoe" }), mock.AnythingOfType("model.RequestMetadata"), mock.AnythingOfType("time.Time")).
+			Return(&model.Session{ID: "session-1", UserID: 1}, nil)
+
+		body, _ := json.Marshal(model.UserLoginRequest{EmailOrUsername: "jdoe@example.com", Password: "Password@1"})
+		resp := httptest.NewRecorder()
+		handler.LoginUser(resp, newJSONRequest("/api/users/login", body))
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"token"`)
+
+		body, _ = json.Marshal(model.UserLoginRequest{EmailOrUsername: "jdoe", Password: "Wrong@123"})
+		resp = httptest.NewRecorder()
+		handler.LoginUser(resp, newJSONRequest("/api/users/login", body))
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+}
+
+func newJSONRequest(target string, body []byte) *http.Request {
+	req := httptest.NewRequest("POST", target, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"errors"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type OAuthHandler struct {
+	oauthService services.OAuthService
+	userService  services.UserService
+	consentURL   string
+}
+
+// authorizationDecision is the body sent by the consent page
+type authorizationDecision struct {
+	model.AuthorizationRequest
+	Approve bool `json:"approve"`
+}
+
+// consentResponse describes an authorization request to the consent page
+type consentResponse struct {
+	ClientID    string   `json:"clientId"`
+	ClientName  string   `json:"clientName"`
+	RedirectURI string   `json:"redirectUri"`
+	Scopes      []string `json:"scopes"`
+}
+
+// oauthClientCreatedResponse includes the client secret, which is only shown once
+type oauthClientCreatedResponse struct {
+	model.OAuthClient
+	ClientSecret string `json:"clientSecret,omitempty"`
+}
+
+// NewOAuthHandler creates a new instance of OAuthHandler
+func NewOAuthHandler(oauthService services.OAuthService, userService services.UserService, consentURL string) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+		userService:  userService,
+		consentURL:   consentURL,
+	}
+}
+
+// Authorize handles GET /oauth/authorize. Browsers without a session are sent
+// to the consent page; the consent page calls it with the user's token to get
+// the details of the request it must show.
+func (oh *OAuthHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	req := authorizationRequestFromQuery(r.URL.Query())
+	client, scopes, err := oh.oauthService.ValidateAuthorizationRequest(r.Context(), req)
+	if err != nil {
+		respondWithAuthorizationError(w, r, err, true)
+		return
+	}
+
+	scheme, credentials, ok := authorizationCredentials(r)
+	if !ok {
+		if oh.consentURL == "" {
+			respondWithError(w, r, http.StatusUnauthorized, "missing_token")
+			return
+		}
+		http.Redirect(w, r, oh.consentURL+"?"+r.URL.RawQuery, http.StatusFound)
+		return
+	}
+	if !strings.EqualFold(scheme, "Bearer") {
+		respondWithError(w, r, http.StatusUnauthorized, "unsupported_auth_scheme")
+		return
+	}
+	if _, err := oh.userService.ValidateToken(r.Context(), credentials); err != nil {
+		respondWithError(w, r, http.StatusUnauthorized, credentialErrorKey(err, "invalid_token"))
+		return
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" {
+		redirectURI = client.RedirectURIs[0]
+	}
+	respondWithJSON(w, http.StatusOK, consentResponse{
+		ClientID:    client.ClientID,
+		ClientName:  client.Name,
+		RedirectURI: redirectURI,
+		Scopes:      scopes,
+	})
+}
+
+// AuthorizeDecision handles POST /oauth/authorize with the user's decision
+// and returns the URL the browser must be sent to.
+func (oh *OAuthHandler) AuthorizeDecision(w http.ResponseWriter, r *http.Request) {
+	var decision authorizationDecision
+	if !decodeJSON(w, r, &decision) {
+		return
+	}
+
+	var redirectTo string
+	var err error
+	if decision.Approve {
+		redirectTo, err = oh.oauthService.Authorize(r.Context(), claimsFromContext(r.Context()), decision.AuthorizationRequest)
+	} else {
+		redirectTo, err = oh.oauthService.DenyAuthorization(r.Context(), decision.AuthorizationRequest)
+	}
+	if err != nil {
+		respondWithAuthorizationError(w, r, err, false)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"redirectTo": redirectTo})
+}
+
+// Token handles POST /oauth/token
+func (oh *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithOAuthError(w, r, &services.OAuthError{Code: services.OAuthErrInvalidRequest, Description: "malformed request body"}, false)
+		return
+	}
+	clientID, clientSecret, usedBasic := clientCredentials(r)
+
+	response, err := oh.oauthService.Token(r.Context(), model.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		Scope:        r.PostForm.Get("scope"),
+	})
+	if err != nil {
+		respondWithOAuthError(w, r, err, usedBasic)
+		return
+	}
+
+	setNoStore(w)
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// Introspect handles POST /oauth/introspect (RFC 7662)
+func (oh *OAuthHandler) Introspect(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithOAuthError(w, r, &services.OAuthError{Code: services.OAuthErrInvalidRequest, Description: "malformed request body"}, false)
+		return
+	}
+	clientID, clientSecret, usedBasic := clientCredentials(r)
+	token := r.PostForm.Get("token")
+	if token == "" {
+		respondWithOAuthError(w, r, &services.OAuthError{Code: services.OAuthErrInvalidRequest, Description: "token is required"}, false)
+		return
+	}
+
+	response, err := oh.oauthService.Introspect(r.Context(), clientID, clientSecret, token)
+	if err != nil {
+		respondWithOAuthError(w, r, err, usedBasic)
+		return
+	}
+
+	setNoStore(w)
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// Revoke handles POST /oauth/revoke (RFC 7009)
+func (oh *OAuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithOAuthError(w, r, &services.OAuthError{Code: services.OAuthErrInvalidRequest, Description: "malformed request body"}, false)
+		return
+	}
+	clientID, clientSecret, usedBasic := clientCredentials(r)
+	token := r.PostForm.Get("token")
+	if token == "" {
+		respondWithOAuthError(w, r, &services.OAuthError{Code: services.OAuthErrInvalidRequest, Description: "token is required"}, false)
+		return
+	}
+
+	if err := oh.oauthService.Revoke(r.Context(), clientID, clientSecret, token, requestMetadata(r)); err != nil {
+		respondWithOAuthError(w, r, err, usedBasic)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RegisterClient registers a new OAuth client
+func (oh *OAuthHandler) RegisterClient(w http.ResponseWriter, r *http.Request) {
+	var req model.OAuthClientRegistrationRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	client, secret, err := oh.oauthService.RegisterClient(r.Context(), req)
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, oauthClientCreatedResponse{OAuthClient: *client, ClientSecret: secret})
+}
+
+// GetClients lists the registered OAuth clients
+func (oh *OAuthHandler) GetClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := oh.oauthService.GetClients(r.Context())
+	if err != nil {
+		respondWithInternalError(w, r, err, "internal_error.oauth_clients")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, clients)
+}
+
+// DeleteClient removes an OAuth client
+func (oh *OAuthHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	err := oh.oauthService.DeleteClient(r.Context(), mux.Vars(r)["clientId"])
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizationRequestFromQuery reads the parameters of an authorization request
+func authorizationRequestFromQuery(query url.Values) model.AuthorizationRequest {
+	return model.AuthorizationRequest{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+		Nonce:               query.Get("nonce"),
+	}
+}
+
+// clientCredentials reads the client credentials from HTTP Basic
+// authentication or, failing that, from the request body (RFC 6749, section 2.3.1).
+func clientCredentials(r *http.Request) (string, string, bool) {
+	if clientID, clientSecret, ok := r.BasicAuth(); ok {
+		id, errID := url.QueryUnescape(clientID)
+		secret, errSecret := url.QueryUnescape(clientSecret)
+		if errID == nil && errSecret == nil {
+			return id, secret, true
+		}
+		return clientID, clientSecret, true
+	}
+	return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret"), false
+}
+
+// respondWithAuthorizationError reports an authorization request error,
+// redirecting to the client when it is safe to do so.
+func respondWithAuthorizationError(w http.ResponseWriter, r *http.Request, err error, redirect bool) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		respondWithOAuthError(w, r, err, false)
+		return
+	}
+	if redirectTo := oauthErr.RedirectURL(); redirectTo != "" {
+		if redirect {
+			http.Redirect(w, r, redirectTo, http.StatusFound)
+		} else {
+			respondWithJSON(w, http.StatusOK, map[string]string{"redirectTo": redirectTo})
+		}
+		return
+	}
+	respondWithOAuthError(w, r, err, false)
+}
+
+// respondWithOAuthError sends an OAuth 2.0 error response (RFC 6749, section 5.2)
+func respondWithOAuthError(w http.ResponseWriter, r *http.Request, err error, usedBasic bool) {
+	var oauthErr *services.OAuthError
+	if timedOut(r, err) {
+		slog.WarnContext(r.Context(), "OAuth request timed out", "error", err)
+		oauthErr = &services.OAuthError{Code: services.OAuthErrTemporarilyUnavailable, Description: "the request timed out"}
+	} else if !errors.As(err, &oauthErr) {
+		slog.ErrorContext(r.Context(), "OAuth request failed", "error", err)
+		oauthErr = &services.OAuthError{Code: services.OAuthErrServerError, Description: "internal server error"}
+	}
+
+	status := http.StatusBadRequest
+	switch oauthErr.Code {
+	case services.OAuthErrInvalidClient:
+		status = http.StatusUnauthorized
+		if usedBasic {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+	case services.OAuthErrServerError:
+		status = http.StatusInternalServerError
+	case services.OAuthErrTemporarilyUnavailable:
+		status = http.StatusServiceUnavailable
+	}
+
+	setNoStore(w)
+	respondWithJSON(w, status, map[string]string{
+		"error":             oauthErr.Code,
+		"error_description": oauthErr.Description,
+	})
+}
+
+// setNoStore prevents caching of responses carrying tokens
+func setNoStore(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+}
@@ -0,0 +1,110 @@
+package api_test
+
+import (
+	"exercise-login-back-go/internal/api"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOAuthToken(t *testing.T) {
+	t.Run("success with basic authentication", func(t *testing.T) {
+		mockOAuthService := new(mocks.OAuthService)
+		handler := api.NewOAuthHandler(mockOAuthService, new(mocks.UserService), "")
+
+		mockOAuthService.On("Token", mock.Anything, mock.MatchedBy(func(req model.TokenRequest) bool {
+			return req.GrantType == "client_credentials" && req.ClientID == "reports" && req.ClientSecret == "s3cret"
+		})).Return(&model.TokenResponse{AccessToken: "abc", TokenType: "Bearer", ExpiresIn: 3600}, nil)
+
+		form := url.Values{"grant_type": {"client_credentials"}}
+		req, _ := http.NewRequest("POST", "/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("reports", "s3cret")
+		resp := httptest.NewRecorder()
+
+		handler.Token(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "no-store", resp.Header().Get("Cache-Control"))
+		assert.Contains(t, resp.Body.String(), `"access_token":"abc"`)
+	})
+
+	t.Run("invalid client", func(t *testing.T) {
+		mockOAuthService := new(mocks.OAuthService)
+		handler := api.NewOAuthHandler(mockOAuthService, new(mocks.UserService), "")
+
+		mockOAuthService.On("Token", mock.Anything, mock.AnythingOfType("model.TokenRequest")).
+			Return(nil, &services.OAuthError{Code: services.OAuthErrInvalidClient, Description: "client authentication failed"})
+
+		form := url.Values{"grant_type": {"client_credentials"}}
+		req, _ := http.NewRequest("POST", "/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("reports", "wrong")
+		resp := httptest.NewRecorder()
+
+		handler.Token(resp, req)
+
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NotEmpty(t, resp.Header().Get("WWW-Authenticate"))
+		assert.Contains(t, resp.Body.String(), `"error":"invalid_client"`)
+	})
+}
+
+func TestOAuthAuthorize(t *testing.T) {
+	client := &model.OAuthClient{ClientID: "spa", Name: "SPA", RedirectURIs: []string{"https://app.example.com/callback"}}
+
+	t.Run("browser without session goes to the consent page", func(t *testing.T) {
+		mockOAuthService := new(mocks.OAuthService)
+		handler := api.NewOAuthHandler(mockOAuthService, new(mocks.UserService), "https://login.example.com/consent")
+		mockOAuthService.On("ValidateAuthorizationRequest", mock.Anything, mock.AnythingOfType("model.AuthorizationRequest")).Return(client, []string{"email"}, nil)
+
+		req, _ := http.NewRequest("GET", "/oauth/authorize?response_type=code&client_id=spa", nil)
+		resp := httptest.NewRecorder()
+
+		handler.Authorize(resp, req)
+
+		assert.Equal(t, http.StatusFound, resp.Code)
+		assert.Equal(t, "https://login.example.com/consent?response_type=code&client_id=spa", resp.Header().Get("Location"))
+	})
+
+	t.Run("redirectable error goes back to the client", func(t *testing.T) {
+		mockOAuthService := new(mocks.OAuthService)
+		handler := api.NewOAuthHandler(mockOAuthService, new(mocks.UserService), "")
+		mockOAuthService.On("ValidateAuthorizationRequest", mock.Anything, mock.AnythingOfType("model.AuthorizationRequest")).
+			Return(nil, nil, &services.OAuthError{Code: services.OAuthErrInvalidScope, Description: "bad scope", RedirectURI: "https://app.example.com/callback", State: "xyz"})
+
+		req, _ := http.NewRequest("GET", "/oauth/authorize?response_type=code&client_id=spa&scope=admin&state=xyz", nil)
+		resp := httptest.NewRecorder()
+
+		handler.Authorize(resp, req)
+
+		assert.Equal(t, http.StatusFound, resp.Code)
+		location := resp.Header().Get("Location")
+		assert.True(t, strings.HasPrefix(location, "https://app.example.com/callback?"))
+		assert.Contains(t, location, "error=invalid_scope")
+		assert.Contains(t, location, "state=xyz")
+	})
+
+	t.Run("unknown client is not redirected", func(t *testing.T) {
+		mockOAuthService := new(mocks.OAuthService)
+		handler := api.NewOAuthHandler(mockOAuthService, new(mocks.UserService), "")
+		mockOAuthService.On("ValidateAuthorizationRequest", mock.Anything, mock.AnythingOfType("model.AuthorizationRequest")).
+			Return(nil, nil, &services.OAuthError{Code: services.OAuthErrInvalidRequest, Description: "unknown client_id"})
+
+		req, _ := http.NewRequest("GET", "/oauth/authorize?response_type=code&client_id=nope", nil)
+		resp := httptest.NewRecorder()
+
+		handler.Authorize(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"error":"invalid_request"`)
+	})
+}
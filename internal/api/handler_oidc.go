@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"strings"
+)
+
+type OIDCHandler struct {
+	oidcService services.OIDCService
+}
+
+// NewOIDCHandler creates a new instance of OIDCHandler
+func NewOIDCHandler(oidcService services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// Discovery handles GET /.well-known/openid-configuration
+func (oh *OIDCHandler) Discovery(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, oh.oidcService.Discovery())
+}
+
+// JWKS handles GET /.well-known/jwks.json
+func (oh *OIDCHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, oh.oidcService.JWKS())
+}
+
+// UserInfo handles GET and POST /userinfo. The access token is sent as a
+// bearer token (RFC 6750).
+func (oh *OIDCHandler) UserInfo(w http.ResponseWriter, r *http.Request) {
+	scheme, accessToken, ok := authorizationCredentials(r)
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="userinfo"`)
+		respondWithError(w, r, http.StatusUnauthorized, "missing_token.access")
+		return
+	}
+
+	info, err := oh.oidcService.UserInfo(r.Context(), accessToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAccessToken):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="userinfo", error="invalid_token"`)
+		case errors.Is(err, services.ErrInsufficientScope):
+			w.Header().Set("WWW-Authenticate", `Bearer realm="userinfo", error="insufficient_scope", scope="openid"`)
+		}
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	setNoStore(w)
+	respondWithJSON(w, http.StatusOK, info)
+}
+
+// Logout handles GET and POST /oauth/logout (RP-initiated logout).
+func (oh *OIDCHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithOAuthError(w, r, &services.OAuthError{Code: services.OAuthErrInvalidRequest, Description: "malformed request body"}, false)
+		return
+	}
+
+	redirectTo, err := oh.oidcService.Logout(r.Context(), model.LogoutRequest{
+		IDTokenHint:           r.Form.Get("id_token_hint"),
+		ClientID:              r.Form.Get("client_id"),
+		PostLogoutRedirectURI: r.Form.Get("post_logout_redirect_uri"),
+		State:                 r.Form.Get("state"),
+	}, requestMetadata(r))
+	if err != nil {
+		respondWithOAuthError(w, r, err, false)
+		return
+	}
+
+	if redirectTo != "" {
+		http.Redirect(w, r, redirectTo, http.StatusFound)
+		return
+	}
+	respondWithMessage(w, r, http.StatusOK, "logged_out")
+}
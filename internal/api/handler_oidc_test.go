@@ -0,0 +1,72 @@
+package api_test
+
+import (
+	"exercise-login-back-go/internal/api"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserInfo(t *testing.T) {
+	t.Run("missing token", func(t *testing.T) {
+		handler := api.NewOIDCHandler(new(mocks.OIDCService))
+
+		req, _ := http.NewRequest("GET", "/userinfo", nil)
+		resp := httptest.NewRecorder()
+
+		handler.UserInfo(resp, req)
+
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NotEmpty(t, resp.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("insufficient scope", func(t *testing.T) {
+		mockOIDCService := new(mocks.OIDCService)
+		handler := api.NewOIDCHandler(mockOIDCService)
+		mockOIDCService.On("UserInfo", mock.Anything, "abc").Return(nil, services.ErrInsufficientScope)
+
+		req, _ := http.NewRequest("GET", "/userinfo", nil)
+		req.Header.Set("Authorization", "Bearer abc")
+		resp := httptest.NewRecorder()
+
+		handler.UserInfo(resp, req)
+
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockOIDCService := new(mocks.OIDCService)
+		handler := api.NewOIDCHandler(mockOIDCService)
+		mockOIDCService.On("UserInfo", mock.Anything, "abc").Return(&model.UserInfo{Subject: "7", Email: "ana@example.com"}, nil)
+
+		req, _ := http.NewRequest("GET", "/userinfo", nil)
+		req.Header.Set("Authorization", "Bearer abc")
+		resp := httptest.NewRecorder()
+
+		handler.UserInfo(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, `{"sub":"7","email":"ana@example.com"}`, resp.Body.String())
+	})
+}
+
+func TestDiscovery(t *testing.T) {
+	mockOIDCService := new(mocks.OIDCService)
+	handler := api.NewOIDCHandler(mockOIDCService)
+	mockOIDCService.On("Discovery").Return(model.OpenIDConfiguration{Issuer: "https://login.example.com"})
+
+	req, _ := http.NewRequest("GET", "/.well-known/openid-configuration", nil)
+	resp := httptest.NewRecorder()
+
+	handler.Discovery(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"issuer":"https://login.example.com"`)
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// samlStateCookie keeps the relay state of a pending SAML sign in in the
+	// browser that started it. It is scoped to the SAML endpoints.
+	samlStateCookie     = "saml_relay_state"
+	samlStateCookiePath = "/saml"
+)
+
+type SAMLHandler struct {
+	samlService services.SAMLService
+	redirectURL string
+}
+
+// NewSAMLHandler creates a new instance of SAMLHandler
+func NewSAMLHandler(samlService services.SAMLService, redirectURL string) *SAMLHandler {
+	return &SAMLHandler{
+		samlService: samlService,
+		redirectURL: redirectURL,
+	}
+}
+
+// Metadata serves the service provider metadata of a connection
+func (sh *SAMLHandler) Metadata(w http.ResponseWriter, r *http.Request) {
+	metadata, err := sh.samlService.Metadata(mux.Vars(r)["connection"])
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/samlmetadata+xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(metadata)
+}
+
+// StartLogin sends the browser to the identity provider of the connection,
+// with a redirect or with a self-submitting form depending on the binding.
+func (sh *SAMLHandler) StartLogin(w http.ResponseWriter, r *http.Request) {
+	req, err := sh.samlService.StartLogin(r.Context(), mux.Vars(r)["connection"])
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	// The identity provider posts the response from its own site, and
+	// browsers only send cookies on cross-site POSTs with SameSite=None,
+	// which requires Secure.
+	http.SetCookie(w, &http.Cookie{
+		Name:     samlStateCookie,
+		Value:    req.RelayState,
+		Path:     samlStateCookiePath,
+		Expires:  req.ExpiresAt,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+	if req.RedirectURL != "" {
+		http.Redirect(w, r, req.RedirectURL, http.StatusFound)
+		return
+	}
+	setNoStore(w)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(req.PostForm)
+}
+
+// AssertionConsumerService completes the sign in when the identity provider
+// posts its response. Like the social login callback, the outcome is sent to
+// the frontend redirect URL when one is configured.
+func (sh *SAMLHandler) AssertionConsumerService(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	browserRelayState := stateCookie(r, samlStateCookie)
+	clearStateCookie(w, samlStateCookie, samlStateCookiePath, true, http.SameSiteNoneMode)
+
+	token, err := sh.samlService.ConsumeAssertion(r.Context(), mux.Vars(r)["connection"], r.PostForm.Get("SAMLResponse"), r.PostForm.Get("RelayState"), browserRelayState, requestMetadata(r))
+	if err != nil {
+		if sh.redirectURL != "" {
+			code, description := redirectError(r, err)
+			params := url.Values{"error": {code}, "error_description": {description}}
+			http.Redirect(w, r, sh.redirectURL+"?"+params.Encode(), http.StatusSeeOther)
+			return
+		}
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	if sh.redirectURL == "" {
+		setNoStore(w)
+		respondWithJSON(w, http.StatusOK, map[string]string{"token": token})
+		return
+	}
+	http.Redirect(w, r, sh.redirectURL+"#"+url.Values{"token": {token}}.Encode(), http.StatusSeeOther)
+}
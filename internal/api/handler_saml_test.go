@@ -0,0 +1,107 @@
+package api_test
+
+import (
+	"exercise-login-back-go/internal/api"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSAMLStartLogin(t *testing.T) {
+	start := func(handler *api.SAMLHandler) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/saml/acme/login", nil)
+		req = mux.SetURLVars(req, map[string]string{"connection": "acme"})
+		resp := httptest.NewRecorder()
+		handler.StartLogin(resp, req)
+		return resp
+	}
+
+	t.Run("redirect binding", func(t *testing.T) {
+		mockService := new(mocks.SAMLService)
+		mockService.On("StartLogin", mock.Anything, "acme").Return(&model.SAMLAuthnRequest{ID: "id-1", RedirectURL: "https://idp.example.com/sso?SAMLRequest=x", RelayState: "relay"}, nil)
+
+		resp := start(api.NewSAMLHandler(mockService, ""))
+
+		assert.Equal(t, http.StatusFound, resp.Code)
+		assert.Equal(t, "https://idp.example.com/sso?SAMLRequest=x", resp.Header().Get("Location"))
+		cookies := resp.Result().Cookies()
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, "saml_relay_state", cookies[0].Name)
+			assert.Equal(t, "relay", cookies[0].Value)
+			assert.True(t, cookies[0].HttpOnly)
+			assert.True(t, cookies[0].Secure)
+			assert.Equal(t, http.SameSiteNoneMode, cookies[0].SameSite)
+		}
+	})
+
+	t.Run("post binding", func(t *testing.T) {
+		mockService := new(mocks.SAMLService)
+		mockService.On("StartLogin", mock.Anything, "acme").Return(&model.SAMLAuthnRequest{ID: "id-1", PostForm: []byte("<form></form>")}, nil)
+
+		resp := start(api.NewSAMLHandler(mockService, ""))
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "text/html; charset=utf-8", resp.Header().Get("Content-Type"))
+		assert.Equal(t, "<form></form>", resp.Body.String())
+	})
+
+	t.Run("unknown connection", func(t *testing.T) {
+		mockService := new(mocks.SAMLService)
+		mockService.On("StartLogin", mock.Anything, "acme").Return(nil, services.ErrUnknownProvider)
+
+		resp := start(api.NewSAMLHandler(mockService, ""))
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
+
+func TestSAMLAssertionConsumerService(t *testing.T) {
+	consume := func(handler *api.SAMLHandler) *httptest.ResponseRecorder {
+		form := url.Values{"SAMLResponse": {"response"}, "RelayState": {"relay"}}
+		req, _ := http.NewRequest("POST", "/saml/acme/acs", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "saml_relay_state", Value: "relay"})
+		req = mux.SetURLVars(req, map[string]string{"connection": "acme"})
+		resp := httptest.NewRecorder()
+		handler.AssertionConsumerService(resp, req)
+		return resp
+	}
+
+	t.Run("token is sent to the frontend in the fragment", func(t *testing.T) {
+		mockService := new(mocks.SAMLService)
+		mockService.On("ConsumeAssertion", mock.Anything, "acme", "response", "relay", "relay", mock.AnythingOfType("model.RequestMetadata")).Return("jwt", nil)
+
+		resp := consume(api.NewSAMLHandler(mockService, "https://app.example.com/sso"))
+
+		assert.Equal(t, http.StatusSeeOther, resp.Code)
+		assert.Equal(t, "https://app.example.com/sso#token=jwt", resp.Header().Get("Location"))
+	})
+
+	t.Run("domain not allowed is reported to the frontend", func(t *testing.T) {
+		mockService := new(mocks.SAMLService)
+		mockService.On("ConsumeAssertion", mock.Anything, "acme", "response", "relay", "relay", mock.AnythingOfType("model.RequestMetadata")).Return("", services.ErrSAMLDomainNotAllowed)
+
+		resp := consume(api.NewSAMLHandler(mockService, "https://app.example.com/sso"))
+
+		assert.Equal(t, http.StatusSeeOther, resp.Code)
+		assert.Contains(t, resp.Header().Get("Location"), "https://app.example.com/sso?error=domain_not_allowed")
+	})
+
+	t.Run("invalid response without frontend", func(t *testing.T) {
+		mockService := new(mocks.SAMLService)
+		mockService.On("ConsumeAssertion", mock.Anything, "acme", "response", "relay", "relay", mock.AnythingOfType("model.RequestMetadata")).Return("", services.ErrSocialLoginFailed)
+
+		resp := consume(api.NewSAMLHandler(mockService, ""))
+
+		assert.Equal(t, http.StatusBadGateway, resp.Code)
+	})
+}
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// scimContentType is the media type of SCIM requests and responses (RFC 7644, section 3.1)
+const scimContentType = "application/scim+json"
+
+type SCIMHandler struct {
+	scimService services.SCIMService
+}
+
+// scimClientCreatedResponse includes the bearer token, which is only shown once
+type scimClientCreatedResponse struct {
+	model.SCIMClient
+	Token string `json:"token"`
+}
+
+// NewSCIMHandler creates a new instance of SCIMHandler
+func NewSCIMHandler(scimService services.SCIMService) *SCIMHandler {
+	return &SCIMHandler{scimService: scimService}
+}
+
+// Authenticate rejects SCIM requests without the bearer token of a
+// provisioning client and stores the client in the request context.
+func (sh *SCIMHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, credentials, ok := authorizationCredentials(r)
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+			respondWithSCIMError(w, r, &services.SCIMError{Status: http.StatusUnauthorized, Key: "scim.missing_token"})
+			return
+		}
+
+		client, err := sh.scimService.AuthenticateClient(r.Context(), credentials)
+		if errors.Is(err, services.ErrInvalidSCIMToken) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scim", error="invalid_token"`)
+			respondWithSCIMError(w, r, &services.SCIMError{Status: http.StatusUnauthorized, Key: services.ErrInvalidSCIMToken.Key})
+			return
+		}
+		if err != nil {
+			respondWithSCIMError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), scimContextKey, client)))
+	})
+}
+
+// ServiceProviderConfig describes the SCIM features supported
+func (sh *SCIMHandler) ServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	respondWithSCIM(w, http.StatusOK, sh.scimService.ServiceProviderConfig())
+}
+
+// GetResourceTypes lists the resource types, or returns one of them
+func (sh *SCIMHandler) GetResourceTypes(w http.ResponseWriter, r *http.Request) {
+	resourceTypes := sh.scimService.ResourceTypes()
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		respondWithSCIM(w, http.StatusOK, scimList(resourceTypes, len(resourceTypes)))
+		return
+	}
+	for _, resourceType := range resourceTypes {
+		if resourceType.ID == id {
+			respondWithSCIM(w, http.StatusOK, resourceType)
+			return
+		}
+	}
+	respondWithSCIMError(w, r, &services.SCIMError{Status: http.StatusNotFound, Key: "scim.resource_type_not_found"})
+}
+
+// GetSchemas lists the schemas, or returns one of them
+func (sh *SCIMHandler) GetSchemas(w http.ResponseWriter, r *http.Request) {
+	schemas := sh.scimService.Schemas()
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		respondWithSCIM(w, http.StatusOK, scimList(schemas, len(schemas)))
+		return
+	}
+	for _, schema := range schemas {
+		if schema.ID == id {
+			respondWithSCIM(w, http.StatusOK, schema)
+			return
+		}
+	}
+	respondWithSCIMError(w, r, &services.SCIMError{Status: http.StatusNotFound, Key: "scim.schema_not_found"})
+}
+
+// ListUsers returns a page of the users matching the filter
+func (sh *SCIMHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	startIndex, err := scimIntParam(query.Get("startIndex"), 1)
+	if err != nil {
+		respondWithSCIMError(w, r, err)
+		return
+	}
+	count, err := scimIntParam(query.Get("count"), 100)
+	if err != nil {
+		respondWithSCIMError(w, r, err)
+		return
+	}
+
+	client := scimClientFromContext(r.Context())
+	list, err := sh.scimService.ListUsers(r.Context(), *client, query.Get("filter"), startIndex, count)
+	if err != nil {
+		respondWithSCIMError(w, r, err)
+		return
+	}
+	respondWithSCIM(w, http.StatusOK, list)
+}
+
+// GetUser returns a provisioned user
+func (sh *SCIMHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	client := scimClientFromContext(r.Context())
+	user, err := sh.scimService.GetUser(r.Context(), *client, mux.Vars(r)["id"])
+	if err != nil {
+		respondWithSCIMError(w, r, err)
+		return
+	}
+	respondWithSCIMUser(w, http.StatusOK, user)
+}
+
+// CreateUser provisions a new user
+func (sh *SCIMHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req model.SCIMUser
+	if err := decodeBody(r, &req, scimBody); err != nil {
+		respondWithSCIMError(w, r, scimDecodeError(err))
+		return
+	}
+
+	client := scimClientFromContext(r.Context())
+	user, err := sh.scimService.CreateUser(r.Context(), *client, req, requestMetadata(r))
+	if err != nil {
+		respondWithSCIMError(w, r, err)
+		return
+	}
+	respondWithSCIMUser(w, http.StatusCreated, user)
+}
+
+// ReplaceUser replaces the attributes of a provisioned user
+func (sh *SCIMHandler) ReplaceUser(w http.ResponseWriter, r *http.Request) {
+	var req model.SCIMUser
+	if err := decodeBody(r, &req, scimBody); err != nil {
+		respondWithSCIMError(w, r, scimDecodeError(err))
+		return
+	}
+
+	client := scimClientFromContext(r.Context())
+	user, err := sh.scimService.ReplaceUser(r.Context(), *client, mux.Vars(r)["id"], req, requestMetadata(r))
+	if err != nil {
+		respondWithSCIMError(w, r, err)
+		return
+	}
+	respondWithSCIMUser(w, http.StatusOK, user)
+}
+
+// PatchUser modifies some attributes of a provisioned user
+func (sh *SCIMHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	var req model.SCIMPatchRequest
+	if err := decodeBody(r, &req, scimBody); err != nil {
+		respondWithSCIMError(w, r, scimDecodeError(err))
+		return
+	}
+
+	client := scimClientFromContext(r.Context())
+	user, err := sh.scimService.PatchUser(r.Context(), *client, mux.Vars(r)["id"], req, requestMetadata(r))
+	if err != nil {
+		respondWithSCIMError(w, r, err)
+		return
+	}
+	respondWithSCIMUser(w, http.StatusOK, user)
+}
+
+// DeleteUser deprovisions a user
+func (sh *SCIMHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	client := scimClientFromContext(r.Context())
+	if err := sh.scimService.DeleteUser(r.Context(), *client, mux.Vars(r)["id"], requestMetadata(r)); err != nil {
+		respondWithSCIMError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateClient registers a provisioning client
+func (sh *SCIMHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	var req model.SCIMClientCreateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	client, token, err := sh.scimService.CreateClient(r.Context(), req)
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	setNoStore(w)
+	respondWithJSON(w, http.StatusCreated, scimClientCreatedResponse{SCIMClient: *client, Token: token})
+}
+
+// GetClients lists the provisioning clients
+func (sh *SCIMHandler) GetClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := sh.scimService.GetClients(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to list the SCIM clients", "error", err)
+		respondWithInternalError(w, r, err, "internal_error.scim_clients")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, clients)
+}
+
+// DeleteClient removes a provisioning client
+func (sh *SCIMHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithServiceError(w, r, services.ErrSCIMClientNotFound)
+		return
+	}
+
+	if err := sh.scimService.DeleteClient(r.Context(), id); err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// scimList wraps the discovery resources in a list response
+func scimList(resources interface{}, total int) model.SCIMListResponse {
+	return model.SCIMListResponse{
+		Schemas:      []string{model.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   1,
+		ItemsPerPage: total,
+		Resources:    resources,
+	}
+}
+
+// scimIntParam parses a numeric query parameter, or returns its default when absent
+func scimIntParam(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &services.SCIMError{Status: http.StatusBadRequest, ScimType: services.SCIMErrInvalidValue, Key: "scim.invalid_number", Params: i18n.Params{"value": value}}
+	}
+	return n, nil
+}
+
+// scimDecodeError reports a request body that could not be decoded. Only
+// malformed bodies are SCIM syntax errors; bodies too large or of another
+// media type keep their HTTP status.
+func scimDecodeError(err *bodyError) error {
+	scimErr := &services.SCIMError{Status: err.status, Key: err.key, Params: err.params}
+	if err.status == http.StatusBadRequest {
+		scimErr.ScimType = services.SCIMErrInvalidSyntax
+	}
+	return scimErr
+}
+
+// respondWithSCIMUser sends a user with its location
+func respondWithSCIMUser(w http.ResponseWriter, code int, user *model.SCIMUser) {
+	if user.Meta != nil && user.Meta.Location != "" {
+		w.Header().Set("Location", user.Meta.Location)
+	}
+	respondWithSCIM(w, code, user)
+}
+
+// respondWithSCIMError sends a SCIM error response (RFC 7644, section 3.12)
+func respondWithSCIMError(w http.ResponseWriter, r *http.Request, err error) {
+	var scimErr *services.SCIMError
+	if timedOut(r, err) {
+		slog.WarnContext(r.Context(), "SCIM request timed out", "error", err)
+		scimErr = &services.SCIMError{Status: http.StatusServiceUnavailable, Key: services.ErrTimeout.Key}
+	} else if !errors.As(err, &scimErr) {
+		slog.ErrorContext(r.Context(), "SCIM request failed", "error", err)
+		scimErr = &services.SCIMError{Status: http.StatusInternalServerError, Key: "internal_error"}
+	}
+	respondWithSCIM(w, scimErr.Status, model.SCIMError{
+		Schemas:  []string{model.SCIMSchemaError},
+		Status:   strconv.Itoa(scimErr.Status),
+		ScimType: scimErr.ScimType,
+		Detail:   localize(r, scimErr.Key, scimErr.Params),
+	})
+}
+
+// respondWithSCIM sends a response with the SCIM media type
+func respondWithSCIM(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(code)
+	w.Write(response)
+}
@@ -0,0 +1,152 @@
+package api_test
+
+import (
+	"encoding/json"
+	"exercise-login-back-go/internal/api"
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// scimRouter serves the SCIM user routes behind the client authentication
+func scimRouter(handler *api.SCIMHandler) *mux.Router {
+	r := mux.NewRouter()
+	users := r.PathPrefix("/scim/v2/Users").Subrouter()
+	users.Use(handler.Authenticate)
+	users.HandleFunc("", handler.ListUsers).Methods("GET")
+	users.HandleFunc("", handler.CreateUser).Methods("POST")
+	users.HandleFunc("/{id}", handler.PatchUser).Methods("PATCH")
+	users.HandleFunc("/{id}", handler.DeleteUser).Methods("DELETE")
+	return r
+}
+
+func TestSCIMAuthentication(t *testing.T) {
+	mockService := new(mocks.SCIMService)
+	mockService.On("AuthenticateClient", mock.Anything, "wrong").Return(nil, services.ErrInvalidSCIMToken)
+	router := scimRouter(api.NewSCIMHandler(mockService))
+
+	t.Run("missing token", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/scim/v2/Users", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "application/scim+json", resp.Header().Get("Content-Type"))
+		assert.Contains(t, resp.Header().Get("WWW-Authenticate"), "Bearer")
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/scim/v2/Users", nil)
+		req.Header.Set("Authorization", "Bearer wrong")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		var body model.SCIMError
+		json.Unmarshal(resp.Body.Bytes(), &body)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "401", body.Status)
+		assert.Equal(t, []string{model.SCIMSchemaError}, body.Schemas)
+		mockService.AssertNotCalled(t, "ListUsers", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSCIMUsers(t *testing.T) {
+	client := &model.SCIMClient{ID: 1, Name: "okta"}
+	serve := func(mockService *mocks.SCIMService, method, target, body string) *httptest.ResponseRecorder {
+		mockService.On("AuthenticateClient", mock.Anything, "token").Return(client, nil)
+		req, _ := http.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Content-Type", "application/scim+json")
+		resp := httptest.NewRecorder()
+		scimRouter(api.NewSCIMHandler(mockService)).ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("list uses the default count", func(t *testing.T) {
+		mockService := new(mocks.SCIMService)
+		mockService.On("ListUsers", mock.Anything, mock.AnythingOfType("model.SCIMClient"), `userName eq "jdoe"`, 1, 100).Return(&model.SCIMListResponse{Schemas: []string{model.SCIMSchemaListResponse}, TotalResults: 0, Resources: []model.SCIMUser{}}, nil)
+
+		resp := serve(mockService, "GET", `/scim/v2/Users?filter=userName+eq+%22jdoe%22`, "")
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"Resources":[]`)
+	})
+
+	t.Run("invalid count", func(t *testing.T) {
+		mockService := new(mocks.SCIMService)
+
+		resp := serve(mockService, "GET", "/scim/v2/Users?count=ten", "")
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"scimType":"invalidValue"`)
+	})
+
+	t.Run("create ignores unknown attributes and answers with the location", func(t *testing.T) {
+		mockService := new(mocks.SCIMService)
+		created := &model.SCIMUser{ID: "9", UserName: "jdoe", Meta: &model.SCIMMeta{ResourceType: "User", Location: "http://localhost/scim/v2/Users/9"}}
+		mockService.On("CreateUser", mock.Anything, *client, model.SCIMUser{UserName: "jdoe"}, mock.AnythingOfType("model.RequestMetadata")).Return(created, nil)
+
+		resp := serve(mockService, "POST", "/scim/v2/Users", `{"userName":"jdoe","displayName":"Jane Doe"}`)
+
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Equal(t, "http://localhost/scim/v2/Users/9", resp.Header().Get("Location"))
+	})
+
+	t.Run("uniqueness error", func(t *testing.T) {
+		mockService := new(mocks.SCIMService)
+		mockService.On("CreateUser", mock.Anything, *client, mock.AnythingOfType("model.SCIMUser"), mock.AnythingOfType("model.RequestMetadata")).
+			Return(nil, &services.SCIMError{Status: http.StatusConflict, ScimType: services.SCIMErrUniqueness, Key: "scim.uniqueness"})
+
+		resp := serve(mockService, "POST", "/scim/v2/Users", `{"userName":"jdoe"}`)
+
+		var body model.SCIMError
+		json.Unmarshal(resp.Body.Bytes(), &body)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Equal(t, model.SCIMError{Schemas: []string{model.SCIMSchemaError}, Status: "409", ScimType: "uniqueness", Detail: "el usuario, correo o teléfono ya se encuentra registrado"}, body)
+	})
+
+	t.Run("error detail in english", func(t *testing.T) {
+		mockService := new(mocks.SCIMService)
+		mockService.On("AuthenticateClient", mock.Anything, "token").Return(client, nil)
+		mockService.On("DeleteUser", mock.Anything, *client, "7", mock.AnythingOfType("model.RequestMetadata")).
+			Return(&services.SCIMError{Status: http.StatusNotFound, Key: "scim.user_not_found", Params: i18n.Params{"id": "7"}})
+
+		req, _ := http.NewRequest("DELETE", "/scim/v2/Users/7", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		req = req.WithContext(i18n.NewContext(req.Context(), i18n.English))
+		resp := httptest.NewRecorder()
+		scimRouter(api.NewSCIMHandler(mockService)).ServeHTTP(resp, req)
+
+		var body model.SCIMError
+		json.Unmarshal(resp.Body.Bytes(), &body)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.Equal(t, "user 7 does not exist", body.Detail)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		mockService := new(mocks.SCIMService)
+
+		resp := serve(mockService, "PATCH", "/scim/v2/Users/9", `{"Operations":`)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"scimType":"invalidSyntax"`)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockService := new(mocks.SCIMService)
+		mockService.On("DeleteUser", mock.Anything, *client, "9", mock.AnythingOfType("model.RequestMetadata")).Return(nil)
+
+		resp := serve(mockService, "DELETE", "/scim/v2/Users/9", "")
+
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+	})
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/services"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type SessionHandler struct {
+	sessionService    services.SessionService
+	loginAlertService services.LoginAlertService
+}
+
+// sessionResponse is a session as shown to its owner
+type sessionResponse struct {
+	ID         string     `json:"id"`
+	Device     string     `json:"device"`
+	IPAddress  string     `json:"ipAddress"`
+	UserAgent  string     `json:"userAgent"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	Active     bool       `json:"active"`
+	Current    bool       `json:"current"`
+}
+
+// NewSessionHandler creates a new instance of SessionHandler
+func NewSessionHandler(sessionService services.SessionService, loginAlertService services.LoginAlertService) *SessionHandler {
+	return &SessionHandler{
+		sessionService:    sessionService,
+		loginAlertService: loginAlertService,
+	}
+}
+
+// GetSessions lists the sessions of the authenticated user
+func (sh *SessionHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+
+	sessions, err := sh.sessionService.GetSessions(r.Context(), claims.UserID)
+	if err != nil {
+		respondWithInternalError(w, r, err, "internal_error.sessions")
+		return
+	}
+
+	now := time.Now()
+	response := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, sessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			RevokedAt:  session.RevokedAt,
+			Active:     session.IsActive(now),
+			Current:    session.ID == claims.Id,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// RevokeSession revokes one of the sessions of the authenticated user
+func (sh *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+	sessionID := mux.Vars(r)["id"]
+
+	err := sh.sessionService.RevokeSession(r.Context(), claims.UserID, claims.Username, sessionID, requestMetadata(r))
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// revokeSessionsPage asks to confirm the "this wasn't me" link of a login
+// alert. Mail scanners and link previews open links with GET, so the form
+// posts the token back and only the POST revokes the sessions.
+var revokeSessionsPage = template.Must(template.New("revoke_sessions").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head><meta charset="utf-8"><title>{{.Button}}</title></head>
+<body>
+<p>{{.Message}}</p>
+<form method="post">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">{{.Button}}</button>
+</form>
+</body>
+</html>
+`))
+
+// ConfirmRevokeSessionsFromAlert shows the page opened by the "this wasn't
+// me" link of a login alert, without revoking anything
+func (sh *SessionHandler) ConfirmRevokeSessionsFromAlert(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		respondWithError(w, r, http.StatusBadRequest, "missing_param.token")
+		return
+	}
+
+	setNoStore(w)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Language", string(i18n.FromContext(r.Context())))
+	w.WriteHeader(http.StatusOK)
+	revokeSessionsPage.Execute(w, map[string]string{
+		"Lang":    string(i18n.FromContext(r.Context())),
+		"Message": localize(r, "sessions_revoke.confirm", nil),
+		"Button":  localize(r, "sessions_revoke.button", nil),
+		"Token":   token,
+	})
+}
+
+// RevokeSessionsFromAlert revokes every session of the account when the
+// confirmation page of a login alert is submitted
+func (sh *SessionHandler) RevokeSessionsFromAlert(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue("token")
+	if token == "" {
+		respondWithError(w, r, http.StatusBadRequest, "missing_param.token")
+		return
+	}
+
+	if err := sh.loginAlertService.RevokeSessionsFromAlert(r.Context(), token, requestMetadata(r)); err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	respondWithMessage(w, r, http.StatusOK, "sessions_revoked")
+}
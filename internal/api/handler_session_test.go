@@ -0,0 +1,55 @@
+package api_test
+
+import (
+	"exercise-login-back-go/internal/api"
+	"exercise-login-back-go/internal/mocks"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRevokeSessionsFromAlert(t *testing.T) {
+	mockAlerts := new(mocks.LoginAlertService)
+	handler := api.NewSessionHandler(new(mocks.SessionService), mockAlerts)
+
+	t.Run("opening the link only asks to confirm", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/users/sessions/revoke?token=alert-token", nil)
+		resp := httptest.NewRecorder()
+
+		handler.ConfirmRevokeSessionsFromAlert(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "text/html; charset=utf-8", resp.Header().Get("Content-Type"))
+		assert.Contains(t, resp.Body.String(), `<form method="post">`)
+		assert.Contains(t, resp.Body.String(), `value="alert-token"`)
+		mockAlerts.AssertNotCalled(t, "RevokeSessionsFromAlert", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("submitting the confirmation revokes the sessions", func(t *testing.T) {
+		mockAlerts.On("RevokeSessionsFromAlert", mock.Anything, "alert-token", mock.AnythingOfType("model.RequestMetadata")).Return(nil).Once()
+
+		form := url.Values{"token": {"alert-token"}}
+		req, _ := http.NewRequest("POST", "/api/users/sessions/revoke", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp := httptest.NewRecorder()
+
+		handler.RevokeSessionsFromAlert(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		mockAlerts.AssertExpectations(t)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/users/sessions/revoke", nil)
+		resp := httptest.NewRecorder()
+
+		handler.ConfirmRevokeSessionsFromAlert(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
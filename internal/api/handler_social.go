@@ -0,0 +1,171 @@
+package api
+
+import (
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// socialStateCookie keeps the state of a pending social sign in in the
+	// browser that started it. It is scoped to the callback path.
+	socialStateCookie     = "social_login_state"
+	socialStateCookiePath = "/api/auth/social"
+)
+
+type SocialLoginHandler struct {
+	socialLoginService services.SocialLoginService
+	redirectURL        string
+	// secureCookies marks the state cookie Secure, for services published
+	// over HTTPS
+	secureCookies bool
+}
+
+// NewSocialLoginHandler creates a new instance of SocialLoginHandler
+func NewSocialLoginHandler(socialLoginService services.SocialLoginService, redirectURL string, secureCookies bool) *SocialLoginHandler {
+	return &SocialLoginHandler{
+		socialLoginService: socialLoginService,
+		redirectURL:        redirectURL,
+		secureCookies:      secureCookies,
+	}
+}
+
+// GetProviders lists the upstream providers users can sign in with
+func (sh *SocialLoginHandler) GetProviders(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, map[string][]string{"providers": sh.socialLoginService.Providers()})
+}
+
+// StartLogin sends the browser to the upstream provider
+func (sh *SocialLoginHandler) StartLogin(w http.ResponseWriter, r *http.Request) {
+	start, err := sh.socialLoginService.StartLogin(r.Context(), mux.Vars(r)["provider"])
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	sh.setStateCookie(w, start)
+	http.Redirect(w, r, start.AuthorizationURL, http.StatusFound)
+}
+
+// Callback completes the sign in when the upstream provider redirects the
+// browser back. When a frontend redirect URL is configured the outcome is sent
+// there: the token in the fragment, or an error code in the query.
+func (sh *SocialLoginHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	browserState := stateCookie(r, socialStateCookie)
+	clearStateCookie(w, socialStateCookie, socialStateCookiePath, sh.secureCookies, http.SameSiteLaxMode)
+
+	query := r.URL.Query()
+	if upstreamError := query.Get("error"); upstreamError != "" {
+		if sh.redirectURL != "" {
+			sh.redirectWithError(w, r, "access_denied", localize(r, "access_denied", nil))
+			return
+		}
+		respondWithError(w, r, http.StatusBadRequest, "access_denied")
+		return
+	}
+
+	result, err := sh.socialLoginService.Callback(r.Context(), mux.Vars(r)["provider"], query.Get("state"), browserState, query.Get("code"), requestMetadata(r))
+	if err != nil {
+		if sh.redirectURL != "" {
+			code, description := redirectError(r, err)
+			sh.redirectWithError(w, r, code, description)
+			return
+		}
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	if sh.redirectURL == "" {
+		setNoStore(w)
+		respondWithJSON(w, http.StatusOK, result)
+		return
+	}
+	if result.Token != "" {
+		http.Redirect(w, r, sh.redirectURL+"#"+url.Values{"token": {result.Token}}.Encode(), http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, sh.redirectURL+"?"+url.Values{"linked": {result.LinkedProvider}}.Encode(), http.StatusFound)
+}
+
+// GetIdentities lists the upstream accounts linked to the authenticated user
+func (sh *SocialLoginHandler) GetIdentities(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+	identities, err := sh.socialLoginService.GetIdentities(r.Context(), claims.UserID)
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, identities)
+}
+
+// StartLink returns the upstream URL the browser must visit to link a provider
+func (sh *SocialLoginHandler) StartLink(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+	start, err := sh.socialLoginService.StartLink(r.Context(), claims.UserID, mux.Vars(r)["provider"])
+	if err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	sh.setStateCookie(w, start)
+	respondWithJSON(w, http.StatusOK, map[string]string{"authorizationUrl": start.AuthorizationURL})
+}
+
+// Unlink removes the link between the authenticated user and a provider
+func (sh *SocialLoginHandler) Unlink(w http.ResponseWriter, r *http.Request) {
+	claims := claimsFromContext(r.Context())
+	if err := sh.socialLoginService.Unlink(r.Context(), claims.UserID, claims.Username, mux.Vars(r)["provider"], requestMetadata(r)); err != nil {
+		respondWithServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// redirectWithError sends the browser to the frontend with an error code
+func (sh *SocialLoginHandler) redirectWithError(w http.ResponseWriter, r *http.Request, code, description string) {
+	params := url.Values{"error": {code}, "error_description": {description}}
+	http.Redirect(w, r, sh.redirectURL+"?"+params.Encode(), http.StatusFound)
+}
+
+// setStateCookie binds the state of a sign in to the browser. SameSite=Lax
+// still sends the cookie on the top-level redirect back from the provider.
+func (sh *SocialLoginHandler) setStateCookie(w http.ResponseWriter, start *model.SocialLoginStart) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     socialStateCookie,
+		Value:    start.State,
+		Path:     socialStateCookiePath,
+		Expires:  start.ExpiresAt,
+		HttpOnly: true,
+		Secure:   sh.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// stateCookie returns the state kept in the browser, if any
+func stateCookie(r *http.Request, name string) string {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// clearStateCookie removes a state cookie once its sign in is completed, so
+// it cannot be presented again
+func clearStateCookie(w http.ResponseWriter, name, path string, secure bool, sameSite http.SameSite) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Path:     path,
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: sameSite,
+	})
+}
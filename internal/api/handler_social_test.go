@@ -0,0 +1,95 @@
+package api_test
+
+import (
+	"exercise-login-back-go/internal/api"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSocialLoginCallback(t *testing.T) {
+	callback := func(handler *api.SocialLoginHandler, query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/auth/social/stub/callback?"+query, nil)
+		req.AddCookie(&http.Cookie{Name: "social_login_state", Value: "s1"})
+		req = mux.SetURLVars(req, map[string]string{"provider": "stub"})
+		resp := httptest.NewRecorder()
+		handler.Callback(resp, req)
+		return resp
+	}
+
+	t.Run("token is sent to the frontend in the fragment", func(t *testing.T) {
+		mockService := new(mocks.SocialLoginService)
+		handler := api.NewSocialLoginHandler(mockService, "https://app.example.com/social", true)
+		mockService.On("Callback", mock.Anything, "stub", "s1", "s1", "c1", mock.AnythingOfType("model.RequestMetadata")).Return(&model.SocialLoginResult{Token: "jwt"}, nil)
+
+		resp := callback(handler, "state=s1&code=c1")
+
+		assert.Equal(t, http.StatusFound, resp.Code)
+		assert.Equal(t, "https://app.example.com/social#token=jwt", resp.Header().Get("Location"))
+		assert.Contains(t, resp.Header().Get("Set-Cookie"), "social_login_state=;")
+	})
+
+	t.Run("email conflict is reported to the frontend", func(t *testing.T) {
+		mockService := new(mocks.SocialLoginService)
+		handler := api.NewSocialLoginHandler(mockService, "https://app.example.com/social", true)
+		mockService.On("Callback", mock.Anything, "stub", "s1", "s1", "c1", mock.AnythingOfType("model.RequestMetadata")).Return(nil, services.ErrSocialEmailConflict)
+
+		resp := callback(handler, "state=s1&code=c1")
+
+		assert.Equal(t, http.StatusFound, resp.Code)
+		assert.Contains(t, resp.Header().Get("Location"), "https://app.example.com/social?error=email_conflict")
+	})
+
+	t.Run("email conflict without frontend", func(t *testing.T) {
+		mockService := new(mocks.SocialLoginService)
+		handler := api.NewSocialLoginHandler(mockService, "", true)
+		mockService.On("Callback", mock.Anything, "stub", "s1", "s1", "c1", mock.AnythingOfType("model.RequestMetadata")).Return(nil, services.ErrSocialEmailConflict)
+
+		resp := callback(handler, "state=s1&code=c1")
+
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("upstream error", func(t *testing.T) {
+		mockService := new(mocks.SocialLoginService)
+		handler := api.NewSocialLoginHandler(mockService, "", true)
+
+		resp := callback(handler, "error=access_denied&state=s1")
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		mockService.AssertNotCalled(t, "Callback", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSocialLoginStart(t *testing.T) {
+	mockService := new(mocks.SocialLoginService)
+	handler := api.NewSocialLoginHandler(mockService, "", true)
+	mockService.On("StartLogin", mock.Anything, "stub").Return(&model.SocialLoginStart{
+		AuthorizationURL: "https://idp.example.com/authorize?state=s1", State: "s1", ExpiresAt: time.Now().Add(10 * time.Minute),
+	}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/auth/social/stub", nil)
+	req = mux.SetURLVars(req, map[string]string{"provider": "stub"})
+	resp := httptest.NewRecorder()
+	handler.StartLogin(resp, req)
+
+	assert.Equal(t, http.StatusFound, resp.Code)
+	assert.Equal(t, "https://idp.example.com/authorize?state=s1", resp.Header().Get("Location"))
+	cookies := resp.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "social_login_state", cookies[0].Name)
+		assert.Equal(t, "s1", cookies[0].Value)
+		assert.Equal(t, "/api/auth/social", cookies[0].Path)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.True(t, cookies[0].Secure)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	}
+}
@@ -0,0 +1,388 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/logging"
+	"exercise-login-back-go/internal/metrics"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type contextKey string
+
+const (
+	claimsContextKey   contextKey = "claims"
+	apiKeyContextKey   contextKey = "apiKey"
+	scimContextKey     contextKey = "scimClient"
+	clientIPContextKey contextKey = "clientIP"
+)
+
+// requestIDHeader carries the identifier that correlates a request with its
+// logs and error responses
+const requestIDHeader = "X-Request-ID"
+
+// requestIDMiddleware identifies every request, keeping the identifier sent
+// by a proxy when it is sane, and echoes it in the response. The logs written
+// with the request context, down to the repositories, carry the identifier.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// probePaths are the health checks of the orchestrator
+var probePaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// accessLogMiddleware logs the outcome and duration of every request
+func accessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		level := slog.LevelInfo
+		switch {
+		case probePaths[r.URL.Path] && recorder.status == http.StatusOK:
+			// Probes arrive every few seconds; only failures are worth reading.
+			level = slog.LevelDebug
+		case probePaths[r.URL.Path]:
+			level = slog.LevelWarn
+		case recorder.status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		}
+		slog.Log(r.Context(), level, "Request completed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"durationMs", time.Since(start).Milliseconds(),
+			"remoteAddr", clientIP(r))
+	})
+}
+
+// metricsMiddleware counts the requests and measures their latency by route
+// template, so "/api/users/me/sessions/{id}" is a single series. Requests
+// that match no route are labeled "unmatched", and methods other than the
+// standard ones are labeled "other" so clients cannot create new series.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		method := metricsMethod(r.Method)
+		metrics.HTTPRequests.WithLabelValues(route, method, strconv.Itoa(recorder.status)).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// metricsMethod returns the method label of a request: the method itself
+// when it is one of the standard HTTP methods, "other" otherwise.
+func metricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "other"
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap exposes the original writer to http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// localeMiddleware stores in the request context the supported language the
+// client prefers according to its Accept-Language header. authMiddleware
+// replaces it with the preference of the authenticated user, if any.
+func localeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := i18n.MatchAcceptLanguage(r.Header.Get("Accept-Language"))
+		next.ServeHTTP(w, r.WithContext(i18n.NewContext(r.Context(), locale)))
+	})
+}
+
+// requestIDFromContext returns the identifier stored by requestIDMiddleware, if any.
+func requestIDFromContext(ctx context.Context) string {
+	return logging.RequestID(ctx)
+}
+
+// validRequestID only accepts short identifiers made of characters that are
+// safe to copy into logs and headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// timeoutMiddleware bounds the time a request may spend in the database and
+// upstream providers: its context is cancelled once the timeout elapses.
+func timeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// authMiddleware rejects requests without valid credentials and stores the
+// caller's claims in the request context. It accepts both
+// "Authorization: Bearer <jwt>" and "Authorization: ApiKey <key>".
+func authMiddleware(userService services.UserService, apiKeyService services.APIKeyService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, credentials, ok := authorizationCredentials(r)
+			if !ok {
+				respondWithError(w, r, http.StatusUnauthorized, "missing_token")
+				return
+			}
+
+			ctx := r.Context()
+			switch {
+			case strings.EqualFold(scheme, "Bearer"):
+				claims, err := userService.ValidateToken(ctx, credentials)
+				if err != nil {
+					respondWithError(w, r, http.StatusUnauthorized, credentialErrorKey(err, "invalid_token"))
+					return
+				}
+				ctx = withClaims(ctx, claims)
+			case strings.EqualFold(scheme, "ApiKey"):
+				claims, key, err := apiKeyService.Authenticate(ctx, credentials)
+				if err != nil {
+					respondWithError(w, r, http.StatusUnauthorized, credentialErrorKey(err, "invalid_api_key"))
+					return
+				}
+				ctx = withClaims(ctx, claims)
+				ctx = context.WithValue(ctx, apiKeyContextKey, key)
+			default:
+				respondWithError(w, r, http.StatusUnauthorized, "unsupported_auth_scheme")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// credentialErrorKey returns the message key of a rejected credential: the
+// key of the service error, such as invalid_token.expired, or fallback when
+// the credential could not be checked
+func credentialErrorKey(err error, fallback string) string {
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) && serviceErr.Kind == services.KindUnauthorized {
+		return serviceErr.Key
+	}
+	return fallback
+}
+
+// requireScope rejects requests authenticated with an API key that does not
+// grant the scope. Requests authenticated with a session token always pass.
+// It must run after authMiddleware.
+func requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if key := apiKeyFromContext(r.Context()); key != nil && !key.HasScope(scope) {
+			respondWithLocalizedError(w, r, http.StatusForbidden, "insufficient_scope.api_key", i18n.Params{"scope": scope})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requireSessionToken rejects requests authenticated with an API key, for
+// operations that must only be performed by the user interactively.
+// It must run after authMiddleware.
+func requireSessionToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKeyFromContext(r.Context()) != nil {
+			respondWithError(w, r, http.StatusForbidden, "session_token_required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminMiddleware only lets through authenticated users whose identifier is
+// listed as an administrator. Usernames are chosen by the users and may be
+// repeated, so they never grant admin rights.
+// It must run after authMiddleware.
+func adminMiddleware(adminUserIDs []int) func(http.Handler) http.Handler {
+	admins := make(map[int]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[id] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := claimsFromContext(r.Context())
+			if claims == nil || !admins[claims.UserID] {
+				respondWithError(w, r, http.StatusForbidden, "admin_required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// withClaims stores the caller's claims in ctx, together with the language
+// the caller prefers
+func withClaims(ctx context.Context, claims *model.Claims) context.Context {
+	if locale, ok := i18n.ParseLocale(claims.Locale); ok {
+		ctx = i18n.NewContext(ctx, locale)
+	}
+	return context.WithValue(ctx, claimsContextKey, claims)
+}
+
+// claimsFromContext returns the token claims stored by authMiddleware, if any.
+func claimsFromContext(ctx context.Context) *model.Claims {
+	claims, _ := ctx.Value(claimsContextKey).(*model.Claims)
+	return claims
+}
+
+// apiKeyFromContext returns the API key used to authenticate the request, if any.
+func apiKeyFromContext(ctx context.Context) *model.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey).(*model.APIKey)
+	return key
+}
+
+// scimClientFromContext returns the provisioning client stored by the SCIM
+// authentication middleware, if any.
+func scimClientFromContext(ctx context.Context) *model.SCIMClient {
+	client, _ := ctx.Value(scimContextKey).(*model.SCIMClient)
+	return client
+}
+
+// authorizationCredentials splits an "Authorization: <scheme> <credentials>" header
+func authorizationCredentials(r *http.Request) (string, string, bool) {
+	scheme, credentials, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	credentials = strings.TrimSpace(credentials)
+	if !found || credentials == "" {
+		return "", "", false
+	}
+	return scheme, credentials, true
+}
+
+// clientIPMiddleware resolves the address of the client once per request.
+// X-Forwarded-For is only read when the request comes from a trusted proxy,
+// so clients cannot forge the address recorded in the audit log or used by
+// the login alerts. It must run before the middleware that log the address.
+func clientIPMiddleware(trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := forwardedFor(r, trustedProxies)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPContextKey, ip)))
+		})
+	}
+}
+
+// forwardedFor returns the originating client address. Starting from the
+// peer, it walks X-Forwarded-For from right to left while the hops are
+// trusted proxies; the first untrusted hop is the client, since anything to
+// its left was written by the client itself.
+func forwardedFor(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip := remoteHost(r)
+	if !trusted(ip, trustedProxies) {
+		return ip
+	}
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !trusted(ip, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+// trusted reports whether an address belongs to one of the trusted proxies
+func trusted(address string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestMetadata collects the client information attached to audit events
+func requestMetadata(r *http.Request) model.RequestMetadata {
+	return model.RequestMetadata{
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// clientIP returns the client address resolved by clientIPMiddleware, or the
+// address of the peer when the middleware did not run.
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPContextKey).(string); ok {
+		return ip
+	}
+	return remoteHost(r)
+}
+
+// remoteHost returns the address of the peer of the connection
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/validation"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// bodyFormat describes the request bodies an endpoint accepts
+type bodyFormat struct {
+	mediaTypes []string
+	// allowUnknownFields ignores the fields the destination lacks instead of
+	// rejecting them
+	allowUnknownFields bool
+}
+
+var (
+	// jsonBody is the format of the request bodies of the API
+	jsonBody = bodyFormat{mediaTypes: []string{"application/json"}}
+	// scimBody is the format of SCIM resources, whose clients send
+	// attributes and schema extensions this server does not store
+	// (RFC 7644, section 3.1)
+	scimBody = bodyFormat{mediaTypes: []string{scimContentType, "application/json"}, allowUnknownFields: true}
+)
+
+// bodyError explains why a request body was rejected, locating the problem
+// in the body when it is malformed
+type bodyError struct {
+	status int
+	key    string
+	params i18n.Params
+	// field is the path of the offending field, if any
+	field string
+}
+
+func (e *bodyError) Error() string {
+	return i18n.Message(i18n.DefaultLocale, e.key, e.params)
+}
+
+// bodyLimitMiddleware caps the size of request bodies: reading past limit
+// bytes fails, and decodeBody reports it as 413 Payload Too Large.
+func bodyLimitMiddleware(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// decodeJSON decodes the application/json body of a request into dst and
+// rejects the request when it is not valid. It returns whether the body was
+// decoded.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	err := decodeBody(r, dst, jsonBody)
+	if err == nil {
+		return true
+	}
+
+	code, _, _ := strings.Cut(err.key, ".")
+	problem := model.Problem{Status: err.status, Code: code, MessageKey: err.key, MessageParams: err.params}
+	if err.field != "" {
+		problem.InvalidParams = []model.InvalidParam{{Name: err.field, Key: err.key, Params: err.params}}
+	}
+	respondWithProblem(w, r, problem)
+	return false
+}
+
+// decodeBody strictly decodes one JSON value of the given format into dst,
+// rejecting unknown fields unless the format allows them. Errors are located
+// by line and column, counted in bytes.
+func decodeBody(r *http.Request, dst interface{}, format bodyFormat) *bodyError {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !slices.Contains(format.mediaTypes, mediaType) {
+		return &bodyError{
+			status: http.StatusUnsupportedMediaType,
+			key:    "unsupported_media_type",
+			params: i18n.Params{"mediaType": strings.Join(format.mediaTypes, ", ")},
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return &bodyError{
+				status: http.StatusRequestEntityTooLarge,
+				key:    "invalid_body.too_large",
+				params: i18n.Params{"limit": strconv.FormatInt(maxBytesErr.Limit, 10)},
+			}
+		}
+		return &bodyError{status: http.StatusBadRequest, key: "invalid_body"}
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return &bodyError{status: http.StatusBadRequest, key: "invalid_body.empty"}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if !format.allowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(dst); err != nil {
+		return jsonError(body, err)
+	}
+
+	// Anything but whitespace after the value is a second value or garbage.
+	end := int(decoder.InputOffset())
+	if rest := bytes.TrimLeft(body[end:], " \t\r\n"); len(rest) > 0 {
+		return &bodyError{
+			status: http.StatusBadRequest,
+			key:    "invalid_body.trailing_data",
+			params: position(body, len(body)-len(rest)),
+		}
+	}
+	return nil
+}
+
+// jsonError describes the error of decoding body
+func jsonError(body []byte, err error) *bodyError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset counts the byte that broke the syntax.
+		return &bodyError{status: http.StatusBadRequest, key: "invalid_body.syntax", params: position(body, int(syntaxErr.Offset)-1)}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &bodyError{status: http.StatusBadRequest, key: "invalid_body.syntax", params: position(body, len(body))}
+	case errors.As(err, &typeErr):
+		// The offset is relative to the start of the value, right after the
+		// mistyped field.
+		start := len(body) - len(bytes.TrimLeft(body, " \t\r\n"))
+		params := position(body, start+int(typeErr.Offset)-1)
+		params["type"] = jsonType(typeErr.Type)
+		if typeErr.Field == "" {
+			return &bodyError{status: http.StatusBadRequest, key: "invalid_body.root_type", params: params}
+		}
+		params["field"] = typeErr.Field
+		return &bodyError{status: http.StatusBadRequest, key: "invalid_body.type", params: params, field: typeErr.Field}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for unknown fields.
+		field, unquoteErr := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		if unquoteErr != nil {
+			return &bodyError{status: http.StatusBadRequest, key: "invalid_body"}
+		}
+		return &bodyError{status: http.StatusBadRequest, key: "invalid_body.unknown_field", params: i18n.Params{"field": field}, field: field}
+	}
+	return &bodyError{status: http.StatusBadRequest, key: "invalid_body"}
+}
+
+// position returns the line and column of the byte at offset in body
+func position(body []byte, offset int) i18n.Params {
+	offset = max(0, min(offset, len(body)))
+	line := bytes.Count(body[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(body[:offset], '\n')
+	return i18n.Params{"line": strconv.Itoa(line), "column": strconv.Itoa(column)}
+}
+
+// jsonType names the JSON type a Go type is decoded from
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// validateRequest checks a decoded request body against the rules of its
+// validate tags and rejects the request with every invalid field. It returns
+// whether the request is valid.
+func validateRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	errs := validation.Struct(req)
+	if len(errs) == 0 {
+		return true
+	}
+
+	params := make([]model.InvalidParam, len(errs))
+	for i, err := range errs {
+		params[i] = model.InvalidParam{Name: err.Field, Code: err.Code, Key: err.Key, Params: err.Params}
+	}
+	respondWithInvalidParams(w, r, params)
+	return false
+}
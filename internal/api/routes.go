@@ -1,35 +1,266 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"exercise-login-back-go/internal/config"
+	"exercise-login-back-go/internal/connectors"
+	"exercise-login-back-go/internal/metrics"
+	"exercise-login-back-go/internal/migrations"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/notifiers"
 	"exercise-login-back-go/internal/repositories"
 	"exercise-login-back-go/internal/services"
-	"exercise-login-back-go/pkg/db"
+	"exercise-login-back-go/internal/tracing"
+	"log/slog"
+	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
-func SetupRoutes(cfg *config.Config, r *mux.Router) error {
-	return configureLoginRoutes(cfg, r)
+// SetupRoutes registers every route on top of the given database, which the
+// caller owns and closes. The returned health service lets the caller drain
+// the server on shutdown.
+func SetupRoutes(cfg *config.Config, database *sql.DB, r *mux.Router) (services.HealthService, error) {
+	if cfg.DBMigrateOnStartup {
+		if err := migrateDatabase(database, cfg.DBDriver); err != nil {
+			return nil, err
+		}
+	}
+	if err := configureLoginRoutes(cfg, database, r); err != nil {
+		return nil, err
+	}
+
+	healthService := services.NewHealthService(map[string]services.Pinger{"database": database}, cfg.ReadinessTimeout)
+	configureHealthRoutes(healthService, r)
+	return healthService, nil
+}
+
+// configureHealthRoutes sets up the liveness and readiness probes.
+func configureHealthRoutes(healthService services.HealthService, r *mux.Router) {
+	healthHandler := NewHealthHandler(healthService)
+	r.HandleFunc("/healthz", healthHandler.Liveness).Methods("GET", "HEAD")
+	r.HandleFunc("/readyz", healthHandler.Readiness).Methods("GET", "HEAD")
+}
+
+// migrateDatabase applies the pending schema migrations
+func migrateDatabase(database *sql.DB, driver string) error {
+	migrator, err := migrations.New(database, driver)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		slog.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	return err
 }
 
 // configureLoginRoutes sets up the routes for the login service.
-func configureLoginRoutes(cfg *config.Config, r *mux.Router) error {
-	database, err := db.InitializeDatabase(cfg.DBDriver, cfg.DBSource)
+func configureLoginRoutes(cfg *config.Config, database *sql.DB, r *mux.Router) error {
+	userRepository, err := repositories.NewUserRepositoryForDriver(cfg.DBDriver, database)
+	if err != nil {
+		return err
+	}
+	auditRepository, err := repositories.NewAuditRepositoryForDriver(cfg.DBDriver, database)
+	if err != nil {
+		return err
+	}
+	sessionRepository, err := repositories.NewSessionRepositoryForDriver(cfg.DBDriver, database)
+	if err != nil {
+		return err
+	}
+	apiKeyRepository, err := repositories.NewAPIKeyRepositoryForDriver(cfg.DBDriver, database)
+	if err != nil {
+		return err
+	}
+	oauthRepository, err := repositories.NewOAuthRepositoryForDriver(cfg.DBDriver, database)
+	if err != nil {
+		return err
+	}
+	identityRepository, err := repositories.NewIdentityRepositoryForDriver(cfg.DBDriver, database)
+	if err != nil {
+		return err
+	}
+	scimRepository, err := repositories.NewSCIMRepositoryForDriver(cfg.DBDriver, database)
+	if err != nil {
+		return err
+	}
+
+	// Expose the connection pool statistics.
+	if err := metrics.RegisterDB(database, cfg.DBDriver); err != nil {
+		return err
+	}
+
+	// auditLogger records authentication events.
+	auditLogger := services.NewAuditLogger(auditRepository)
+
+	// sessionService tracks the tokens issued to each user.
+	sessionService := services.NewSessionService(sessionRepository, auditLogger)
+
+	// loginAlertService notifies users about logins from unfamiliar devices.
+	notifier, err := notifiers.NewNotifier(cfg.Notifier, cfg.NotificationsFile)
 	if err != nil {
 		return err
 	}
-	userRepository := repositories.NewUserRepository(database)
+	loginAlertService := services.NewLoginAlertService(sessionRepository, sessionService, notifier, cfg.SecretKey, cfg.PublicBaseURL)
+
+	// authenticator verifies passwords locally, or against the LDAP directory
+	// configured for the user's email domain.
+	directoryAuthenticators := make(map[string]services.Authenticator)
+	for _, directory := range cfg.LDAPDirectories {
+		authenticator := services.NewDirectoryAuthenticator(connectors.NewLDAPDirectory(directory), userRepository, auditLogger)
+		for _, domain := range directory.Domains {
+			directoryAuthenticators[domain] = authenticator
+		}
+	}
+	authenticator := services.NewDomainAuthenticator(userRepository, services.NewPasswordAuthenticator(userRepository), directoryAuthenticators)
 
 	// userService is the service used to handle user operations.
-	userService := services.NewUserService(userRepository, cfg.SecretKey)
+	userService := services.NewUserService(userRepository, authenticator, sessionService, loginAlertService, auditLogger, cfg.SecretKey)
+
+	// apiKeyService manages the personal API keys used for machine access.
+	apiKeyService := services.NewAPIKeyService(apiKeyRepository, userRepository, auditLogger)
+
+	// socialLoginService signs users in with upstream OpenID Connect providers.
+	var identityProviders []model.IdentityProvider
+	for _, provider := range cfg.SocialProviders {
+		redirectURI := cfg.PublicBaseURL + "/api/auth/social/" + provider.Name + "/callback"
+		identityProviders = append(identityProviders, connectors.NewOIDCConnector(provider, redirectURI, nil))
+	}
+	socialLoginService := services.NewSocialLoginService(identityRepository, userRepository, userService, auditLogger, identityProviders)
+
+	// samlService signs in the users of enterprise tenants through SAML 2.0.
+	var samlProviders []model.SAMLIdentityProvider
+	if len(cfg.SAMLConnections) > 0 {
+		samlKey, samlCertificate, err := connectors.LoadSAMLKeyPair(cfg.SAMLCertificateFile, cfg.SAMLKeyFile)
+		if err != nil {
+			return err
+		}
+		for _, connection := range cfg.SAMLConnections {
+			connector, err := connectors.NewSAMLConnector(connection, cfg.PublicBaseURL, samlKey, samlCertificate)
+			if err != nil {
+				return err
+			}
+			samlProviders = append(samlProviders, connector)
+		}
+	}
+	samlService := services.NewSAMLService(identityRepository, userRepository, userService, auditLogger, samlProviders)
+
+	// oidcService adds the OpenID Connect provider layer to the authorization server.
+	signingKey, err := services.LoadSigningKey(cfg.OIDCSigningKeyFile)
+	if err != nil {
+		return err
+	}
+	oidcService := services.NewOIDCService(oauthRepository, userRepository, sessionService, signingKey, cfg.PublicBaseURL)
+
+	// oauthService is the OAuth 2.0 authorization server.
+	oauthService := services.NewOAuthService(oauthRepository, userRepository, oidcService, auditLogger, cfg.PublicBaseURL)
+
+	// scimService provisions users from enterprise identity-management systems.
+	scimService := services.NewSCIMService(scimRepository, userRepository, sessionService, auditLogger, cfg.PublicBaseURL)
 
 	// userHandler is the handler used to handle user requests.
 	userHandler := NewUserHandler(userService)
+	sessionHandler := NewSessionHandler(sessionService, loginAlertService)
+	apiKeyHandler := NewAPIKeyHandler(apiKeyService)
+	oauthHandler := NewOAuthHandler(oauthService, userService, cfg.OAuthConsentURL)
+	oidcHandler := NewOIDCHandler(oidcService)
+	socialLoginHandler := NewSocialLoginHandler(socialLoginService, cfg.SocialLoginRedirectURL, strings.HasPrefix(cfg.PublicBaseURL, "https://"))
+	samlHandler := NewSAMLHandler(samlService, cfg.SocialLoginRedirectURL)
+	scimHandler := NewSCIMHandler(scimService)
+	auditHandler := NewAuditHandler(auditLogger)
+	auth := authMiddleware(userService, apiKeyService)
+
+	clientIP := clientIPMiddleware(cfg.TrustedProxies)
+	r.Use(otelmux.Middleware(tracing.ServiceName), requestIDMiddleware, clientIP, accessLogMiddleware, metricsMiddleware, localeMiddleware, timeoutMiddleware(cfg.RequestTimeout), bodyLimitMiddleware(cfg.MaxRequestBodyBytes))
+	// Router middleware only runs on matched routes, so the fallbacks are
+	// wrapped themselves.
+	r.NotFoundHandler = requestIDMiddleware(clientIP(accessLogMiddleware(metricsMiddleware(localeMiddleware(http.HandlerFunc(notFound))))))
+	r.MethodNotAllowedHandler = requestIDMiddleware(clientIP(accessLogMiddleware(metricsMiddleware(localeMiddleware(http.HandlerFunc(methodNotAllowed))))))
+
+	// Prometheus metrics.
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
 
 	// Register the user registration handler.
 	r.HandleFunc("/api/users/register", userHandler.RegisterUser).Methods("POST")
 	r.HandleFunc("/api/users/login", userHandler.LoginUser).Methods("POST")
+	r.HandleFunc("/api/users/sessions/revoke", sessionHandler.ConfirmRevokeSessionsFromAlert).Methods("GET")
+	r.HandleFunc("/api/users/sessions/revoke", sessionHandler.RevokeSessionsFromAlert).Methods("POST")
+
+	// Sign in with upstream identity providers.
+	r.HandleFunc("/api/auth/social", socialLoginHandler.GetProviders).Methods("GET")
+	r.HandleFunc("/api/auth/social/{provider}", socialLoginHandler.StartLogin).Methods("GET")
+	r.HandleFunc("/api/auth/social/{provider}/callback", socialLoginHandler.Callback).Methods("GET")
+
+	// Enterprise single sign-on through SAML 2.0.
+	r.HandleFunc("/saml/{connection}/metadata", samlHandler.Metadata).Methods("GET")
+	r.HandleFunc("/saml/{connection}/login", samlHandler.StartLogin).Methods("GET")
+	r.HandleFunc("/saml/{connection}/acs", samlHandler.AssertionConsumerService).Methods("POST")
+
+	// Routes of the authenticated user.
+	me := r.PathPrefix("/api/users/me").Subrouter()
+	me.Use(auth)
+	me.HandleFunc("/sessions", requireScope(model.ScopeSessionsRead, sessionHandler.GetSessions)).Methods("GET")
+	me.HandleFunc("/sessions/{id}", requireScope(model.ScopeSessionsWrite, sessionHandler.RevokeSession)).Methods("DELETE")
+	me.Handle("/locale", requireSessionToken(http.HandlerFunc(userHandler.UpdateLocale))).Methods("PUT")
+
+	// API keys can only be managed with a session token.
+	apiKeys := me.PathPrefix("/api-keys").Subrouter()
+	apiKeys.Use(requireSessionToken)
+	apiKeys.HandleFunc("", apiKeyHandler.CreateAPIKey).Methods("POST")
+	apiKeys.HandleFunc("", apiKeyHandler.GetAPIKeys).Methods("GET")
+	apiKeys.HandleFunc("/{id}", apiKeyHandler.RevokeAPIKey).Methods("DELETE")
+
+	// Linked identities can only be managed with a session token.
+	identities := me.PathPrefix("/identities").Subrouter()
+	identities.Use(requireSessionToken)
+	identities.HandleFunc("", socialLoginHandler.GetIdentities).Methods("GET")
+	identities.HandleFunc("/{provider}", socialLoginHandler.StartLink).Methods("POST")
+	identities.HandleFunc("/{provider}", socialLoginHandler.Unlink).Methods("DELETE")
+
+	// OAuth 2.0 authorization server. Only the consent decision requires the
+	// user's session; the other endpoints authenticate the client.
+	r.HandleFunc("/oauth/authorize", oauthHandler.Authorize).Methods("GET")
+	r.Handle("/oauth/authorize", auth(requireSessionToken(http.HandlerFunc(oauthHandler.AuthorizeDecision)))).Methods("POST")
+	r.HandleFunc("/oauth/token", oauthHandler.Token).Methods("POST")
+	r.HandleFunc("/oauth/introspect", oauthHandler.Introspect).Methods("POST")
+	r.HandleFunc("/oauth/revoke", oauthHandler.Revoke).Methods("POST")
+
+	// OpenID Connect provider.
+	r.HandleFunc("/.well-known/openid-configuration", oidcHandler.Discovery).Methods("GET")
+	r.HandleFunc("/.well-known/jwks.json", oidcHandler.JWKS).Methods("GET")
+	r.HandleFunc("/userinfo", oidcHandler.UserInfo).Methods("GET", "POST")
+	r.HandleFunc("/oauth/logout", oidcHandler.Logout).Methods("GET", "POST")
+
+	// SCIM 2.0 provisioning. Discovery is public; users require the bearer
+	// token of a provisioning client.
+	r.HandleFunc("/scim/v2/ServiceProviderConfig", scimHandler.ServiceProviderConfig).Methods("GET")
+	r.HandleFunc("/scim/v2/ResourceTypes", scimHandler.GetResourceTypes).Methods("GET")
+	r.HandleFunc("/scim/v2/ResourceTypes/{id}", scimHandler.GetResourceTypes).Methods("GET")
+	r.HandleFunc("/scim/v2/Schemas", scimHandler.GetSchemas).Methods("GET")
+	r.HandleFunc("/scim/v2/Schemas/{id}", scimHandler.GetSchemas).Methods("GET")
+	scimUsers := r.PathPrefix("/scim/v2/Users").Subrouter()
+	scimUsers.Use(scimHandler.Authenticate)
+	scimUsers.HandleFunc("", scimHandler.ListUsers).Methods("GET")
+	scimUsers.HandleFunc("", scimHandler.CreateUser).Methods("POST")
+	scimUsers.HandleFunc("/{id}", scimHandler.GetUser).Methods("GET")
+	scimUsers.HandleFunc("/{id}", scimHandler.ReplaceUser).Methods("PUT")
+	scimUsers.HandleFunc("/{id}", scimHandler.PatchUser).Methods("PATCH")
+	scimUsers.HandleFunc("/{id}", scimHandler.DeleteUser).Methods("DELETE")
+
+	// Admin routes require an authenticated administrator.
+	admin := r.PathPrefix("/api/admin").Subrouter()
+	admin.Use(auth, adminMiddleware(cfg.AdminUserIDs))
+	admin.HandleFunc("/audit-events", requireScope(model.ScopeAuditRead, auditHandler.GetAuditEvents)).Methods("GET")
+	admin.HandleFunc("/oauth/clients", requireScope(model.ScopeOAuthClients, oauthHandler.RegisterClient)).Methods("POST")
+	admin.HandleFunc("/oauth/clients", requireScope(model.ScopeOAuthClients, oauthHandler.GetClients)).Methods("GET")
+	admin.HandleFunc("/oauth/clients/{clientId}", requireScope(model.ScopeOAuthClients, oauthHandler.DeleteClient)).Methods("DELETE")
+	admin.HandleFunc("/scim/clients", requireScope(model.ScopeSCIMClients, scimHandler.CreateClient)).Methods("POST")
+	admin.HandleFunc("/scim/clients", requireScope(model.ScopeSCIMClients, scimHandler.GetClients)).Methods("GET")
+	admin.HandleFunc("/scim/clients/{id}", requireScope(model.ScopeSCIMClients, scimHandler.DeleteClient)).Methods("DELETE")
 
 	return nil
 }
@@ -0,0 +1,80 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"exercise-login-back-go/internal/api"
+	"exercise-login-back-go/internal/config"
+	"exercise-login-back-go/pkg/db"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSQLiteLogin runs the whole service on an in-memory SQLite database, so
+// every repository used by a login has a SQLite version.
+func TestSQLiteLogin(t *testing.T) {
+	database, err := db.InitializeDatabase("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	cfg := config.Config{
+		DBDriver:            "sqlite",
+		DBMigrateOnStartup:  true,
+		SecretKey:           "secret",
+		PublicBaseURL:       "http://localhost",
+		Notifier:            "log",
+		RequestTimeout:      15 * time.Second,
+		MaxRequestBodyBytes: 1 << 20,
+		ReadinessTimeout:    time.Second,
+	}
+	router := mux.NewRouter()
+	_, err = api.SetupRoutes(&cfg, database, router)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send("POST", "/api/users/register", "", map[string]string{
+		"username": "testuser",
+		"email":    "test@example.com",
+		"phone":    "1234567890",
+		"password": "Password@123",
+	})
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	resp = send("POST", "/api/users/login", "", map[string]string{"emailOrUsername": "testuser", "password": "Password@123"})
+	if !assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String()) {
+		return
+	}
+	var login map[string]string
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &login))
+	assert.NotEmpty(t, login["token"])
+
+	// The token is backed by the session stored in SQLite.
+	resp = send("GET", "/api/users/me/sessions", login["token"], nil)
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var sessions []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &sessions))
+	assert.Len(t, sessions, 1)
+
+	resp = send("POST", "/api/users/login", "", map[string]string{"emailOrUsername": "testuser", "password": "Wrong@123"})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code, resp.Body.String())
+}
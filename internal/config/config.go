@@ -1,30 +1,343 @@
 package config
 
 import (
-	"log"
+	"exercise-login-back-go/internal/logging"
+	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DBDriver  string
-	DBSource  string
-	SecretKey string
+	DBDriver string
+	DBSource string
+	// DBMigrateOnStartup applies the pending schema migrations when the server starts.
+	DBMigrateOnStartup bool
+	SecretKey          string
+	// AdminUserIDs are the identifiers of the users allowed on the admin
+	// routes. Identifiers are assigned by the database, unlike usernames,
+	// which users choose and are not unique.
+	AdminUserIDs  []int
+	PublicBaseURL string
+	// TrustedProxies are the networks of the proxies whose X-Forwarded-For
+	// header is believed. Requests from any other address are attributed to
+	// the address itself.
+	TrustedProxies    []*net.IPNet
+	Notifier          string
+	NotificationsFile string
+	OAuthConsentURL   string
+	// OIDCSigningKeyFile is the PEM file with the RSA key that signs ID tokens.
+	OIDCSigningKeyFile string
+	// SocialProviders are the upstream OpenID Connect providers users can sign in with.
+	SocialProviders        []SocialProvider
+	SocialLoginRedirectURL string
+	// LDAPDirectories authenticate the users of their email domains.
+	LDAPDirectories []LDAPDirectory
+	// SAMLConnections are the SAML 2.0 identity providers of enterprise tenants.
+	SAMLConnections []SAMLConnection
+	// SAMLCertificateFile and SAMLKeyFile are the PEM files with the
+	// certificate and RSA key of the service provider.
+	SAMLCertificateFile string
+	SAMLKeyFile         string
+	// RequestTimeout is the deadline of each request, including its database queries.
+	RequestTimeout time.Duration
+	// MaxRequestBodyBytes is the largest request body accepted, in bytes.
+	MaxRequestBodyBytes int64
+	// LogLevel is the minimum level of the logs written.
+	LogLevel slog.Level
+	// TracingExporter is where the OpenTelemetry spans are sent: "none",
+	// "otlp" or "stdout".
+	TracingExporter string
+	// ReadinessTimeout is how long the readiness probe waits for each dependency.
+	ReadinessTimeout time.Duration
+	// HTTPServer configures the listener, its limits and its shutdown.
+	HTTPServer HTTPServer
+}
+
+// HTTPServer configures the HTTP server. The read timeouts protect it from
+// clients that send their requests slowly to hold connections open.
+type HTTPServer struct {
+	Addr              string
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	// WriteTimeout must exceed RequestTimeout, or slow requests are cut
+	// before they can answer.
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
+	// DrainPeriod is how long the server keeps serving while it reports not
+	// ready, so the load balancer stops sending traffic before it stops.
+	DrainPeriod time.Duration
+	// ShutdownTimeout is how long the in-flight requests have to finish.
+	ShutdownTimeout time.Duration
+}
+
+// SocialProvider configures an upstream OpenID Connect provider.
+type SocialProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
 }
 
 // LoadConfig loads the configuration from the environment variables
 func LoadConfig() (config Config, err error) {
 	err = godotenv.Load()
 	if err != nil {
-		log.Println("No config file found")
+		slog.Info("No config file found")
 	}
 
 	config = Config{
-		DBDriver:  os.Getenv("DB_DRIVER"),
-		DBSource:  os.Getenv("DB_SOURCE"),
-		SecretKey: os.Getenv("JWT_SECRET_KEY"),
+		DBDriver:               os.Getenv("DB_DRIVER"),
+		DBSource:               os.Getenv("DB_SOURCE"),
+		SecretKey:              os.Getenv("JWT_SECRET_KEY"),
+		PublicBaseURL:          strings.TrimSuffix(getEnv("PUBLIC_BASE_URL", "http://localhost"), "/"),
+		Notifier:               getEnv("NOTIFIER", "log"),
+		NotificationsFile:      os.Getenv("NOTIFICATIONS_FILE"),
+		OAuthConsentURL:        os.Getenv("OAUTH_CONSENT_URL"),
+		OIDCSigningKeyFile:     os.Getenv("OIDC_SIGNING_KEY_FILE"),
+		SocialLoginRedirectURL: os.Getenv("SOCIAL_LOGIN_REDIRECT_URL"),
+		SAMLCertificateFile:    os.Getenv("SAML_SP_CERT_FILE"),
+		SAMLKeyFile:            os.Getenv("SAML_SP_KEY_FILE"),
+	}
+	// The embedded SQLite database is migrated by default so local runs need no setup.
+	defaultMigrate := "false"
+	if config.DBDriver == "sqlite" {
+		defaultMigrate = "true"
+	}
+	config.DBMigrateOnStartup = getEnv("DB_MIGRATE_ON_STARTUP", defaultMigrate) == "true"
+	config.AdminUserIDs, err = parseIDs(os.Getenv("ADMIN_USER_IDS"))
+	if err != nil {
+		return config, fmt.Errorf("invalid ADMIN_USER_IDS: %v", err)
+	}
+	config.TrustedProxies, err = parseNetworks(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return config, fmt.Errorf("invalid TRUSTED_PROXIES: %v", err)
+	}
+	config.RequestTimeout, err = getDuration("REQUEST_TIMEOUT", "15s")
+	if err != nil {
+		return config, err
+	}
+	if config.RequestTimeout <= 0 {
+		return config, fmt.Errorf("invalid REQUEST_TIMEOUT: %s must be positive", config.RequestTimeout)
+	}
+	config.MaxRequestBodyBytes, err = strconv.ParseInt(getEnv("MAX_REQUEST_BODY_BYTES", "1048576"), 10, 64)
+	if err != nil || config.MaxRequestBodyBytes <= 0 {
+		return config, fmt.Errorf("invalid MAX_REQUEST_BODY_BYTES: %q", os.Getenv("MAX_REQUEST_BODY_BYTES"))
+	}
+	config.LogLevel, err = logging.ParseLevel(getEnv("LOG_LEVEL", "info"))
+	if err != nil {
+		return config, fmt.Errorf("invalid LOG_LEVEL: %v", err)
+	}
+	config.ReadinessTimeout, err = getDuration("READINESS_TIMEOUT", "2s")
+	if err != nil {
+		return config, err
+	}
+	config.TracingExporter = getEnv("TRACING_EXPORTER", "none")
+	switch config.TracingExporter {
+	case "none", "otlp", "stdout":
+	default:
+		return config, fmt.Errorf("invalid TRACING_EXPORTER: %q", config.TracingExporter)
+	}
+	config.HTTPServer, err = loadHTTPServer()
+	if err != nil {
+		return config, err
+	}
+	if config.HTTPServer.WriteTimeout <= config.RequestTimeout {
+		return config, fmt.Errorf("HTTP_WRITE_TIMEOUT (%s) must exceed REQUEST_TIMEOUT (%s)", config.HTTPServer.WriteTimeout, config.RequestTimeout)
 	}
+	config.SocialProviders = loadSocialProviders(splitList(os.Getenv("SOCIAL_PROVIDERS")))
+	config.LDAPDirectories = loadLDAPDirectories(splitList(os.Getenv("LDAP_DIRECTORIES")))
+	config.SAMLConnections = loadSAMLConnections(splitList(os.Getenv("SAML_CONNECTIONS")))
 
 	return config, nil
 }
+
+// LDAPDirectory configures an LDAP or Active Directory server used to
+// authenticate the users of some email domains.
+type LDAPDirectory struct {
+	Name              string
+	URL               string
+	StartTLS          bool
+	BindDN            string
+	BindPassword      string
+	BaseDN            string
+	UserFilter        string
+	UsernameAttribute string
+	EmailAttribute    string
+	PhoneAttribute    string
+	Domains           []string
+}
+
+// SAMLConnection configures the SAML 2.0 identity provider of an enterprise
+// tenant and how its assertion attributes map onto a local user.
+type SAMLConnection struct {
+	Name              string
+	IDPMetadataFile   string
+	Binding           string
+	UsernameAttribute string
+	EmailAttribute    string
+	PhoneAttribute    string
+	Domains           []string
+}
+
+// loadSocialProviders reads the settings of each upstream provider from the
+// SOCIAL_<NAME>_ISSUER, SOCIAL_<NAME>_CLIENT_ID, SOCIAL_<NAME>_CLIENT_SECRET and
+// SOCIAL_<NAME>_SCOPES environment variables.
+func loadSocialProviders(names []string) []SocialProvider {
+	var providers []SocialProvider
+	for _, name := range names {
+		prefix := "SOCIAL_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		providers = append(providers, SocialProvider{
+			Name:         name,
+			Issuer:       strings.TrimSuffix(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		})
+	}
+	return providers
+}
+
+// loadLDAPDirectories reads the settings of each directory from the
+// LDAP_<NAME>_* environment variables.
+func loadLDAPDirectories(names []string) []LDAPDirectory {
+	var directories []LDAPDirectory
+	for _, name := range names {
+		prefix := "LDAP_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		directories = append(directories, LDAPDirectory{
+			Name:              name,
+			URL:               os.Getenv(prefix + "URL"),
+			StartTLS:          os.Getenv(prefix+"START_TLS") == "true",
+			BindDN:            os.Getenv(prefix + "BIND_DN"),
+			BindPassword:      os.Getenv(prefix + "BIND_PASSWORD"),
+			BaseDN:            os.Getenv(prefix + "BASE_DN"),
+			UserFilter:        getEnv(prefix+"USER_FILTER", "(mail=%s)"),
+			UsernameAttribute: getEnv(prefix+"USERNAME_ATTRIBUTE", "uid"),
+			EmailAttribute:    getEnv(prefix+"EMAIL_ATTRIBUTE", "mail"),
+			PhoneAttribute:    getEnv(prefix+"PHONE_ATTRIBUTE", "telephoneNumber"),
+			Domains:           splitList(strings.ToLower(os.Getenv(prefix + "DOMAINS"))),
+		})
+	}
+	return directories
+}
+
+// loadSAMLConnections reads the settings of each connection from the
+// SAML_<NAME>_* environment variables.
+func loadSAMLConnections(names []string) []SAMLConnection {
+	var connections []SAMLConnection
+	for _, name := range names {
+		prefix := "SAML_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		connections = append(connections, SAMLConnection{
+			Name:              name,
+			IDPMetadataFile:   os.Getenv(prefix + "IDP_METADATA_FILE"),
+			Binding:           getEnv(prefix+"BINDING", "redirect"),
+			UsernameAttribute: getEnv(prefix+"USERNAME_ATTRIBUTE", "uid"),
+			EmailAttribute:    getEnv(prefix+"EMAIL_ATTRIBUTE", "email"),
+			PhoneAttribute:    getEnv(prefix+"PHONE_ATTRIBUTE", "phone"),
+			Domains:           splitList(strings.ToLower(os.Getenv(prefix + "DOMAINS"))),
+		})
+	}
+	return connections
+}
+
+// loadHTTPServer reads the settings of the HTTP server from the HTTP_* and
+// SHUTDOWN_* environment variables.
+func loadHTTPServer() (server HTTPServer, err error) {
+	server.Addr = getEnv("HTTP_ADDR", ":80")
+	durations := []struct {
+		value        *time.Duration
+		key          string
+		defaultValue string
+	}{
+		{&server.ReadHeaderTimeout, "HTTP_READ_HEADER_TIMEOUT", "5s"},
+		{&server.ReadTimeout, "HTTP_READ_TIMEOUT", "30s"},
+		{&server.WriteTimeout, "HTTP_WRITE_TIMEOUT", "30s"},
+		{&server.IdleTimeout, "HTTP_IDLE_TIMEOUT", "120s"},
+		{&server.DrainPeriod, "SHUTDOWN_DRAIN_PERIOD", "5s"},
+		{&server.ShutdownTimeout, "SHUTDOWN_TIMEOUT", "25s"},
+	}
+	for _, d := range durations {
+		if *d.value, err = getDuration(d.key, d.defaultValue); err != nil {
+			return server, err
+		}
+	}
+	server.MaxHeaderBytes, err = strconv.Atoi(getEnv("HTTP_MAX_HEADER_BYTES", "65536"))
+	if err != nil || server.MaxHeaderBytes <= 0 {
+		return server, fmt.Errorf("invalid HTTP_MAX_HEADER_BYTES: %q", os.Getenv("HTTP_MAX_HEADER_BYTES"))
+	}
+	return server, nil
+}
+
+// getDuration parses an environment variable holding a Go duration, such as "15s"
+func getDuration(key, defaultValue string) (time.Duration, error) {
+	value, err := time.ParseDuration(getEnv(key, defaultValue))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", key, err)
+	}
+	return value, nil
+}
+
+// getEnv returns the value of an environment variable or a default when it is not set
+func getEnv(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// splitList splits a comma separated environment variable into its trimmed, non-empty values
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// parseIDs parses a comma separated list of positive identifiers
+func parseIDs(value string) ([]int, error) {
+	var ids []int
+	for _, item := range splitList(value) {
+		id, err := strconv.Atoi(item)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%q is not a user identifier", item)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseNetworks parses a comma separated list of IP addresses and CIDR
+// networks. A single address is a network of its own.
+func parseNetworks(value string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, item := range splitList(value) {
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address", item)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
@@ -0,0 +1,110 @@
+package connectors
+
+import (
+	"crypto/tls"
+	"exercise-login-back-go/internal/config"
+	"exercise-login-back-go/internal/model"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	// ldapTimeout bounds the connection to and the searches on the directory.
+	ldapTimeout = 10 * time.Second
+)
+
+// LDAPDirectory authenticates users with a search and bind against an LDAP
+// or Active Directory server: it binds with the service account, searches the
+// user entry and binds again as the user to verify the password.
+type LDAPDirectory struct {
+	cfg  config.LDAPDirectory
+	dial func() (ldap.Client, error)
+}
+
+// NewLDAPDirectory creates a directory for the given server. A connection is
+// opened for every authentication.
+func NewLDAPDirectory(cfg config.LDAPDirectory) *LDAPDirectory {
+	directory := &LDAPDirectory{cfg: cfg}
+	directory.dial = directory.connect
+	return directory
+}
+
+// NewLDAPDirectoryWithDialer creates a directory that obtains its connections
+// from dial, which lets tests replace the server.
+func NewLDAPDirectoryWithDialer(cfg config.LDAPDirectory, dial func() (ldap.Client, error)) *LDAPDirectory {
+	return &LDAPDirectory{cfg: cfg, dial: dial}
+}
+
+// Authenticate verifies the password of the user matching the login.
+func (d *LDAPDirectory) Authenticate(login, password string) (*model.DirectoryEntry, error) {
+	// An empty password would be an unauthenticated bind, which many servers accept.
+	if login == "" || password == "" {
+		return nil, nil
+	}
+
+	conn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if d.cfg.BindDN != "" {
+		if err := conn.Bind(d.cfg.BindDN, d.cfg.BindPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	filter := strings.ReplaceAll(d.cfg.UserFilter, "%s", ldap.EscapeFilter(login))
+	attributes := []string{d.cfg.UsernameAttribute, d.cfg.EmailAttribute, d.cfg.PhoneAttribute}
+	result, err := conn.Search(ldap.NewSearchRequest(d.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(ldapTimeout.Seconds()), false, filter, attributes, nil))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+			return nil, nil // The login is ambiguous
+		}
+		return nil, err
+	}
+	if len(result.Entries) != 1 {
+		return nil, nil
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &model.DirectoryEntry{
+		DN:       entry.DN,
+		Username: entry.GetAttributeValue(d.cfg.UsernameAttribute),
+		Email:    entry.GetAttributeValue(d.cfg.EmailAttribute),
+		Phone:    entry.GetAttributeValue(d.cfg.PhoneAttribute),
+	}, nil
+}
+
+// connect opens a connection to the configured server
+func (d *LDAPDirectory) connect() (ldap.Client, error) {
+	conn, err := ldap.DialURL(d.cfg.URL, ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(ldapTimeout)
+
+	if d.cfg.StartTLS {
+		serverName := ""
+		if parsed, err := url.Parse(d.cfg.URL); err == nil {
+			serverName = parsed.Hostname()
+		}
+		if err := conn.StartTLS(&tls.Config{ServerName: serverName}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
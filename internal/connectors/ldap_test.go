@@ -0,0 +1,94 @@
+package connectors_test
+
+import (
+	"exercise-login-back-go/internal/config"
+	"exercise-login-back-go/internal/connectors"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLDAP is an in-memory directory with a service account and one user
+type fakeLDAP struct {
+	ldap.Client
+	filters []string
+	closed  bool
+}
+
+func (f *fakeLDAP) Bind(username, password string) error {
+	switch {
+	case username == "cn=service,dc=corp" && password == "service-secret":
+		return nil
+	case username == "uid=jane,ou=people,dc=corp" && password == "jane-secret":
+		return nil
+	}
+	return ldap.NewError(ldap.LDAPResultInvalidCredentials, nil)
+}
+
+func (f *fakeLDAP) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	f.filters = append(f.filters, req.Filter)
+	if req.Filter != "(mail=jane@corp.example.com)" {
+		return &ldap.SearchResult{}, nil
+	}
+	return &ldap.SearchResult{Entries: []*ldap.Entry{
+		ldap.NewEntry("uid=jane,ou=people,dc=corp", map[string][]string{
+			"uid":             {"jane"},
+			"mail":            {"jane@corp.example.com"},
+			"telephoneNumber": {"5512345678"},
+		}),
+	}}, nil
+}
+
+func (f *fakeLDAP) Close() error {
+	f.closed = true
+	return nil
+}
+
+func newLDAPDirectory(conn *fakeLDAP) *connectors.LDAPDirectory {
+	cfg := config.LDAPDirectory{
+		BindDN:            "cn=service,dc=corp",
+		BindPassword:      "service-secret",
+		BaseDN:            "dc=corp",
+		UserFilter:        "(mail=%s)",
+		UsernameAttribute: "uid",
+		EmailAttribute:    "mail",
+		PhoneAttribute:    "telephoneNumber",
+	}
+	return connectors.NewLDAPDirectoryWithDialer(cfg, func() (ldap.Client, error) { return conn, nil })
+}
+
+func TestLDAPDirectoryAuthenticate(t *testing.T) {
+	t.Run("Valid Credentials", func(t *testing.T) {
+		conn := &fakeLDAP{}
+		entry, err := newLDAPDirectory(conn).Authenticate("jane@corp.example.com", "jane-secret")
+		assert.NoError(t, err)
+		assert.Equal(t, "uid=jane,ou=people,dc=corp", entry.DN)
+		assert.Equal(t, "jane", entry.Username)
+		assert.Equal(t, "jane@corp.example.com", entry.Email)
+		assert.Equal(t, "5512345678", entry.Phone)
+		assert.True(t, conn.closed)
+	})
+
+	t.Run("Wrong Password", func(t *testing.T) {
+		entry, err := newLDAPDirectory(&fakeLDAP{}).Authenticate("jane@corp.example.com", "wrong")
+		assert.NoError(t, err)
+		assert.Nil(t, entry)
+	})
+
+	t.Run("Empty Password", func(t *testing.T) {
+		conn := &fakeLDAP{}
+		entry, err := newLDAPDirectory(conn).Authenticate("jane@corp.example.com", "")
+		assert.NoError(t, err)
+		assert.Nil(t, entry)
+		assert.Empty(t, conn.filters)
+	})
+
+	t.Run("Escapes Filter", func(t *testing.T) {
+		conn := &fakeLDAP{}
+		entry, err := newLDAPDirectory(conn).Authenticate("*)(uid=*", "jane-secret")
+		assert.NoError(t, err)
+		assert.Nil(t, entry)
+		assert.Equal(t, []string{`(mail=\2a\29\28uid=\2a)`}, conn.filters)
+	})
+}
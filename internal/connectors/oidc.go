@@ -0,0 +1,278 @@
+package connectors
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"exercise-login-back-go/internal/config"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// httpTimeout bounds every request made to an upstream provider.
+const httpTimeout = 10 * time.Second
+
+// OIDCConnector signs users in with an upstream OpenID Connect provider using
+// the authorization code flow with PKCE.
+type OIDCConnector struct {
+	provider    config.SocialProvider
+	redirectURI string
+	httpClient  *http.Client
+
+	mu       sync.Mutex
+	metadata *providerMetadata
+	keys     map[string]*rsa.PublicKey
+}
+
+// providerMetadata holds the fields of the discovery document used by the connector
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// tokenResponse holds the fields of the token endpoint response used by the connector
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// NewOIDCConnector creates a connector for an upstream provider. The
+// discovery document is fetched on first use.
+func NewOIDCConnector(provider config.SocialProvider, redirectURI string, httpClient *http.Client) *OIDCConnector {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: httpTimeout}
+	}
+	return &OIDCConnector{
+		provider:    provider,
+		redirectURI: redirectURI,
+		httpClient:  httpClient,
+	}
+}
+
+// Name returns the name the provider was configured with.
+func (c *OIDCConnector) Name() string {
+	return c.provider.Name
+}
+
+// AuthCodeURL returns the URL of the upstream authorization endpoint the user
+// must be sent to.
+func (c *OIDCConnector) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", c.provider.ClientID)
+	params.Set("redirect_uri", c.redirectURI)
+	params.Set("scope", strings.Join(c.provider.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the identity asserted by
+// the validated ID token.
+func (c *OIDCConnector) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*model.ExternalIdentity, error) {
+	metadata, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.redirectURI)
+	form.Set("code_verifier", codeVerifier)
+	if c.provider.ClientSecret == "" {
+		form.Set("client_id", c.provider.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.provider.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.provider.ClientID), url.QueryEscape(c.provider.ClientSecret))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("invalid token response from %s: %v", c.provider.Name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint of %s returned %d: %s %s", c.provider.Name, resp.StatusCode, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("token response from %s has no id_token", c.provider.Name)
+	}
+
+	return c.verifyIDToken(ctx, metadata, token.IDToken, nonce)
+}
+
+// verifyIDToken validates the signature and claims of an upstream ID token
+func (c *OIDCConnector) verifyIDToken(ctx context.Context, metadata *providerMetadata, idToken, nonce string) (*model.ExternalIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return c.publicKey(ctx, metadata, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token from %s: %v", c.provider.Name, err)
+	}
+
+	if issuer, _ := claims["iss"].(string); issuer != metadata.Issuer {
+		return nil, fmt.Errorf("ID token from %s has an unexpected issuer %q", c.provider.Name, issuer)
+	}
+	if !claims.VerifyAudience(c.provider.ClientID, true) && !audienceContains(claims["aud"], c.provider.ClientID) {
+		return nil, fmt.Errorf("ID token from %s was not issued for this client", c.provider.Name)
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, fmt.Errorf("ID token from %s has an unexpected nonce", c.provider.Name)
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("ID token from %s has no subject", c.provider.Name)
+	}
+
+	identity := &model.ExternalIdentity{Provider: c.provider.Name, Subject: subject}
+	identity.Email, _ = claims["email"].(string)
+	identity.PreferredUsername, _ = claims["preferred_username"].(string)
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		// Some providers send the claim as a string.
+		identity.EmailVerified = verified == "true"
+	}
+	return identity, nil
+}
+
+// audienceContains reports whether a list valued aud claim includes the client
+func audienceContains(audience interface{}, clientID string) bool {
+	values, ok := audience.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, value := range values {
+		if value == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// discover fetches and caches the discovery document of the provider
+func (c *OIDCConnector) discover(ctx context.Context) (*providerMetadata, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.metadata != nil {
+		return c.metadata, nil
+	}
+
+	var metadata providerMetadata
+	if err := c.getJSON(ctx, c.provider.Issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, err
+	}
+	if metadata.Issuer != c.provider.Issuer {
+		return nil, fmt.Errorf("discovery document of %s has issuer %q, expected %q", c.provider.Name, metadata.Issuer, c.provider.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document of %s is incomplete", c.provider.Name)
+	}
+	c.metadata = &metadata
+	return c.metadata, nil
+}
+
+// publicKey returns the signing key with the given ID, refreshing the cached
+// key set once when the key is unknown so rotated keys are picked up.
+func (c *OIDCConnector) publicKey(ctx context.Context, metadata *providerMetadata, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+
+	var keySet struct {
+		Keys []struct {
+			KeyType  string `json:"kty"`
+			KeyID    string `json:"kid"`
+			Modulus  string `json:"n"`
+			Exponent string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := c.getJSON(ctx, metadata.JWKSURI, &keySet); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range keySet.Keys {
+		if jwk.KeyType != "RSA" {
+			continue
+		}
+		modulus, errN := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+		exponent, errE := base64.RawURLEncoding.DecodeString(jwk.Exponent)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[jwk.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		}
+	}
+	c.keys = keys
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key " + kid)
+	}
+	return key, nil
+}
+
+// getJSON fetches and decodes a JSON document
+func (c *OIDCConnector) getJSON(ctx context.Context, documentURL string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, documentURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", documentURL, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,89 @@
+package connectors_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"exercise-login-back-go/internal/connectors/oidctest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthCodeURL(t *testing.T) {
+	idp := oidctest.NewIdP(t)
+
+	authURL, err := idp.Connector("stub").AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge-1")
+	assert.NoError(t, err)
+
+	parsed, _ := url.Parse(authURL)
+	assert.Equal(t, idp.Server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	query := parsed.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, oidctest.ClientID, query.Get("client_id"))
+	assert.Equal(t, "http://localhost/api/auth/social/stub/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "openid email", query.Get("scope"))
+	assert.Equal(t, "state-1", query.Get("state"))
+	assert.Equal(t, "nonce-1", query.Get("nonce"))
+	assert.Equal(t, "challenge-1", query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+}
+
+func TestExchange(t *testing.T) {
+	t.Run("valid ID token", func(t *testing.T) {
+		idp := oidctest.NewIdP(t)
+		idp.Claims = jwt.MapClaims{"nonce": "nonce-1", "email": "ana@example.com", "email_verified": true, "preferred_username": "ana"}
+
+		identity, err := idp.Connector("stub").Exchange(context.Background(), oidctest.Code, "verifier-1", "nonce-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "verifier-1", idp.CodeVerifier())
+		assert.Equal(t, "stub", identity.Provider)
+		assert.Equal(t, "upstream-42", identity.Subject)
+		assert.Equal(t, "ana@example.com", identity.Email)
+		assert.True(t, identity.EmailVerified)
+		assert.Equal(t, "ana", identity.PreferredUsername)
+	})
+
+	t.Run("audience list", func(t *testing.T) {
+		idp := oidctest.NewIdP(t)
+		idp.Claims = jwt.MapClaims{"nonce": "nonce-1", "aud": []string{"other", oidctest.ClientID}}
+
+		_, err := idp.Connector("stub").Exchange(context.Background(), oidctest.Code, "verifier-1", "nonce-1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejected code", func(t *testing.T) {
+		idp := oidctest.NewIdP(t)
+		_, err := idp.Connector("stub").Exchange(context.Background(), "wrong-code", "verifier-1", "nonce-1")
+		assert.Error(t, err)
+	})
+
+	invalid := map[string]jwt.MapClaims{
+		"wrong nonce":    {"nonce": "other"},
+		"wrong audience": {"nonce": "nonce-1", "aud": "other-client"},
+		"wrong issuer":   {"nonce": "nonce-1", "iss": "https://evil.example.com"},
+		"expired":        {"nonce": "nonce-1", "exp": time.Now().Add(-time.Minute).Unix()},
+		"no subject":     {"nonce": "nonce-1", "sub": ""},
+	}
+	for name, claims := range invalid {
+		t.Run(name, func(t *testing.T) {
+			idp := oidctest.NewIdP(t)
+			idp.Claims = claims
+
+			_, err := idp.Connector("stub").Exchange(context.Background(), oidctest.Code, "verifier-1", "nonce-1")
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("token signed by another key", func(t *testing.T) {
+		idp := oidctest.NewIdP(t)
+		idp.Claims = jwt.MapClaims{"nonce": "nonce-1"}
+		idp.Key, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+		_, err := idp.Connector("stub").Exchange(context.Background(), oidctest.Code, "verifier-1", "nonce-1")
+		assert.Error(t, err)
+	})
+}
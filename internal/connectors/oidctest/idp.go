@@ -0,0 +1,124 @@
+// Package oidctest provides a stub OpenID Connect provider for tests.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"exercise-login-back-go/internal/config"
+	"exercise-login-back-go/internal/connectors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	// ClientID and ClientSecret are the credentials the stub expects.
+	ClientID     = "local-client"
+	ClientSecret = "local-secret"
+	// Code is the only authorization code the stub accepts.
+	Code = "upstream-code"
+)
+
+// IdP is a minimal OpenID Connect provider serving discovery, keys and a
+// token endpoint that answers with an ID token built from Claims.
+type IdP struct {
+	Server *httptest.Server
+	// Key signs the ID tokens; replacing it simulates a forged token.
+	Key *rsa.PrivateKey
+	// Claims are added to the ID token; iss, aud, sub, exp and iat are set by default.
+	Claims jwt.MapClaims
+
+	mu           sync.Mutex
+	codeVerifier string
+}
+
+// NewIdP starts a stub provider that is stopped when the test ends.
+func NewIdP(t *testing.T) *IdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &IdP{Key: key, Claims: jwt.MapClaims{}}
+	publicKey := key.PublicKey
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.Server.URL,
+			"authorization_endpoint": idp.Server.URL + "/authorize",
+			"token_endpoint":         idp.Server.URL + "/token",
+			"jwks_uri":               idp.Server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "stub-key",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+		if r.PostFormValue("code") != Code || clientID != ClientID || clientSecret != ClientSecret {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		idToken, err := idp.idToken()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		idp.mu.Lock()
+		idp.codeVerifier = r.PostFormValue("code_verifier")
+		idp.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "upstream-access", "token_type": "Bearer", "id_token": idToken})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Server.Close)
+	return idp
+}
+
+// CodeVerifier returns the PKCE code verifier received by the token endpoint.
+func (idp *IdP) CodeVerifier() string {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	return idp.codeVerifier
+}
+
+// Connector returns a connector configured for the stub provider.
+func (idp *IdP) Connector(name string) *connectors.OIDCConnector {
+	return connectors.NewOIDCConnector(config.SocialProvider{
+		Name:         name,
+		Issuer:       idp.Server.URL,
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		Scopes:       []string{"openid", "email"},
+	}, "http://localhost/api/auth/social/"+name+"/callback", nil)
+}
+
+// idToken signs an ID token with the default claims overridden by Claims
+func (idp *IdP) idToken() (string, error) {
+	claims := jwt.MapClaims{
+		"iss": idp.Server.URL,
+		"aud": ClientID,
+		"sub": "upstream-42",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range idp.Claims {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "stub-key"
+	return token.SignedString(idp.Key)
+}
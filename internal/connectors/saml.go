@@ -0,0 +1,244 @@
+package connectors
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"exercise-login-back-go/internal/config"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/crewjam/saml"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+const (
+	// samlKeyBits is the size of the ephemeral service provider key.
+	samlKeyBits = 2048
+	// samlBindingPost selects the HTTP-POST binding for authentication requests.
+	samlBindingPost = "post"
+)
+
+// SAMLConnector is the service provider side of a SAML 2.0 connection with
+// the identity provider of an enterprise tenant.
+type SAMLConnector struct {
+	cfg config.SAMLConnection
+	sp  *saml.ServiceProvider
+}
+
+// NewSAMLConnector creates a connector for the identity provider described by
+// the metadata file of the connection. The service provider endpoints are
+// published under baseURL/saml/<name>.
+func NewSAMLConnector(cfg config.SAMLConnection, baseURL string, key *rsa.PrivateKey, certificate *x509.Certificate) (*SAMLConnector, error) {
+	data, err := os.ReadFile(cfg.IDPMetadataFile)
+	if err != nil {
+		return nil, err
+	}
+	idpMetadata, err := parseIDPMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata of SAML connection %s: %w", cfg.Name, err)
+	}
+
+	endpoint := baseURL + "/saml/" + url.PathEscape(cfg.Name)
+	metadataURL, err := url.Parse(endpoint + "/metadata")
+	if err != nil {
+		return nil, err
+	}
+	acsURL, err := url.Parse(endpoint + "/acs")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SAMLConnector{
+		cfg: cfg,
+		sp: &saml.ServiceProvider{
+			EntityID:          metadataURL.String(),
+			Key:               key,
+			Certificate:       certificate,
+			MetadataURL:       *metadataURL,
+			AcsURL:            *acsURL,
+			IDPMetadata:       idpMetadata,
+			AuthnNameIDFormat: saml.UnspecifiedNameIDFormat,
+			SignatureMethod:   dsig.RSASHA256SignatureMethod,
+		},
+	}, nil
+}
+
+// LoadSAMLKeyPair reads the certificate and RSA key of the service provider.
+// Without files it generates an ephemeral self-signed pair, which identity
+// providers must trust again after every restart.
+func LoadSAMLKeyPair(certificateFile, keyFile string) (*rsa.PrivateKey, *x509.Certificate, error) {
+	if certificateFile == "" || keyFile == "" {
+		slog.Warn("No SAML service provider key configured, generating an ephemeral key")
+		return generateSAMLKeyPair()
+	}
+
+	pair, err := tls.LoadX509KeyPair(certificateFile, keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, ok := pair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("the SAML service provider key is not an RSA key")
+	}
+	certificate, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, certificate, nil
+}
+
+// Name returns the name of the connection.
+func (c *SAMLConnector) Name() string {
+	return c.cfg.Name
+}
+
+// Domains returns the email domains owned by the tenant.
+func (c *SAMLConnector) Domains() []string {
+	return c.cfg.Domains
+}
+
+// Metadata returns the service provider metadata for the identity provider.
+func (c *SAMLConnector) Metadata() ([]byte, error) {
+	data, err := xml.MarshalIndent(c.sp.Metadata(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// MakeAuthnRequest builds a signed authentication request with the binding
+// of the connection. The response is always expected through HTTP-POST.
+func (c *SAMLConnector) MakeAuthnRequest(relayState string) (*model.SAMLAuthnRequest, error) {
+	binding := saml.HTTPRedirectBinding
+	if c.cfg.Binding == samlBindingPost {
+		binding = saml.HTTPPostBinding
+	}
+	location := c.sp.GetSSOBindingLocation(binding)
+	if location == "" {
+		return nil, fmt.Errorf("the identity provider of %s has no single sign-on endpoint for %s", c.cfg.Name, binding)
+	}
+
+	req, err := c.sp.MakeAuthenticationRequest(location, binding, saml.HTTPPostBinding)
+	if err != nil {
+		return nil, err
+	}
+
+	if binding == saml.HTTPPostBinding {
+		return &model.SAMLAuthnRequest{ID: req.ID, PostForm: req.Post(relayState)}, nil
+	}
+	redirectURL, err := req.Redirect(relayState, c.sp)
+	if err != nil {
+		return nil, err
+	}
+	return &model.SAMLAuthnRequest{ID: req.ID, RedirectURL: redirectURL.String()}, nil
+}
+
+// ParseResponse validates a base64 encoded SAML response sent to the
+// assertion consumer service: the signature against the identity provider
+// certificates, the issuer, audience, recipient, validity window and the
+// request it answers. The assertion attributes are mapped onto an identity.
+func (c *SAMLConnector) ParseResponse(samlResponse, requestID string) (*model.ExternalIdentity, error) {
+	data, err := base64.StdEncoding.DecodeString(samlResponse)
+	if err != nil {
+		return nil, fmt.Errorf("the SAML response is not base64 encoded: %w", err)
+	}
+
+	assertion, err := c.sp.ParseXMLResponse(data, []string{requestID})
+	if err != nil {
+		var invalid *saml.InvalidResponseError
+		if errors.As(err, &invalid) {
+			return nil, fmt.Errorf("invalid SAML response: %w", invalid.PrivateErr)
+		}
+		return nil, err
+	}
+	if assertion.Subject == nil || assertion.Subject.NameID == nil || assertion.Subject.NameID.Value == "" {
+		return nil, errors.New("the SAML assertion has no subject")
+	}
+	nameID := assertion.Subject.NameID.Value
+
+	email := assertionAttribute(assertion, c.cfg.EmailAttribute)
+	if email == "" && strings.Contains(nameID, "@") {
+		email = nameID
+	}
+	return &model.ExternalIdentity{
+		Provider:          c.cfg.Name,
+		Subject:           nameID,
+		Email:             email,
+		EmailVerified:     email != "",
+		PreferredUsername: assertionAttribute(assertion, c.cfg.UsernameAttribute),
+		Phone:             assertionAttribute(assertion, c.cfg.PhoneAttribute),
+	}, nil
+}
+
+// assertionAttribute returns the first value of the attribute with the given
+// name or friendly name
+func assertionAttribute(assertion *saml.Assertion, name string) string {
+	if name == "" {
+		return ""
+	}
+	for _, statement := range assertion.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			if (attribute.Name == name || attribute.FriendlyName == name) && len(attribute.Values) > 0 {
+				return strings.TrimSpace(attribute.Values[0].Value)
+			}
+		}
+	}
+	return ""
+}
+
+// parseIDPMetadata reads an EntityDescriptor, or the first entity of an
+// EntitiesDescriptor, that describes an identity provider.
+func parseIDPMetadata(data []byte) (*saml.EntityDescriptor, error) {
+	var entity saml.EntityDescriptor
+	if err := xml.Unmarshal(data, &entity); err != nil {
+		var entities saml.EntitiesDescriptor
+		if xml.Unmarshal(data, &entities) != nil || len(entities.EntityDescriptors) == 0 {
+			return nil, err
+		}
+		entity = entities.EntityDescriptors[0]
+	}
+	if len(entity.IDPSSODescriptors) == 0 {
+		return nil, errors.New("no IDPSSODescriptor found")
+	}
+	return &entity, nil
+}
+
+// generateSAMLKeyPair creates an RSA key and a self-signed certificate
+func generateSAMLKeyPair() (*rsa.PrivateKey, *x509.Certificate, error) {
+	key, err := rsa.GenerateKey(rand.Reader, samlKeyBits)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "SAML service provider"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, certificate, nil
+}
@@ -0,0 +1,166 @@
+package connectors_test
+
+import (
+	"encoding/base64"
+	"exercise-login-back-go/internal/config"
+	"exercise-login-back-go/internal/connectors"
+	"exercise-login-back-go/internal/connectors/samltest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	samlBaseURL  = "https://login.example.com"
+	samlEntityID = samlBaseURL + "/saml/acme/metadata"
+	samlACSURL   = samlBaseURL + "/saml/acme/acs"
+)
+
+func newSAMLConnector(t *testing.T, idp *samltest.IdP, binding string) *connectors.SAMLConnector {
+	key, certificate, err := connectors.LoadSAMLKeyPair("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	connector, err := connectors.NewSAMLConnector(config.SAMLConnection{
+		Name:              "acme",
+		IDPMetadataFile:   idp.MetadataFile(),
+		Binding:           binding,
+		UsernameAttribute: "uid",
+		EmailAttribute:    "email",
+		PhoneAttribute:    "phone",
+	}, samlBaseURL, key, certificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return connector
+}
+
+func validAssertion(requestID string) samltest.Assertion {
+	return samltest.Assertion{
+		RequestID:   requestID,
+		Destination: samlACSURL,
+		Audience:    samlEntityID,
+		NameID:      "jane.doe",
+		Attributes:  map[string]string{"uid": "jane", "email": "jane@acme.example.com", "phone": "5512345678"},
+	}
+}
+
+func TestSAMLConnectorMetadata(t *testing.T) {
+	connector := newSAMLConnector(t, samltest.NewIdP(t), "redirect")
+
+	metadata, err := connector.Metadata()
+	assert.NoError(t, err)
+	assert.Contains(t, string(metadata), `entityID="`+samlEntityID+`"`)
+	assert.Contains(t, string(metadata), `Location="`+samlACSURL+`"`)
+	assert.Contains(t, string(metadata), "X509Certificate")
+}
+
+func TestSAMLConnectorAuthnRequest(t *testing.T) {
+	t.Run("Redirect Binding", func(t *testing.T) {
+		connector := newSAMLConnector(t, samltest.NewIdP(t), "redirect")
+
+		req, err := connector.MakeAuthnRequest("relay-state")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, req.ID)
+		assert.Empty(t, req.PostForm)
+
+		redirectURL, err := url.Parse(req.RedirectURL)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(req.RedirectURL, samltest.SSOURL+"?"))
+		assert.Equal(t, "relay-state", redirectURL.Query().Get("RelayState"))
+		assert.NotEmpty(t, redirectURL.Query().Get("SAMLRequest"))
+		assert.NotEmpty(t, redirectURL.Query().Get("Signature"))
+	})
+
+	t.Run("Post Binding", func(t *testing.T) {
+		connector := newSAMLConnector(t, samltest.NewIdP(t), "post")
+
+		req, err := connector.MakeAuthnRequest("relay-state")
+		assert.NoError(t, err)
+		assert.Empty(t, req.RedirectURL)
+		assert.Contains(t, string(req.PostForm), `action="`+samltest.SSOURL+`"`)
+		assert.Contains(t, string(req.PostForm), `name="SAMLRequest"`)
+		assert.Contains(t, string(req.PostForm), `value="relay-state"`)
+	})
+}
+
+func TestSAMLConnectorParseResponse(t *testing.T) {
+	idp := samltest.NewIdP(t)
+	connector := newSAMLConnector(t, idp, "redirect")
+
+	t.Run("Valid Assertion", func(t *testing.T) {
+		identity, err := connector.ParseResponse(idp.Response(validAssertion("id-request")), "id-request")
+		assert.NoError(t, err)
+		assert.Equal(t, "acme", identity.Provider)
+		assert.Equal(t, "jane.doe", identity.Subject)
+		assert.Equal(t, "jane", identity.PreferredUsername)
+		assert.Equal(t, "jane@acme.example.com", identity.Email)
+		assert.Equal(t, "5512345678", identity.Phone)
+	})
+
+	t.Run("Email From NameID", func(t *testing.T) {
+		assertion := validAssertion("id-request")
+		assertion.NameID = "jane@acme.example.com"
+		assertion.Attributes = nil
+
+		identity, err := connector.ParseResponse(idp.Response(assertion), "id-request")
+		assert.NoError(t, err)
+		assert.Equal(t, "jane@acme.example.com", identity.Email)
+	})
+
+	t.Run("Unexpected Request", func(t *testing.T) {
+		_, err := connector.ParseResponse(idp.Response(validAssertion("id-other")), "id-request")
+		assert.Error(t, err)
+	})
+
+	t.Run("Unsigned Assertion", func(t *testing.T) {
+		assertion := validAssertion("id-request")
+		assertion.Unsigned = true
+
+		_, err := connector.ParseResponse(idp.Response(assertion), "id-request")
+		assert.Error(t, err)
+	})
+
+	t.Run("Untrusted Key", func(t *testing.T) {
+		forger := samltest.NewIdP(t)
+
+		_, err := connector.ParseResponse(forger.Response(validAssertion("id-request")), "id-request")
+		assert.Error(t, err)
+	})
+
+	t.Run("Tampered Attribute", func(t *testing.T) {
+		data, err := base64.StdEncoding.DecodeString(idp.Response(validAssertion("id-request")))
+		assert.NoError(t, err)
+		tampered := strings.Replace(string(data), "jane@acme.example.com", "ceo@acme.example.com", 1)
+
+		_, err = connector.ParseResponse(base64.StdEncoding.EncodeToString([]byte(tampered)), "id-request")
+		assert.Error(t, err)
+	})
+
+	t.Run("Wrong Audience", func(t *testing.T) {
+		assertion := validAssertion("id-request")
+		assertion.Audience = "https://other.example.com/metadata"
+
+		_, err := connector.ParseResponse(idp.Response(assertion), "id-request")
+		assert.Error(t, err)
+	})
+
+	t.Run("Expired Assertion", func(t *testing.T) {
+		assertion := validAssertion("id-request")
+		assertion.IssueInstant = time.Now().UTC().Add(-time.Hour)
+
+		_, err := connector.ParseResponse(idp.Response(assertion), "id-request")
+		assert.Error(t, err)
+	})
+
+	t.Run("Wrong Issuer", func(t *testing.T) {
+		assertion := validAssertion("id-request")
+		assertion.Issuer = "https://other-idp.example.com/metadata"
+
+		_, err := connector.ParseResponse(idp.Response(assertion), "id-request")
+		assert.Error(t, err)
+	})
+}
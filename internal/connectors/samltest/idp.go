@@ -0,0 +1,182 @@
+// Package samltest provides a stub SAML 2.0 identity provider for tests.
+package samltest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+const (
+	// EntityID identifies the stub identity provider.
+	EntityID = "https://idp.example.com/metadata"
+	// SSOURL is the single sign-on endpoint published for both bindings.
+	SSOURL = "https://idp.example.com/sso"
+)
+
+// IdP holds a locally generated key pair and issues signed SAML responses.
+type IdP struct {
+	t *testing.T
+	// Key signs the assertions; replacing it simulates a forged assertion.
+	Key         *rsa.PrivateKey
+	Certificate *x509.Certificate
+}
+
+// Assertion describes the response the stub issues. IssueInstant defaults to
+// the current time.
+type Assertion struct {
+	RequestID    string
+	Destination  string
+	Audience     string
+	NameID       string
+	Attributes   map[string]string
+	IssueInstant time.Time
+	Issuer       string
+	Unsigned     bool
+}
+
+// NewIdP generates the key pair of a stub identity provider.
+func NewIdP(t *testing.T) *IdP {
+	t.Helper()
+	key, certificate := newKeyPair(t)
+	return &IdP{t: t, Key: key, Certificate: certificate}
+}
+
+// Metadata returns the identity provider metadata.
+func (idp *IdP) Metadata() []byte {
+	certificate := base64.StdEncoding.EncodeToString(idp.Certificate.Raw)
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="%[1]s">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data><ds:X509Certificate>%[2]s</ds:X509Certificate></ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="%[3]s"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="%[3]s"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`, EntityID, certificate, SSOURL))
+}
+
+// MetadataFile writes the metadata to a temporary file and returns its path.
+func (idp *IdP) MetadataFile() string {
+	idp.t.Helper()
+	path := filepath.Join(idp.t.TempDir(), "idp-metadata.xml")
+	if err := os.WriteFile(path, idp.Metadata(), 0o600); err != nil {
+		idp.t.Fatal(err)
+	}
+	return path
+}
+
+// Response returns the base64 encoded SAML response carrying the assertion,
+// signed with Key unless the assertion is marked as unsigned.
+func (idp *IdP) Response(a Assertion) string {
+	idp.t.Helper()
+	if a.IssueInstant.IsZero() {
+		a.IssueInstant = time.Now().UTC()
+	}
+	if a.Issuer == "" {
+		a.Issuer = EntityID
+	}
+
+	assertion := etree.NewDocument()
+	if err := assertion.ReadFromString(assertionXML(a)); err != nil {
+		idp.t.Fatal(err)
+	}
+	assertionEl := assertion.Root()
+	if !a.Unsigned {
+		ctx := dsig.NewDefaultSigningContext(dsig.TLSCertKeyStore(tls.Certificate{
+			Certificate: [][]byte{idp.Certificate.Raw},
+			PrivateKey:  idp.Key,
+		}))
+		ctx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+		signed, err := ctx.SignEnveloped(assertionEl)
+		if err != nil {
+			idp.t.Fatal(err)
+		}
+		assertionEl = signed
+	}
+
+	response := etree.NewDocument()
+	if err := response.ReadFromString(responseXML(a)); err != nil {
+		idp.t.Fatal(err)
+	}
+	response.Root().AddChild(assertionEl)
+	data, err := response.WriteToBytes()
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// responseXML is the envelope of the assertion
+func responseXML(a Assertion) string {
+	return fmt.Sprintf(`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="id-response-%d" Version="2.0" IssueInstant="%s" Destination="%s" InResponseTo="%s">
+  <saml:Issuer>%s</saml:Issuer>
+  <samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>
+</samlp:Response>`, a.IssueInstant.UnixNano(), a.IssueInstant.Format(time.RFC3339), a.Destination, a.RequestID, a.Issuer)
+}
+
+// assertionXML is the unsigned assertion about the subject
+func assertionXML(a Assertion) string {
+	attributes := ""
+	for name, value := range a.Attributes {
+		attributes += fmt.Sprintf(`<saml:Attribute Name="%s"><saml:AttributeValue>%s</saml:AttributeValue></saml:Attribute>`, name, value)
+	}
+	notOnOrAfter := a.IssueInstant.Add(5 * time.Minute).Format(time.RFC3339)
+	return fmt.Sprintf(`<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="id-assertion-%d" Version="2.0" IssueInstant="%s">
+  <saml:Issuer>%s</saml:Issuer>
+  <saml:Subject>
+    <saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified">%s</saml:NameID>
+    <saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">
+      <saml:SubjectConfirmationData InResponseTo="%s" NotOnOrAfter="%s" Recipient="%s"/>
+    </saml:SubjectConfirmation>
+  </saml:Subject>
+  <saml:Conditions NotBefore="%s" NotOnOrAfter="%s">
+    <saml:AudienceRestriction><saml:Audience>%s</saml:Audience></saml:AudienceRestriction>
+  </saml:Conditions>
+  <saml:AuthnStatement AuthnInstant="%[2]s">
+    <saml:AuthnContext><saml:AuthnContextClassRef>urn:oasis:names:tc:SAML:2.0:ac:classes:PasswordProtectedTransport</saml:AuthnContextClassRef></saml:AuthnContext>
+  </saml:AuthnStatement>
+  <saml:AttributeStatement>%[11]s</saml:AttributeStatement>
+</saml:Assertion>`, a.IssueInstant.UnixNano(), a.IssueInstant.Format(time.RFC3339), a.Issuer, a.NameID,
+		a.RequestID, notOnOrAfter, a.Destination, a.IssueInstant.Format(time.RFC3339), notOnOrAfter, a.Audience, attributes)
+}
+
+// newKeyPair generates an RSA key and a self-signed certificate
+func newKeyPair(t *testing.T) (*rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, certificate
+}
@@ -0,0 +1,147 @@
+// Package i18n holds the catalog of the messages shown to users, one bundle
+// per supported language. Messages are identified by stable keys that
+// clients can use to localize themselves; a key is an error code optionally
+// followed by a variant, such as "invalid_password.too_short".
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a supported language, identified by its ISO 639-1 code.
+type Locale string
+
+const (
+	Spanish Locale = "es"
+	English Locale = "en"
+)
+
+// DefaultLocale is used when the user has no preference and the request
+// accepts none of the supported languages.
+const DefaultLocale = Spanish
+
+// Params are the values of the {name} placeholders of a message.
+type Params map[string]string
+
+//go:embed locales/*.json
+var files embed.FS
+
+// bundles maps each supported locale to its messages by key
+var bundles = loadBundles()
+
+func loadBundles() map[Locale]map[string]string {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	bundles := make(map[Locale]map[string]string, len(entries))
+	for _, entry := range entries {
+		content, err := files.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(content, &messages); err != nil {
+			panic(fmt.Sprintf("invalid message bundle %s: %v", entry.Name(), err))
+		}
+		bundles[Locale(strings.TrimSuffix(entry.Name(), ".json"))] = messages
+	}
+	return bundles
+}
+
+// Locales returns the supported locales in alphabetical order.
+func Locales() []Locale {
+	locales := make([]Locale, 0, len(bundles))
+	for locale := range bundles {
+		locales = append(locales, locale)
+	}
+	sort.Slice(locales, func(i, j int) bool { return locales[i] < locales[j] })
+	return locales
+}
+
+// Keys returns the keys of the messages of a locale.
+func Keys(locale Locale) []string {
+	keys := make([]string, 0, len(bundles[locale]))
+	for key := range bundles[locale] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Message returns the message of key in the given locale, falling back to
+// the default locale and then to the key itself, with its placeholders
+// replaced by params.
+func Message(locale Locale, key string, params Params) string {
+	message, ok := bundles[locale][key]
+	if !ok {
+		message, ok = bundles[DefaultLocale][key]
+	}
+	if !ok {
+		return key
+	}
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+	return message
+}
+
+// ParseLocale returns the supported locale of a language tag such as
+// "en-US", ignoring its region.
+func ParseLocale(tag string) (Locale, bool) {
+	language, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	locale := Locale(strings.ToLower(language))
+	if _, ok := bundles[locale]; !ok {
+		return "", false
+	}
+	return locale, true
+}
+
+// MatchAcceptLanguage returns the supported locale the client prefers
+// according to an Accept-Language header, or the default locale.
+func MatchAcceptLanguage(header string) Locale {
+	best, bestQuality := DefaultLocale, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, options, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(options), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		// The first language wins between languages of the same quality.
+		if quality <= bestQuality {
+			continue
+		}
+		if strings.TrimSpace(tag) == "*" {
+			best, bestQuality = DefaultLocale, quality
+		} else if locale, ok := ParseLocale(tag); ok {
+			best, bestQuality = locale, quality
+		}
+	}
+	return best
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the locale of the user.
+func NewContext(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale stored in ctx, or the default locale.
+func FromContext(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(contextKey{}).(Locale); ok {
+		return locale
+	}
+	return DefaultLocale
+}
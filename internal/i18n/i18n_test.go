@@ -0,0 +1,49 @@
+package i18n_test
+
+import (
+	"context"
+	"exercise-login-back-go/internal/i18n"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundlesHaveTheSameKeys(t *testing.T) {
+	assert.Equal(t, []i18n.Locale{i18n.English, i18n.Spanish}, i18n.Locales())
+	assert.Equal(t, i18n.Keys(i18n.DefaultLocale), i18n.Keys(i18n.English))
+}
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, "usuario / contraseña incorrectos", i18n.Message(i18n.Spanish, "invalid_credentials", nil))
+	assert.Equal(t, "incorrect username / password", i18n.Message(i18n.English, "invalid_credentials", nil))
+	assert.Equal(t, `the permission "admin" is not valid`, i18n.Message(i18n.English, "invalid_scope", i18n.Params{"scope": "admin"}))
+	// Unknown locales fall back to the default locale, unknown keys to the key.
+	assert.Equal(t, "usuario / contraseña incorrectos", i18n.Message("fr", "invalid_credentials", nil))
+	assert.Equal(t, "no_such_key", i18n.Message(i18n.English, "no_such_key", nil))
+}
+
+func TestMatchAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   i18n.Locale
+	}{
+		{"", i18n.Spanish},
+		{"en", i18n.English},
+		{"en-US,en;q=0.9", i18n.English},
+		{"fr-FR, en;q=0.8, es;q=0.9", i18n.Spanish},
+		{"fr, de", i18n.Spanish},
+		{"ES-mx", i18n.Spanish},
+		{"en;q=0, es;q=0.5", i18n.Spanish},
+		{"*;q=0.5, en;q=0.7", i18n.English},
+		{"en;q=bad", i18n.Spanish},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, i18n.MatchAcceptLanguage(tt.header), tt.header)
+	}
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, i18n.DefaultLocale, i18n.FromContext(context.Background()))
+	ctx := i18n.NewContext(context.Background(), i18n.English)
+	assert.Equal(t, i18n.English, i18n.FromContext(ctx))
+}
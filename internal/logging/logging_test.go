@@ -0,0 +1,100 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"exercise-login-back-go/internal/logging"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLogger(t *testing.T) {
+	t.Run("writes JSON records with the request identifier", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logging.New(&buf, slog.LevelInfo)
+
+		ctx := logging.WithRequestID(context.Background(), "req-1")
+		logger.InfoContext(ctx, "Request completed", "status", 200)
+
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "INFO", record["level"])
+		assert.Equal(t, "Request completed", record["msg"])
+		assert.Equal(t, float64(200), record["status"])
+		assert.Equal(t, "req-1", record["requestId"])
+	})
+
+	t.Run("omits the request identifier outside requests", func(t *testing.T) {
+		var buf bytes.Buffer
+		logging.New(&buf, slog.LevelInfo).Info("Web server started")
+
+		assert.NotContains(t, buf.String(), "requestId")
+	})
+
+	t.Run("filters records below the level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logging.New(&buf, slog.LevelWarn)
+
+		logger.Info("ignored")
+		logger.Warn("kept")
+
+		assert.NotContains(t, buf.String(), "ignored")
+		assert.Contains(t, buf.String(), "kept")
+	})
+
+	t.Run("redacts sensitive attributes", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logging.New(&buf, slog.LevelInfo).With("clientSecret", "s3cr3t")
+
+		logger.Info("Login",
+			"username", "jdoe",
+			"password", "Password@23",
+			slog.Group("request", "Authorization", "Bearer abc", "refresh_token", "xyz"))
+
+		assert.NotContains(t, buf.String(), "s3cr3t")
+		assert.NotContains(t, buf.String(), "Password@23")
+		assert.NotContains(t, buf.String(), "Bearer abc")
+		assert.NotContains(t, buf.String(), "xyz")
+		assert.Contains(t, buf.String(), `"username":"jdoe"`)
+		assert.Contains(t, buf.String(), `"password":"[REDACTED]"`)
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := logging.ParseLevel("debug")
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	level, err = logging.ParseLevel("WARN")
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = logging.ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestIsSensitive(t *testing.T) {
+	for _, key := range []string{"password", "newPassword", "accessToken", "client_secret", "Authorization", "apiKey", "Cookie"} {
+		assert.True(t, logging.IsSensitive(key), key)
+	}
+	for _, key := range []string{"username", "email", "userId", "prefix", "messageKey"} {
+		assert.False(t, logging.IsSensitive(key), key)
+	}
+}
+
+func TestLoggerTrace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, slog.LevelInfo)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	logger.InfoContext(ctx, "Request completed")
+
+	assert.Contains(t, buf.String(), `"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+	assert.Contains(t, buf.String(), `"spanId":"00f067aa0ba902b7"`)
+}
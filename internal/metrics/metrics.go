@@ -0,0 +1,87 @@
+// Package metrics exposes the Prometheus metrics of the server: HTTP traffic,
+// authentication outcomes, password hashing cost and database pool usage.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Outcomes of the authentication counters
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Operations of the password hashing histogram
+const (
+	OperationHash    = "hash"
+	OperationCompare = "compare"
+)
+
+// Registry holds the metrics served by Handler
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts the requests by route template, method and status.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequestDuration measures the time taken to answer each route.
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to answer HTTP requests by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// Logins counts the login attempts by result and, for failures, the
+	// error code that explains them.
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_logins_total",
+		Help: "Login attempts by result and failure reason.",
+	}, []string{"result", "reason"})
+
+	// Registrations counts the self-service registrations by result and
+	// failure reason.
+	Registrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_registrations_total",
+		Help: "User registrations by result and failure reason.",
+	}, []string{"result", "reason"})
+
+	// PasswordHashDuration measures bcrypt, which dominates the cost of
+	// registrations and password logins.
+	PasswordHashDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "auth_password_hash_duration_seconds",
+		Help:    "Time taken by bcrypt to hash or compare a password.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 10),
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		Logins,
+		Registrations,
+		PasswordHashDuration,
+	)
+}
+
+// RegisterDB exposes the connection pool statistics of a database, such as
+// open, in use and idle connections and the time spent waiting for one.
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,30 @@
+package metrics_test
+
+import (
+	"exercise-login-back-go/internal/metrics"
+	"exercise-login-back-go/pkg/db"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	database, err := db.InitializeDatabase("sqlite", ":memory:")
+	assert.NoError(t, err)
+	defer database.Close()
+	assert.NoError(t, metrics.RegisterDB(database, "sqlite"))
+
+	metrics.HTTPRequests.WithLabelValues("/api/users/login", "POST", "200").Inc()
+	metrics.Logins.WithLabelValues(metrics.ResultFailure, "invalid_credentials").Inc()
+
+	resp := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(resp, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := resp.Body.String()
+	assert.Equal(t, 200, resp.Code)
+	assert.Contains(t, body, `http_requests_total{method="POST",route="/api/users/login",status="200"} 1`)
+	assert.Contains(t, body, `auth_logins_total{reason="invalid_credentials",result="failure"} 1`)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="sqlite"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
@@ -0,0 +1,147 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// lockName identifies the migration lock in SQL Server and, hashed, in PostgreSQL
+const lockName = "schema_migrations"
+
+// batchSeparator matches the GO lines that split SQL Server scripts into
+// batches, since CREATE PROCEDURE must be the only statement of its batch
+var batchSeparator = regexp.MustCompile(`(?im)^\s*GO\s*$`)
+
+// dialect holds the SQL that differs between database engines
+type dialect struct {
+	name string
+	// createTable creates the table recording the applied migrations.
+	createTable string
+	// placeholder returns the bind parameter for the nth argument.
+	placeholder func(n int) string
+	// tryLock takes the migration lock without waiting and reports whether it
+	// was acquired. unlock releases it.
+	tryLock func(ctx context.Context, conn *sql.Conn) (bool, error)
+	unlock  func(ctx context.Context, conn *sql.Conn) error
+	// batches splits a script into the statements sent to the server.
+	batches func(script string) []string
+}
+
+// dialectForDriver returns the dialect of a database/sql driver name
+func dialectForDriver(driver string) (*dialect, error) {
+	switch driver {
+	case "sqlserver", "mssql":
+		return sqlServerDialect, nil
+	case "postgres":
+		return postgresDialect, nil
+	case "sqlite":
+		return sqliteDialect, nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
+
+var sqlServerDialect = &dialect{
+	name: "sqlserver",
+	createTable: `IF OBJECT_ID('schema_migrations', 'U') IS NULL
+CREATE TABLE schema_migrations (
+    version INT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    applied_at DATETIME2 NOT NULL
+)`,
+	placeholder: func(n int) string { return fmt.Sprintf("@p%d", n) },
+	tryLock: func(ctx context.Context, conn *sql.Conn) (bool, error) {
+		// sp_getapplock returns 0 or 1 when the lock is granted and -1 on timeout.
+		var result int
+		query := "DECLARE @result INT; " +
+			"EXEC @result = sp_getapplock @Resource = '" + lockName + "', @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = 0; " +
+			"SELECT @result"
+		if err := conn.QueryRowContext(ctx, query).Scan(&result); err != nil {
+			return false, err
+		}
+		return result >= 0, nil
+	},
+	unlock: func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, "EXEC sp_releaseapplock @Resource = '"+lockName+"', @LockOwner = 'Session'")
+		return err
+	},
+	batches: func(script string) []string {
+		var batches []string
+		for _, batch := range batchSeparator.Split(script, -1) {
+			if strings.TrimSpace(batch) != "" {
+				batches = append(batches, batch)
+			}
+		}
+		return batches
+	},
+}
+
+var postgresDialect = &dialect{
+	name: "postgres",
+	createTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL
+)`,
+	placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+	tryLock: func(ctx context.Context, conn *sql.Conn) (bool, error) {
+		var acquired bool
+		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext('"+lockName+"'))").Scan(&acquired)
+		return acquired, err
+	},
+	unlock: func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext('"+lockName+"'))")
+		return err
+	},
+	batches: singleBatch,
+}
+
+// sqliteStaleLockAge is how old a SQLite lock row must be to be considered
+// left behind by a runner that crashed, and so taken over.
+const sqliteStaleLockAge = 15 * time.Minute
+
+// sqliteDialect has no session locks, so the lock is a row that only one
+// runner can insert. Unlike session locks it survives the process that took
+// it, so rows older than sqliteStaleLockAge are reclaimed.
+var sqliteDialect = &dialect{
+	name: "sqlite",
+	createTable: `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    locked_at TIMESTAMP NOT NULL
+)`,
+	placeholder: func(n int) string { return "?" },
+	tryLock: func(ctx context.Context, conn *sql.Conn) (bool, error) {
+		stale := fmt.Sprintf("-%d seconds", int(sqliteStaleLockAge.Seconds()))
+		if _, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations_lock WHERE id = 1 AND locked_at < datetime('now', ?)", stale); err != nil {
+			return false, err
+		}
+		result, err := conn.ExecContext(ctx, "INSERT OR IGNORE INTO schema_migrations_lock (id, locked_at) VALUES (1, CURRENT_TIMESTAMP)")
+		if err != nil {
+			return false, err
+		}
+		inserted, err := result.RowsAffected()
+		return inserted == 1, err
+	},
+	unlock: func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations_lock WHERE id = 1")
+		return err
+	},
+	batches: singleBatch,
+}
+
+// singleBatch sends the whole script at once, for drivers that accept
+// several statements per call
+func singleBatch(script string) []string {
+	return []string{script}
+}
@@ -0,0 +1,267 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrLocked is returned when another runner holds the migration lock
+	// for longer than the lock timeout.
+	ErrLocked = errors.New("migrations are locked by another runner")
+	// ErrChecksumMismatch is returned when an applied migration was edited
+	// after it ran.
+	ErrChecksumMismatch = errors.New("applied migration does not match its script")
+	// ErrUnknownMigration is returned when the database has a migration this
+	// binary does not know, usually because it was migrated by a newer version.
+	ErrUnknownMigration = errors.New("applied migration is unknown")
+)
+
+// lockRetryInterval is how often a busy lock is retried
+const lockRetryInterval = 200 * time.Millisecond
+
+// Status describes a migration and whether it has been applied.
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// appliedMigration is a row of the schema_migrations table
+type appliedMigration struct {
+	version   int
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Migrator applies and reverts the migrations of a database. Only one
+// migrator runs at a time across processes: the others wait for the lock.
+type Migrator struct {
+	db         *sql.DB
+	dialect    *dialect
+	migrations []Migration
+	// LockTimeout is how long to wait for another runner to finish.
+	LockTimeout time.Duration
+}
+
+// New creates a migrator for a database opened with the given driver.
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	d, err := dialectForDriver(driver)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(d.name)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: d, migrations: migrations, LockTimeout: time.Minute}, nil
+}
+
+// Up applies the pending migrations in order and returns them. Each
+// migration runs in its own transaction together with its record.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, done map[int]appliedMigration) error {
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			insert := fmt.Sprintf("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (%s, %s, %s, %s)",
+				m.dialect.placeholder(1), m.dialect.placeholder(2), m.dialect.placeholder(3), m.dialect.placeholder(4))
+			err := m.inTransaction(ctx, conn, migration.Up, insert,
+				migration.Version, migration.Name, migration.Checksum, time.Now().UTC())
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn, done map[int]appliedMigration) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			remove := "DELETE FROM schema_migrations WHERE version = " + m.dialect.placeholder(1)
+			if err := m.inTransaction(ctx, conn, migration.Down, remove, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Baseline records the migrations up to version as applied without running
+// them, for databases created by hand before migrations existed.
+func (m *Migrator) Baseline(ctx context.Context, version int) error {
+	return m.withLock(ctx, func(conn *sql.Conn, done map[int]appliedMigration) error {
+		if len(done) > 0 {
+			return errors.New("the database already has applied migrations")
+		}
+		insert := fmt.Sprintf("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (%s, %s, %s, %s)",
+			m.dialect.placeholder(1), m.dialect.placeholder(2), m.dialect.placeholder(3), m.dialect.placeholder(4))
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, err := conn.ExecContext(ctx, insert, migration.Version, migration.Name, migration.Checksum, time.Now().UTC()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists the known migrations and whether each one has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, m.dialect.createTable); err != nil {
+		return nil, err
+	}
+	done, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := done[migration.Version]; ok {
+			appliedAt := row.appliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// withLock runs fn holding the migration lock, after verifying that the
+// applied migrations match the embedded scripts
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, done map[int]appliedMigration) error) (err error) {
+	// Session locks belong to a connection, so everything runs on the same one.
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, m.dialect.createTable); err != nil {
+		return err
+	}
+	if err := m.lock(ctx, conn); err != nil {
+		return err
+	}
+	defer func() {
+		// The lock is released even when the context was cancelled.
+		if unlockErr := m.dialect.unlock(context.Background(), conn); unlockErr != nil && err == nil {
+			err = unlockErr
+		}
+	}()
+
+	done, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(done); err != nil {
+		return err
+	}
+	return fn(conn, done)
+}
+
+// lock waits for the migration lock until LockTimeout
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) error {
+	deadline := time.Now().Add(m.LockTimeout)
+	for {
+		acquired, err := m.dialect.tryLock(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if acquired {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLocked
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// applied reads the schema_migrations table
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int]appliedMigration)
+	for rows.Next() {
+		var row appliedMigration
+		if err := rows.Scan(&row.version, &row.name, &row.checksum, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		done[row.version] = row
+	}
+	return done, rows.Err()
+}
+
+// verify checks that every applied migration is known and unchanged
+func (m *Migrator) verify(done map[int]appliedMigration) error {
+	known := make(map[int]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+	for version, row := range done {
+		migration, ok := known[version]
+		if !ok {
+			return fmt.Errorf("%w: %d_%s", ErrUnknownMigration, version, row.name)
+		}
+		if migration.Checksum != row.checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// inTransaction runs a script and the statement that records it atomically
+func (m *Migrator) inTransaction(ctx context.Context, conn *sql.Conn, script, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, batch := range m.dialect.batches(script) {
+		if _, err := tx.ExecContext(ctx, batch); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
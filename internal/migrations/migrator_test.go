@@ -0,0 +1,196 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/migrations"
+	"exercise-login-back-go/pkg/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newMigrator opens a fresh in-memory SQLite database
+func newMigrator(t *testing.T) (*migrations.Migrator, *sql.DB) {
+	t.Helper()
+	database, err := db.InitializeDatabase("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	migrator, err := migrations.New(database, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator, database
+}
+
+// latestVersion returns the number of sqlite migrations
+func latestVersion(t *testing.T) int {
+	t.Helper()
+	loaded, err := migrations.Load("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(loaded)
+}
+
+func TestLoad(t *testing.T) {
+	for _, dialect := range []string{"sqlserver", "postgres", "sqlite"} {
+		loaded, err := migrations.Load(dialect)
+		assert.NoError(t, err, dialect)
+		assert.NotEmpty(t, loaded, dialect)
+		for i, migration := range loaded {
+			assert.Equal(t, i+1, migration.Version, dialect)
+			assert.NotEmpty(t, migration.Down, dialect)
+			assert.Len(t, migration.Checksum, 64, dialect)
+		}
+	}
+
+	_, err := migrations.Load("oracle")
+	assert.Error(t, err)
+}
+
+func TestUpAndDown(t *testing.T) {
+	migrator, database := newMigrator(t)
+	ctx := context.Background()
+
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, latestVersion(t))
+	_, err = database.Exec("INSERT INTO users (username, email, password) VALUES ('jdoe', 'jdoe@example.com', 'hash')")
+	assert.NoError(t, err)
+
+	applied, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "create_users", statuses[0].Name)
+	assert.True(t, statuses[0].Applied)
+	assert.WithinDuration(t, time.Now(), *statuses[0].AppliedAt, time.Minute)
+
+	reverted, err := migrator.Down(ctx, latestVersion(t)+3)
+	assert.NoError(t, err)
+	assert.Len(t, reverted, latestVersion(t))
+	_, err = database.Exec("SELECT 1 FROM users")
+	assert.Error(t, err)
+
+	statuses, err = migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.False(t, statuses[0].Applied)
+	assert.Nil(t, statuses[0].AppliedAt)
+}
+
+func TestFailedMigrationIsNotRecorded(t *testing.T) {
+	migrator, database := newMigrator(t)
+	ctx := context.Background()
+
+	// The table already exists, so the first migration fails.
+	_, err := database.Exec("CREATE TABLE users (id INTEGER)")
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	assert.Error(t, err)
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.False(t, statuses[0].Applied)
+}
+
+func TestChecksumVerification(t *testing.T) {
+	migrator, database := newMigrator(t)
+	ctx := context.Background()
+
+	_, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	_, err = database.Exec("UPDATE schema_migrations SET checksum = 'edited' WHERE version = 1")
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	assert.ErrorIs(t, err, migrations.ErrChecksumMismatch)
+
+	_, err = database.Exec("UPDATE schema_migrations SET version = 99 WHERE version = 1")
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	assert.ErrorIs(t, err, migrations.ErrUnknownMigration)
+}
+
+func TestLockedByAnotherRunner(t *testing.T) {
+	migrator, database := newMigrator(t)
+	ctx := context.Background()
+
+	_, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	_, err = database.Exec("INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, CURRENT_TIMESTAMP)")
+	assert.NoError(t, err)
+
+	migrator.LockTimeout = 300 * time.Millisecond
+	_, err = migrator.Up(ctx)
+	assert.ErrorIs(t, err, migrations.ErrLocked)
+
+	_, err = database.Exec("DELETE FROM schema_migrations_lock")
+	assert.NoError(t, err)
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, latestVersion(t))
+}
+
+func TestStaleLockIsReclaimed(t *testing.T) {
+	migrator, database := newMigrator(t)
+	ctx := context.Background()
+
+	// A runner that crashed an hour ago never released its lock.
+	_, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	_, err = database.Exec("INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, datetime('now', '-1 hour'))")
+	assert.NoError(t, err)
+
+	migrator.LockTimeout = 300 * time.Millisecond
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, latestVersion(t))
+}
+
+func TestSQLiteSchemaHasEveryTable(t *testing.T) {
+	migrator, database := newMigrator(t)
+	_, err := migrator.Up(context.Background())
+	assert.NoError(t, err)
+
+	rows, err := database.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name string
+		assert.NoError(t, rows.Scan(&name))
+		tables = append(tables, name)
+	}
+	assert.Equal(t, []string{
+		"api_keys", "audit_events", "oauth_authorization_codes", "oauth_clients", "oauth_tokens",
+		"revoke_links", "schema_migrations", "schema_migrations_lock", "scim_clients", "sessions",
+		"social_login_states", "user_identities", "users",
+	}, tables)
+}
+
+func TestBaseline(t *testing.T) {
+	migrator, database := newMigrator(t)
+	ctx := context.Background()
+
+	// A database created by hand before migrations existed.
+	_, err := database.Exec("CREATE TABLE users (id INTEGER)")
+	assert.NoError(t, err)
+
+	assert.NoError(t, migrator.Baseline(ctx, latestVersion(t)))
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	assert.Error(t, migrator.Baseline(ctx, latestVersion(t)))
+}
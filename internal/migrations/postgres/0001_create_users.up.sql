@@ -0,0 +1,12 @@
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(10) NULL,
+    password VARCHAR(255) NOT NULL,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT uc_users_email UNIQUE (email)
+);
+
+-- Users created through an upstream provider or an LDAP directory may have no phone.
+CREATE UNIQUE INDEX ux_users_phone ON users (phone) WHERE phone IS NOT NULL;
@@ -0,0 +1,14 @@
+-- Events outlive the users they refer to, so user_id has no foreign key.
+CREATE TABLE audit_events (
+    id SERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    user_id INTEGER NULL,
+    actor VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    user_agent VARCHAR(512) NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    detail VARCHAR(1000) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX ix_audit_events_user_id_created_at ON audit_events (user_id, created_at);
@@ -0,0 +1,43 @@
+CREATE TABLE oauth_clients (
+    id SERIAL PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL,
+    secret_hash CHAR(64) NULL,
+    name VARCHAR(100) NOT NULL,
+    redirect_uris VARCHAR(2000) NOT NULL,
+    post_logout_redirect_uris VARCHAR(2000) NOT NULL,
+    grant_types VARCHAR(200) NOT NULL,
+    scopes VARCHAR(500) NOT NULL,
+    is_public BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT uc_oauth_clients_client_id UNIQUE (client_id)
+);
+
+CREATE TABLE oauth_authorization_codes (
+    code_hash CHAR(64) PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients (client_id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    redirect_uri VARCHAR(500) NOT NULL,
+    scopes VARCHAR(500) NOT NULL,
+    code_challenge VARCHAR(128) NOT NULL,
+    code_challenge_method VARCHAR(10) NOT NULL,
+    nonce VARCHAR(500) NOT NULL,
+    session_id VARCHAR(64) NOT NULL,
+    auth_time TIMESTAMPTZ NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL
+);
+
+CREATE TABLE oauth_tokens (
+    token_hash CHAR(64) PRIMARY KEY,
+    token_type VARCHAR(20) NOT NULL,
+    grant_id VARCHAR(64) NOT NULL,
+    client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients (client_id) ON DELETE CASCADE,
+    user_id INTEGER NULL REFERENCES users (id) ON DELETE CASCADE,
+    scopes VARCHAR(500) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX ix_oauth_tokens_grant_id ON oauth_tokens (grant_id);
@@ -0,0 +1,7 @@
+CREATE TABLE scim_clients (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT uc_scim_clients_token_hash UNIQUE (token_hash)
+);
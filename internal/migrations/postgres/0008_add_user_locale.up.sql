@@ -0,0 +1,2 @@
+-- Language preferred by the user; NULL follows the Accept-Language header.
+ALTER TABLE users ADD COLUMN locale VARCHAR(10) NULL;
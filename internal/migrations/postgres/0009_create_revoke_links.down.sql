@@ -0,0 +1 @@
+DROP TABLE revoke_links;
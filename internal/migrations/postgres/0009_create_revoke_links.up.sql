@@ -0,0 +1,8 @@
+-- "This wasn't me" links of the login alerts. Each link can only be used once.
+CREATE TABLE revoke_links (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ NULL
+);
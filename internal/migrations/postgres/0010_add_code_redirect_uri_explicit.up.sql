@@ -0,0 +1,3 @@
+-- Whether the authorization request included redirect_uri, in which case the
+-- token request must repeat it (RFC 6749, section 4.1.3).
+ALTER TABLE oauth_authorization_codes ADD COLUMN redirect_uri_explicit BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- Provisioning client that created the user; only that client may manage it.
+-- Deleting the client leaves its users unmanaged.
+ALTER TABLE users ADD COLUMN scim_client_id INTEGER NULL
+    CONSTRAINT fk_users_scim_clients REFERENCES scim_clients (id) ON DELETE SET NULL;
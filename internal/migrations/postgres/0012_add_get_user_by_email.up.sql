@@ -0,0 +1,2 @@
+-- SQL Server adds the GetUserByEmail procedure in this version; this dialect
+-- queries the users table directly, so its schema does not change.
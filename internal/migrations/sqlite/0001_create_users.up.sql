@@ -0,0 +1,11 @@
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL,
+    email TEXT NOT NULL UNIQUE,
+    phone TEXT NULL,
+    password TEXT NOT NULL,
+    disabled BOOLEAN NOT NULL DEFAULT 0
+);
+
+-- Users created through an upstream provider or an LDAP directory may have no phone.
+CREATE UNIQUE INDEX ux_users_phone ON users (phone) WHERE phone IS NOT NULL;
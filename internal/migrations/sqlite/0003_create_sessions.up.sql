@@ -0,0 +1,15 @@
+-- SQLite does not enforce foreign keys by default, so the rows that belong to
+-- a user are removed by the repository when the user is deleted.
+CREATE TABLE sessions (
+    id TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    device TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL
+);
+
+CREATE INDEX ix_sessions_user_id_created_at ON sessions (user_id, created_at);
@@ -0,0 +1 @@
+DROP TABLE scim_clients;
@@ -0,0 +1,8 @@
+-- "This wasn't me" links of the login alerts. Each link can only be used once.
+CREATE TABLE revoke_links (
+    id TEXT PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE users DROP COLUMN scim_client_id;
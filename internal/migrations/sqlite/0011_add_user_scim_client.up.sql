@@ -0,0 +1,4 @@
+-- Provisioning client that created the user; only that client may manage it.
+-- SQLite does not enforce foreign keys by default, so the repository clears
+-- the column when the client is deleted.
+ALTER TABLE users ADD COLUMN scim_client_id INTEGER NULL;
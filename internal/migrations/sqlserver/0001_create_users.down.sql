@@ -0,0 +1,5 @@
+DROP PROCEDURE CreateUser;
+DROP PROCEDURE GetUserByEmailOrPhone;
+DROP PROCEDURE GetUserByID;
+DROP PROCEDURE GetUserByEmailOrUsername;
+DROP TABLE users;
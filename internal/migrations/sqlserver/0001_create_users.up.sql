@@ -0,0 +1,53 @@
+CREATE TABLE users (
+    id INT IDENTITY(1,1) PRIMARY KEY,
+    username VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(10) NULL,
+    password VARCHAR(255) NOT NULL,
+    disabled BIT NOT NULL DEFAULT 0,
+    CONSTRAINT UC_users_email UNIQUE (email)
+);
+
+-- Users created through an upstream provider or an LDAP directory may have no phone.
+CREATE UNIQUE INDEX UX_users_phone ON users (phone) WHERE phone IS NOT NULL;
+GO
+
+CREATE PROCEDURE GetUserByEmailOrUsername
+    @EmailOrUsername VARCHAR(255)
+AS
+BEGIN
+    SELECT * FROM Users
+    WHERE Email = @EmailOrUsername OR Username = @EmailOrUsername
+END
+GO
+
+CREATE PROCEDURE GetUserByID
+    @ID INT
+AS
+BEGIN
+    SELECT * FROM users
+    WHERE id = @ID
+END
+GO
+
+CREATE PROCEDURE GetUserByEmailOrPhone
+    @Email VARCHAR(255),
+    @Phone VARCHAR(10)
+AS
+BEGIN
+    SELECT * FROM users
+    WHERE email = @Email OR phone = @Phone
+END
+GO
+
+CREATE PROCEDURE CreateUser
+    @Username VARCHAR(255),
+    @Email VARCHAR(255),
+    @Phone VARCHAR(10),
+    @Password VARCHAR(255),
+    @Disabled BIT = 0
+AS
+BEGIN
+    INSERT INTO users (username, email, phone, password, disabled)
+    VALUES (@Username, @Email, @Phone, @Password, @Disabled)
+END
@@ -0,0 +1,3 @@
+DROP PROCEDURE GetAuditEvents;
+DROP PROCEDURE CreateAuditEvent;
+DROP TABLE audit_events;
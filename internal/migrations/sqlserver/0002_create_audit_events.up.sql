@@ -0,0 +1,45 @@
+CREATE TABLE audit_events (
+    id INT IDENTITY(1,1) PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    user_id INT NULL,
+    actor VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    user_agent VARCHAR(512) NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    detail VARCHAR(1000) NOT NULL,
+    created_at DATETIME2 NOT NULL
+);
+
+CREATE INDEX IX_audit_events_user_id_created_at ON audit_events (user_id, created_at);
+GO
+
+CREATE PROCEDURE CreateAuditEvent
+    @EventType VARCHAR(50),
+    @UserID INT,
+    @Actor VARCHAR(255),
+    @IPAddress VARCHAR(45),
+    @UserAgent VARCHAR(512),
+    @Outcome VARCHAR(20),
+    @Detail VARCHAR(1000),
+    @CreatedAt DATETIME2
+AS
+BEGIN
+    INSERT INTO audit_events (event_type, user_id, actor, ip_address, user_agent, outcome, detail, created_at)
+    VALUES (@EventType, @UserID, LEFT(@Actor, 255), LEFT(@IPAddress, 45), LEFT(@UserAgent, 512), @Outcome, LEFT(@Detail, 1000), @CreatedAt)
+END
+GO
+
+CREATE PROCEDURE GetAuditEvents
+    @UserID INT = NULL,
+    @From DATETIME2 = NULL,
+    @To DATETIME2 = NULL,
+    @Limit INT = 100
+AS
+BEGIN
+    SELECT TOP (@Limit) id, event_type, user_id, actor, ip_address, user_agent, outcome, detail, created_at
+    FROM audit_events
+    WHERE (@UserID IS NULL OR user_id = @UserID)
+      AND (@From IS NULL OR created_at >= @From)
+      AND (@To IS NULL OR created_at < @To)
+    ORDER BY created_at DESC
+END
@@ -0,0 +1,7 @@
+DROP PROCEDURE RevokeUserSessions;
+DROP PROCEDURE RevokeSession;
+DROP PROCEDURE UpdateSessionLastSeen;
+DROP PROCEDURE GetSessionsByUserID;
+DROP PROCEDURE GetSessionByID;
+DROP PROCEDURE CreateSession;
+DROP TABLE sessions;
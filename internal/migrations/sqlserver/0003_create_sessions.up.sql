@@ -0,0 +1,78 @@
+CREATE TABLE sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL,
+    device VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    user_agent VARCHAR(512) NOT NULL,
+    created_at DATETIME2 NOT NULL,
+    last_seen_at DATETIME2 NOT NULL,
+    expires_at DATETIME2 NOT NULL,
+    revoked_at DATETIME2 NULL,
+    CONSTRAINT FK_sessions_users FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IX_sessions_user_id_created_at ON sessions (user_id, created_at);
+GO
+
+CREATE PROCEDURE CreateSession
+    @ID VARCHAR(64),
+    @UserID INT,
+    @Device VARCHAR(255),
+    @IPAddress VARCHAR(45),
+    @UserAgent VARCHAR(512),
+    @CreatedAt DATETIME2,
+    @ExpiresAt DATETIME2
+AS
+BEGIN
+    INSERT INTO sessions (id, user_id, device, ip_address, user_agent, created_at, last_seen_at, expires_at)
+    VALUES (@ID, @UserID, @Device, LEFT(@IPAddress, 45), LEFT(@UserAgent, 512), @CreatedAt, @CreatedAt, @ExpiresAt)
+END
+GO
+
+CREATE PROCEDURE GetSessionByID
+    @ID VARCHAR(64)
+AS
+BEGIN
+    SELECT id, user_id, device, ip_address, user_agent, created_at, last_seen_at, expires_at, revoked_at
+    FROM sessions
+    WHERE id = @ID
+END
+GO
+
+CREATE PROCEDURE GetSessionsByUserID
+    @UserID INT,
+    @Limit INT = 50
+AS
+BEGIN
+    SELECT TOP (@Limit) id, user_id, device, ip_address, user_agent, created_at, last_seen_at, expires_at, revoked_at
+    FROM sessions
+    WHERE user_id = @UserID
+    ORDER BY created_at DESC
+END
+GO
+
+CREATE PROCEDURE UpdateSessionLastSeen
+    @ID VARCHAR(64),
+    @LastSeenAt DATETIME2
+AS
+BEGIN
+    UPDATE sessions SET last_seen_at = @LastSeenAt WHERE id = @ID
+END
+GO
+
+CREATE PROCEDURE RevokeSession
+    @ID VARCHAR(64),
+    @RevokedAt DATETIME2
+AS
+BEGIN
+    UPDATE sessions SET revoked_at = @RevokedAt WHERE id = @ID AND revoked_at IS NULL
+END
+GO
+
+CREATE PROCEDURE RevokeUserSessions
+    @UserID INT,
+    @RevokedAt DATETIME2
+AS
+BEGIN
+    UPDATE sessions SET revoked_at = @RevokedAt WHERE user_id = @UserID AND revoked_at IS NULL
+END
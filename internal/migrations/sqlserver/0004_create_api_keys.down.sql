@@ -0,0 +1,7 @@
+DROP PROCEDURE RevokeAPIKey;
+DROP PROCEDURE UpdateAPIKeyLastUsed;
+DROP PROCEDURE GetAPIKeysByUserID;
+DROP PROCEDURE GetAPIKeyByPrefix;
+DROP PROCEDURE GetAPIKeyByID;
+DROP PROCEDURE CreateAPIKey;
+DROP TABLE api_keys;
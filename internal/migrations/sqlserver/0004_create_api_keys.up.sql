@@ -0,0 +1,79 @@
+CREATE TABLE api_keys (
+    id INT IDENTITY(1,1) PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    secret_hash CHAR(64) NOT NULL,
+    scopes VARCHAR(500) NOT NULL,
+    expires_at DATETIME2 NULL,
+    last_used_at DATETIME2 NULL,
+    created_at DATETIME2 NOT NULL,
+    revoked_at DATETIME2 NULL,
+    CONSTRAINT UC_api_keys_prefix UNIQUE (prefix),
+    CONSTRAINT FK_api_keys_users FOREIGN KEY (user_id) REFERENCES users (id)
+);
+GO
+
+CREATE PROCEDURE CreateAPIKey
+    @UserID INT,
+    @Name VARCHAR(100),
+    @Prefix VARCHAR(20),
+    @SecretHash CHAR(64),
+    @Scopes VARCHAR(500),
+    @ExpiresAt DATETIME2,
+    @CreatedAt DATETIME2
+AS
+BEGIN
+    INSERT INTO api_keys (user_id, name, prefix, secret_hash, scopes, expires_at, created_at)
+    VALUES (@UserID, @Name, @Prefix, @SecretHash, @Scopes, @ExpiresAt, @CreatedAt);
+    SELECT CAST(SCOPE_IDENTITY() AS INT);
+END
+GO
+
+CREATE PROCEDURE GetAPIKeyByID
+    @ID INT
+AS
+BEGIN
+    SELECT id, user_id, name, prefix, secret_hash, scopes, expires_at, last_used_at, created_at, revoked_at
+    FROM api_keys
+    WHERE id = @ID
+END
+GO
+
+CREATE PROCEDURE GetAPIKeyByPrefix
+    @Prefix VARCHAR(20)
+AS
+BEGIN
+    SELECT id, user_id, name, prefix, secret_hash, scopes, expires_at, last_used_at, created_at, revoked_at
+    FROM api_keys
+    WHERE prefix = @Prefix
+END
+GO
+
+CREATE PROCEDURE GetAPIKeysByUserID
+    @UserID INT
+AS
+BEGIN
+    SELECT id, user_id, name, prefix, secret_hash, scopes, expires_at, last_used_at, created_at, revoked_at
+    FROM api_keys
+    WHERE user_id = @UserID
+    ORDER BY created_at DESC
+END
+GO
+
+CREATE PROCEDURE UpdateAPIKeyLastUsed
+    @ID INT,
+    @LastUsedAt DATETIME2
+AS
+BEGIN
+    UPDATE api_keys SET last_used_at = @LastUsedAt WHERE id = @ID
+END
+GO
+
+CREATE PROCEDURE RevokeAPIKey
+    @ID INT,
+    @RevokedAt DATETIME2
+AS
+BEGIN
+    UPDATE api_keys SET revoked_at = @RevokedAt WHERE id = @ID AND revoked_at IS NULL
+END
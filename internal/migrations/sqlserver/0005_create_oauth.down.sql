@@ -0,0 +1,13 @@
+DROP PROCEDURE RevokeOAuthGrant;
+DROP PROCEDURE RevokeOAuthToken;
+DROP PROCEDURE GetOAuthTokenByHash;
+DROP PROCEDURE CreateOAuthToken;
+DROP PROCEDURE ConsumeOAuthAuthorizationCode;
+DROP PROCEDURE CreateOAuthAuthorizationCode;
+DROP PROCEDURE DeleteOAuthClient;
+DROP PROCEDURE GetOAuthClients;
+DROP PROCEDURE GetOAuthClientByClientID;
+DROP PROCEDURE CreateOAuthClient;
+DROP TABLE oauth_tokens;
+DROP TABLE oauth_authorization_codes;
+DROP TABLE oauth_clients;
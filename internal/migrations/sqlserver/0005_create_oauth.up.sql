@@ -0,0 +1,172 @@
+CREATE TABLE oauth_clients (
+    id INT IDENTITY(1,1) PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL,
+    secret_hash CHAR(64) NULL,
+    name VARCHAR(100) NOT NULL,
+    redirect_uris VARCHAR(2000) NOT NULL,
+    post_logout_redirect_uris VARCHAR(2000) NOT NULL,
+    grant_types VARCHAR(200) NOT NULL,
+    scopes VARCHAR(500) NOT NULL,
+    is_public BIT NOT NULL,
+    created_at DATETIME2 NOT NULL,
+    CONSTRAINT UC_oauth_clients_client_id UNIQUE (client_id)
+);
+
+CREATE TABLE oauth_authorization_codes (
+    code_hash CHAR(64) PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL,
+    user_id INT NOT NULL,
+    redirect_uri VARCHAR(500) NOT NULL,
+    scopes VARCHAR(500) NOT NULL,
+    code_challenge VARCHAR(128) NOT NULL,
+    code_challenge_method VARCHAR(10) NOT NULL,
+    nonce VARCHAR(500) NOT NULL,
+    session_id VARCHAR(64) NOT NULL,
+    auth_time DATETIME2 NULL,
+    expires_at DATETIME2 NOT NULL,
+    created_at DATETIME2 NOT NULL,
+    used_at DATETIME2 NULL,
+    CONSTRAINT FK_oauth_authorization_codes_clients FOREIGN KEY (client_id) REFERENCES oauth_clients (client_id),
+    CONSTRAINT FK_oauth_authorization_codes_users FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE oauth_tokens (
+    token_hash CHAR(64) PRIMARY KEY,
+    token_type VARCHAR(20) NOT NULL,
+    grant_id VARCHAR(64) NOT NULL,
+    client_id VARCHAR(64) NOT NULL,
+    user_id INT NULL,
+    scopes VARCHAR(500) NOT NULL,
+    expires_at DATETIME2 NOT NULL,
+    created_at DATETIME2 NOT NULL,
+    revoked_at DATETIME2 NULL,
+    CONSTRAINT FK_oauth_tokens_clients FOREIGN KEY (client_id) REFERENCES oauth_clients (client_id),
+    CONSTRAINT FK_oauth_tokens_users FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE INDEX IX_oauth_tokens_grant_id ON oauth_tokens (grant_id);
+GO
+
+CREATE PROCEDURE CreateOAuthClient
+    @ClientID VARCHAR(64),
+    @SecretHash CHAR(64),
+    @Name VARCHAR(100),
+    @RedirectURIs VARCHAR(2000),
+    @PostLogoutRedirectURIs VARCHAR(2000),
+    @GrantTypes VARCHAR(200),
+    @Scopes VARCHAR(500),
+    @IsPublic BIT,
+    @CreatedAt DATETIME2
+AS
+BEGIN
+    INSERT INTO oauth_clients (client_id, secret_hash, name, redirect_uris, post_logout_redirect_uris, grant_types, scopes, is_public, created_at)
+    VALUES (@ClientID, NULLIF(@SecretHash, ''), @Name, @RedirectURIs, @PostLogoutRedirectURIs, @GrantTypes, @Scopes, @IsPublic, @CreatedAt);
+    SELECT CAST(SCOPE_IDENTITY() AS INT);
+END
+GO
+
+CREATE PROCEDURE GetOAuthClientByClientID
+    @ClientID VARCHAR(64)
+AS
+BEGIN
+    SELECT id, client_id, ISNULL(secret_hash, ''), name, redirect_uris, grant_types, scopes, is_public, created_at
+    FROM oauth_clients
+    WHERE client_id = @ClientID
+END
+GO
+
+CREATE PROCEDURE GetOAuthClients
+AS
+BEGIN
+    SELECT id, client_id, ISNULL(secret_hash, ''), name, redirect_uris, grant_types, scopes, is_public, created_at
+    FROM oauth_clients
+    ORDER BY created_at DESC
+END
+GO
+
+CREATE PROCEDURE DeleteOAuthClient
+    @ClientID VARCHAR(64)
+AS
+BEGIN
+    DELETE FROM oauth_tokens WHERE client_id = @ClientID;
+    DELETE FROM oauth_authorization_codes WHERE client_id = @ClientID;
+    DELETE FROM oauth_clients WHERE client_id = @ClientID;
+END
+GO
+
+CREATE PROCEDURE CreateOAuthAuthorizationCode
+    @CodeHash CHAR(64),
+    @ClientID VARCHAR(64),
+    @UserID INT,
+    @RedirectURI VARCHAR(500),
+    @Scopes VARCHAR(500),
+    @CodeChallenge VARCHAR(128),
+    @CodeChallengeMethod VARCHAR(10),
+    @Nonce VARCHAR(500),
+    @SessionID VARCHAR(64),
+    @AuthTime DATETIME2,
+    @ExpiresAt DATETIME2,
+    @CreatedAt DATETIME2
+AS
+BEGIN
+    INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, code_challenge_method, nonce, session_id, auth_time, expires_at, created_at)
+    VALUES (@CodeHash, @ClientID, @UserID, @RedirectURI, @Scopes, @CodeChallenge, @CodeChallengeMethod, @Nonce, @SessionID, @AuthTime, @ExpiresAt, @CreatedAt)
+END
+GO
+
+CREATE PROCEDURE ConsumeOAuthAuthorizationCode
+    @CodeHash CHAR(64),
+    @UsedAt DATETIME2
+AS
+BEGIN
+    UPDATE oauth_authorization_codes
+    SET used_at = @UsedAt
+    OUTPUT inserted.code_hash, inserted.client_id, inserted.user_id, inserted.redirect_uri, inserted.scopes,
+           inserted.code_challenge, inserted.code_challenge_method, inserted.nonce, inserted.session_id, inserted.auth_time,
+           inserted.expires_at, inserted.created_at
+    WHERE code_hash = @CodeHash AND used_at IS NULL
+END
+GO
+
+CREATE PROCEDURE CreateOAuthToken
+    @TokenHash CHAR(64),
+    @TokenType VARCHAR(20),
+    @GrantID VARCHAR(64),
+    @ClientID VARCHAR(64),
+    @UserID INT,
+    @Scopes VARCHAR(500),
+    @ExpiresAt DATETIME2,
+    @CreatedAt DATETIME2
+AS
+BEGIN
+    INSERT INTO oauth_tokens (token_hash, token_type, grant_id, client_id, user_id, scopes, expires_at, created_at)
+    VALUES (@TokenHash, @TokenType, @GrantID, @ClientID, @UserID, @Scopes, @ExpiresAt, @CreatedAt)
+END
+GO
+
+CREATE PROCEDURE GetOAuthTokenByHash
+    @TokenHash CHAR(64)
+AS
+BEGIN
+    SELECT token_hash, token_type, grant_id, client_id, user_id, scopes, expires_at, created_at, revoked_at
+    FROM oauth_tokens
+    WHERE token_hash = @TokenHash
+END
+GO
+
+CREATE PROCEDURE RevokeOAuthToken
+    @TokenHash CHAR(64),
+    @RevokedAt DATETIME2
+AS
+BEGIN
+    UPDATE oauth_tokens SET revoked_at = @RevokedAt WHERE token_hash = @TokenHash AND revoked_at IS NULL
+END
+GO
+
+CREATE PROCEDURE RevokeOAuthGrant
+    @GrantID VARCHAR(64),
+    @RevokedAt DATETIME2
+AS
+BEGIN
+    UPDATE oauth_tokens SET revoked_at = @RevokedAt WHERE grant_id = @GrantID AND revoked_at IS NULL
+END
@@ -0,0 +1,8 @@
+DROP PROCEDURE ConsumeSocialLoginState;
+DROP PROCEDURE CreateSocialLoginState;
+DROP PROCEDURE DeleteUserIdentity;
+DROP PROCEDURE GetUserIdentitiesByUserID;
+DROP PROCEDURE GetUserIdentity;
+DROP PROCEDURE CreateUserIdentity;
+DROP TABLE social_login_states;
+DROP TABLE user_identities;
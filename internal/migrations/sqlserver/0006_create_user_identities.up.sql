@@ -0,0 +1,91 @@
+CREATE TABLE user_identities (
+    id INT IDENTITY(1,1) PRIMARY KEY,
+    user_id INT NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at DATETIME2 NOT NULL,
+    CONSTRAINT UC_user_identities_provider_subject UNIQUE (provider, subject),
+    CONSTRAINT UC_user_identities_user_provider UNIQUE (user_id, provider),
+    CONSTRAINT FK_user_identities_users FOREIGN KEY (user_id) REFERENCES users (id)
+);
+
+CREATE TABLE social_login_states (
+    state_hash CHAR(64) PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    nonce VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    user_id INT NULL,
+    expires_at DATETIME2 NOT NULL,
+    created_at DATETIME2 NOT NULL
+);
+GO
+
+CREATE PROCEDURE CreateUserIdentity
+    @UserID INT,
+    @Provider VARCHAR(50),
+    @Subject VARCHAR(255),
+    @Email VARCHAR(255),
+    @CreatedAt DATETIME2
+AS
+BEGIN
+    INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+    VALUES (@UserID, @Provider, @Subject, @Email, @CreatedAt)
+END
+GO
+
+CREATE PROCEDURE GetUserIdentity
+    @Provider VARCHAR(50),
+    @Subject VARCHAR(255)
+AS
+BEGIN
+    SELECT id, user_id, provider, subject, email, created_at
+    FROM user_identities
+    WHERE provider = @Provider AND subject = @Subject
+END
+GO
+
+CREATE PROCEDURE GetUserIdentitiesByUserID
+    @UserID INT
+AS
+BEGIN
+    SELECT id, user_id, provider, subject, email, created_at
+    FROM user_identities
+    WHERE user_id = @UserID
+    ORDER BY created_at
+END
+GO
+
+CREATE PROCEDURE DeleteUserIdentity
+    @UserID INT,
+    @Provider VARCHAR(50)
+AS
+BEGIN
+    DELETE FROM user_identities WHERE user_id = @UserID AND provider = @Provider
+END
+GO
+
+CREATE PROCEDURE CreateSocialLoginState
+    @StateHash CHAR(64),
+    @Provider VARCHAR(50),
+    @Nonce VARCHAR(64),
+    @CodeVerifier VARCHAR(128),
+    @UserID INT,
+    @ExpiresAt DATETIME2,
+    @CreatedAt DATETIME2
+AS
+BEGIN
+    INSERT INTO social_login_states (state_hash, provider, nonce, code_verifier, user_id, expires_at, created_at)
+    VALUES (@StateHash, @Provider, @Nonce, @CodeVerifier, @UserID, @ExpiresAt, @CreatedAt)
+END
+GO
+
+CREATE PROCEDURE ConsumeSocialLoginState
+    @StateHash CHAR(64)
+AS
+BEGIN
+    DELETE FROM social_login_states
+    OUTPUT deleted.state_hash, deleted.provider, deleted.nonce, deleted.code_verifier, deleted.user_id,
+           deleted.expires_at, deleted.created_at
+    WHERE state_hash = @StateHash
+END
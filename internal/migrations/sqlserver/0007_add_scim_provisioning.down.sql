@@ -0,0 +1,9 @@
+DROP PROCEDURE DeleteUser;
+DROP PROCEDURE UpdateUser;
+DROP PROCEDURE GetUsers;
+DROP PROCEDURE DeleteSCIMClient;
+DROP PROCEDURE GetSCIMClients;
+DROP PROCEDURE GetSCIMClientByTokenHash;
+DROP PROCEDURE GetSCIMClientByID;
+DROP PROCEDURE CreateSCIMClient;
+DROP TABLE scim_clients;
@@ -0,0 +1,101 @@
+CREATE TABLE scim_clients (
+    id INT IDENTITY(1,1) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    created_at DATETIME2 NOT NULL,
+    CONSTRAINT UC_scim_clients_token_hash UNIQUE (token_hash)
+);
+GO
+
+CREATE PROCEDURE CreateSCIMClient
+    @Name VARCHAR(100),
+    @TokenHash CHAR(64),
+    @CreatedAt DATETIME2
+AS
+BEGIN
+    INSERT INTO scim_clients (name, token_hash, created_at)
+    OUTPUT inserted.id
+    VALUES (@Name, @TokenHash, @CreatedAt)
+END
+GO
+
+CREATE PROCEDURE GetSCIMClientByID
+    @ID INT
+AS
+BEGIN
+    SELECT id, name, token_hash, created_at FROM scim_clients
+    WHERE id = @ID
+END
+GO
+
+CREATE PROCEDURE GetSCIMClientByTokenHash
+    @TokenHash CHAR(64)
+AS
+BEGIN
+    SELECT id, name, token_hash, created_at FROM scim_clients
+    WHERE token_hash = @TokenHash
+END
+GO
+
+CREATE PROCEDURE GetSCIMClients
+AS
+BEGIN
+    SELECT id, name, token_hash, created_at FROM scim_clients
+    ORDER BY created_at DESC
+END
+GO
+
+CREATE PROCEDURE DeleteSCIMClient
+    @ID INT
+AS
+BEGIN
+    DELETE FROM scim_clients WHERE id = @ID
+END
+GO
+
+CREATE PROCEDURE GetUsers
+    @Username VARCHAR(255) = NULL,
+    @Email VARCHAR(255) = NULL,
+    @Offset INT = 0,
+    @Limit INT = NULL
+AS
+BEGIN
+    SELECT id, username, email, phone, password, disabled, COUNT(*) OVER() AS total
+    FROM users
+    WHERE (@Username IS NULL OR username = @Username)
+      AND (@Email IS NULL OR email = @Email)
+    ORDER BY id
+    OFFSET @Offset ROWS
+    FETCH NEXT ISNULL(@Limit, 2147483647) ROWS ONLY
+END
+GO
+
+CREATE PROCEDURE UpdateUser
+    @ID INT,
+    @Username VARCHAR(255),
+    @Email VARCHAR(255),
+    @Phone VARCHAR(10),
+    @Password VARCHAR(255),
+    @Disabled BIT
+AS
+BEGIN
+    UPDATE users
+    SET username = @Username, email = @Email, phone = @Phone, password = @Password, disabled = @Disabled
+    WHERE id = @ID
+END
+GO
+
+CREATE PROCEDURE DeleteUser
+    @ID INT
+AS
+BEGIN
+    BEGIN TRANSACTION;
+    DELETE FROM sessions WHERE user_id = @ID;
+    DELETE FROM api_keys WHERE user_id = @ID;
+    DELETE FROM oauth_authorization_codes WHERE user_id = @ID;
+    DELETE FROM oauth_tokens WHERE user_id = @ID;
+    DELETE FROM user_identities WHERE user_id = @ID;
+    DELETE FROM social_login_states WHERE user_id = @ID;
+    DELETE FROM users WHERE id = @ID;
+    COMMIT TRANSACTION;
+END
@@ -0,0 +1,46 @@
+ALTER PROCEDURE UpdateUser
+    @ID INT,
+    @Username VARCHAR(255),
+    @Email VARCHAR(255),
+    @Phone VARCHAR(10),
+    @Password VARCHAR(255),
+    @Disabled BIT
+AS
+BEGIN
+    UPDATE users
+    SET username = @Username, email = @Email, phone = @Phone, password = @Password, disabled = @Disabled
+    WHERE id = @ID
+END
+GO
+
+ALTER PROCEDURE GetUsers
+    @Username VARCHAR(255) = NULL,
+    @Email VARCHAR(255) = NULL,
+    @Offset INT = 0,
+    @Limit INT = NULL
+AS
+BEGIN
+    SELECT id, username, email, phone, password, disabled, COUNT(*) OVER() AS total
+    FROM users
+    WHERE (@Username IS NULL OR username = @Username)
+      AND (@Email IS NULL OR email = @Email)
+    ORDER BY id
+    OFFSET @Offset ROWS
+    FETCH NEXT ISNULL(@Limit, 2147483647) ROWS ONLY
+END
+GO
+
+ALTER PROCEDURE CreateUser
+    @Username VARCHAR(255),
+    @Email VARCHAR(255),
+    @Phone VARCHAR(10),
+    @Password VARCHAR(255),
+    @Disabled BIT = 0
+AS
+BEGIN
+    INSERT INTO users (username, email, phone, password, disabled)
+    VALUES (@Username, @Email, @Phone, @Password, @Disabled)
+END
+GO
+
+ALTER TABLE users DROP COLUMN locale;
@@ -0,0 +1,19 @@
+ALTER PROCEDURE DeleteUser
+    @ID INT
+AS
+BEGIN
+    BEGIN TRANSACTION;
+    DELETE FROM sessions WHERE user_id = @ID;
+    DELETE FROM api_keys WHERE user_id = @ID;
+    DELETE FROM oauth_authorization_codes WHERE user_id = @ID;
+    DELETE FROM oauth_tokens WHERE user_id = @ID;
+    DELETE FROM user_identities WHERE user_id = @ID;
+    DELETE FROM social_login_states WHERE user_id = @ID;
+    DELETE FROM users WHERE id = @ID;
+    COMMIT TRANSACTION;
+END
+GO
+
+DROP PROCEDURE ConsumeRevokeLink;
+DROP PROCEDURE CreateRevokeLink;
+DROP TABLE revoke_links;
@@ -0,0 +1,50 @@
+-- "This wasn't me" links of the login alerts. Each link can only be used once.
+CREATE TABLE revoke_links (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL,
+    created_at DATETIME2 NOT NULL,
+    expires_at DATETIME2 NOT NULL,
+    used_at DATETIME2 NULL,
+    CONSTRAINT FK_revoke_links_users FOREIGN KEY (user_id) REFERENCES users (id)
+);
+GO
+
+CREATE PROCEDURE CreateRevokeLink
+    @ID VARCHAR(64),
+    @UserID INT,
+    @CreatedAt DATETIME2,
+    @ExpiresAt DATETIME2
+AS
+BEGIN
+    INSERT INTO revoke_links (id, user_id, created_at, expires_at)
+    VALUES (@ID, @UserID, @CreatedAt, @ExpiresAt)
+END
+GO
+
+CREATE PROCEDURE ConsumeRevokeLink
+    @ID VARCHAR(64),
+    @UsedAt DATETIME2
+AS
+BEGIN
+    UPDATE revoke_links
+    SET used_at = @UsedAt
+    OUTPUT inserted.id, inserted.user_id, inserted.created_at, inserted.expires_at, inserted.used_at
+    WHERE id = @ID AND used_at IS NULL
+END
+GO
+
+ALTER PROCEDURE DeleteUser
+    @ID INT
+AS
+BEGIN
+    BEGIN TRANSACTION;
+    DELETE FROM sessions WHERE user_id = @ID;
+    DELETE FROM revoke_links WHERE user_id = @ID;
+    DELETE FROM api_keys WHERE user_id = @ID;
+    DELETE FROM oauth_authorization_codes WHERE user_id = @ID;
+    DELETE FROM oauth_tokens WHERE user_id = @ID;
+    DELETE FROM user_identities WHERE user_id = @ID;
+    DELETE FROM social_login_states WHERE user_id = @ID;
+    DELETE FROM users WHERE id = @ID;
+    COMMIT TRANSACTION;
+END
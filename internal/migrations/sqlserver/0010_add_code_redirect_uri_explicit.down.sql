@@ -0,0 +1,36 @@
+ALTER PROCEDURE ConsumeOAuthAuthorizationCode
+    @CodeHash CHAR(64),
+    @UsedAt DATETIME2
+AS
+BEGIN
+    UPDATE oauth_authorization_codes
+    SET used_at = @UsedAt
+    OUTPUT inserted.code_hash, inserted.client_id, inserted.user_id, inserted.redirect_uri, inserted.scopes,
+           inserted.code_challenge, inserted.code_challenge_method, inserted.nonce, inserted.session_id, inserted.auth_time,
+           inserted.expires_at, inserted.created_at
+    WHERE code_hash = @CodeHash AND used_at IS NULL
+END
+GO
+
+ALTER PROCEDURE CreateOAuthAuthorizationCode
+    @CodeHash CHAR(64),
+    @ClientID VARCHAR(64),
+    @UserID INT,
+    @RedirectURI VARCHAR(500),
+    @Scopes VARCHAR(500),
+    @CodeChallenge VARCHAR(128),
+    @CodeChallengeMethod VARCHAR(10),
+    @Nonce VARCHAR(500),
+    @SessionID VARCHAR(64),
+    @AuthTime DATETIME2,
+    @ExpiresAt DATETIME2,
+    @CreatedAt DATETIME2
+AS
+BEGIN
+    INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, code_challenge_method, nonce, session_id, auth_time, expires_at, created_at)
+    VALUES (@CodeHash, @ClientID, @UserID, @RedirectURI, @Scopes, @CodeChallenge, @CodeChallengeMethod, @Nonce, @SessionID, @AuthTime, @ExpiresAt, @CreatedAt)
+END
+GO
+
+ALTER TABLE oauth_authorization_codes DROP CONSTRAINT DF_oauth_authorization_codes_redirect_uri_explicit;
+ALTER TABLE oauth_authorization_codes DROP COLUMN redirect_uri_explicit;
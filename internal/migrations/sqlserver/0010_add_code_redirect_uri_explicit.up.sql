@@ -0,0 +1,39 @@
+-- Whether the authorization request included redirect_uri, in which case the
+-- token request must repeat it (RFC 6749, section 4.1.3).
+ALTER TABLE oauth_authorization_codes ADD redirect_uri_explicit BIT NOT NULL
+    CONSTRAINT DF_oauth_authorization_codes_redirect_uri_explicit DEFAULT 0;
+GO
+
+ALTER PROCEDURE CreateOAuthAuthorizationCode
+    @CodeHash CHAR(64),
+    @ClientID VARCHAR(64),
+    @UserID INT,
+    @RedirectURI VARCHAR(500),
+    @RedirectURIExplicit BIT,
+    @Scopes VARCHAR(500),
+    @CodeChallenge VARCHAR(128),
+    @CodeChallengeMethod VARCHAR(10),
+    @Nonce VARCHAR(500),
+    @SessionID VARCHAR(64),
+    @AuthTime DATETIME2,
+    @ExpiresAt DATETIME2,
+    @CreatedAt DATETIME2
+AS
+BEGIN
+    INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, redirect_uri_explicit, scopes, code_challenge, code_challenge_method, nonce, session_id, auth_time, expires_at, created_at)
+    VALUES (@CodeHash, @ClientID, @UserID, @RedirectURI, @RedirectURIExplicit, @Scopes, @CodeChallenge, @CodeChallengeMethod, @Nonce, @SessionID, @AuthTime, @ExpiresAt, @CreatedAt)
+END
+GO
+
+ALTER PROCEDURE ConsumeOAuthAuthorizationCode
+    @CodeHash CHAR(64),
+    @UsedAt DATETIME2
+AS
+BEGIN
+    UPDATE oauth_authorization_codes
+    SET used_at = @UsedAt
+    OUTPUT inserted.code_hash, inserted.client_id, inserted.user_id, inserted.redirect_uri, inserted.redirect_uri_explicit, inserted.scopes,
+           inserted.code_challenge, inserted.code_challenge_method, inserted.nonce, inserted.session_id, inserted.auth_time,
+           inserted.expires_at, inserted.created_at
+    WHERE code_hash = @CodeHash AND used_at IS NULL
+END
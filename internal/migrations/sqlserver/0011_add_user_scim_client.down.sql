@@ -0,0 +1,33 @@
+ALTER PROCEDURE GetUsers
+    @Username VARCHAR(255) = NULL,
+    @Email VARCHAR(255) = NULL,
+    @Offset INT = 0,
+    @Limit INT = NULL
+AS
+BEGIN
+    SELECT id, username, email, phone, password, disabled, locale, COUNT(*) OVER() AS total
+    FROM users
+    WHERE (@Username IS NULL OR username = @Username)
+      AND (@Email IS NULL OR email = @Email)
+    ORDER BY id
+    OFFSET @Offset ROWS
+    FETCH NEXT ISNULL(@Limit, 2147483647) ROWS ONLY
+END
+GO
+
+ALTER PROCEDURE CreateUser
+    @Username VARCHAR(255),
+    @Email VARCHAR(255),
+    @Phone VARCHAR(10),
+    @Password VARCHAR(255),
+    @Disabled BIT = 0,
+    @Locale VARCHAR(10) = NULL
+AS
+BEGIN
+    INSERT INTO users (username, email, phone, password, disabled, locale)
+    VALUES (@Username, @Email, @Phone, @Password, @Disabled, @Locale)
+END
+GO
+
+ALTER TABLE users DROP CONSTRAINT FK_users_scim_clients;
+ALTER TABLE users DROP COLUMN scim_client_id;
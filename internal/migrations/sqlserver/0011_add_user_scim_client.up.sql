@@ -0,0 +1,38 @@
+-- Provisioning client that created the user; only that client may manage it.
+-- Deleting the client leaves its users unmanaged.
+ALTER TABLE users ADD scim_client_id INT NULL
+    CONSTRAINT FK_users_scim_clients FOREIGN KEY REFERENCES scim_clients (id) ON DELETE SET NULL;
+GO
+
+ALTER PROCEDURE CreateUser
+    @Username VARCHAR(255),
+    @Email VARCHAR(255),
+    @Phone VARCHAR(10),
+    @Password VARCHAR(255),
+    @Disabled BIT = 0,
+    @Locale VARCHAR(10) = NULL,
+    @SCIMClientID INT = NULL
+AS
+BEGIN
+    INSERT INTO users (username, email, phone, password, disabled, locale, scim_client_id)
+    VALUES (@Username, @Email, @Phone, @Password, @Disabled, @Locale, @SCIMClientID)
+END
+GO
+
+ALTER PROCEDURE GetUsers
+    @Username VARCHAR(255) = NULL,
+    @Email VARCHAR(255) = NULL,
+    @Offset INT = 0,
+    @Limit INT = NULL,
+    @SCIMClientID INT = NULL
+AS
+BEGIN
+    SELECT id, username, email, phone, password, disabled, locale, scim_client_id, COUNT(*) OVER() AS total
+    FROM users
+    WHERE (@Username IS NULL OR username = @Username)
+      AND (@Email IS NULL OR email = @Email)
+      AND (@SCIMClientID IS NULL OR scim_client_id = @SCIMClientID)
+    ORDER BY id
+    OFFSET @Offset ROWS
+    FETCH NEXT ISNULL(@Limit, 2147483647) ROWS ONLY
+END
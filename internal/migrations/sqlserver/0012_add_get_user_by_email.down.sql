@@ -0,0 +1 @@
+DROP PROCEDURE GetUserByEmail;
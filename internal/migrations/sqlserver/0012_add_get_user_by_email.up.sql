@@ -0,0 +1,9 @@
+-- Exact email lookup: GetUserByEmailOrUsername also matches a username that
+-- happens to equal the email.
+CREATE PROCEDURE GetUserByEmail
+    @Email VARCHAR(255)
+AS
+BEGIN
+    SELECT * FROM users
+    WHERE email = @Email
+END
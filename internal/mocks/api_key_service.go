@@ -0,0 +1,153 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// APIKeyService is an autogenerated mock type for the APIKeyService type
+type APIKeyService struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: ctx, key
+func (_m *APIKeyService) Authenticate(ctx context.Context, key string) (*model.Claims, *model.APIKey, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authenticate")
+	}
+
+	var r0 *model.Claims
+	var r1 *model.APIKey
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Claims, *model.APIKey, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Claims); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Claims)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) *model.APIKey); ok {
+		r1 = rf(ctx, key)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, key)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, userID, username, req, meta
+func (_m *APIKeyService) CreateAPIKey(ctx context.Context, userID int, username string, req model.APIKeyCreateRequest, meta model.RequestMetadata) (*model.APIKey, string, error) {
+	ret := _m.Called(ctx, userID, username, req, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 *model.APIKey
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, model.APIKeyCreateRequest, model.RequestMetadata) (*model.APIKey, string, error)); ok {
+		return rf(ctx, userID, username, req, meta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, model.APIKeyCreateRequest, model.RequestMetadata) *model.APIKey); ok {
+		r0 = rf(ctx, userID, username, req, meta)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, model.APIKeyCreateRequest, model.RequestMetadata) string); ok {
+		r1 = rf(ctx, userID, username, req, meta)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, string, model.APIKeyCreateRequest, model.RequestMetadata) error); ok {
+		r2 = rf(ctx, userID, username, req, meta)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetAPIKeys provides a mock function with given fields: ctx, userID
+func (_m *APIKeyService) GetAPIKeys(ctx context.Context, userID int) ([]model.APIKey, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeys")
+	}
+
+	var r0 []model.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]model.APIKey, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []model.APIKey); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, userID, username, keyID, meta
+func (_m *APIKeyService) RevokeAPIKey(ctx context.Context, userID int, username string, keyID int, meta model.RequestMetadata) error {
+	ret := _m.Called(ctx, userID, username, keyID, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int, model.RequestMetadata) error); ok {
+		r0 = rf(ctx, userID, username, keyID, meta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAPIKeyService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *APIKeyService {
+	mock := &APIKeyService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
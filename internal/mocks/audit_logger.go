@@ -0,0 +1,64 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AuditLogger is an autogenerated mock type for the AuditLogger type
+type AuditLogger struct {
+	mock.Mock
+}
+
+// GetEvents provides a mock function with given fields: ctx, filter
+func (_m *AuditLogger) GetEvents(ctx context.Context, filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEvents")
+	}
+
+	var r0 []model.AuditEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.AuditEventFilter) ([]model.AuditEvent, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.AuditEventFilter) []model.AuditEvent); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AuditEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.AuditEventFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LogEvent provides a mock function with given fields: ctx, event
+func (_m *AuditLogger) LogEvent(ctx context.Context, event model.AuditEvent) {
+	_m.Called(ctx, event)
+}
+
+// NewAuditLogger creates a new instance of AuditLogger. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuditLogger(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuditLogger {
+	mock := &AuditLogger{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,58 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Directory is an autogenerated mock type for the Directory type
+type Directory struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: login, password
+func (_m *Directory) Authenticate(login string, password string) (*model.DirectoryEntry, error) {
+	ret := _m.Called(login, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authenticate")
+	}
+
+	var r0 *model.DirectoryEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*model.DirectoryEntry, error)); ok {
+		return rf(login, password)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *model.DirectoryEntry); ok {
+		r0 = rf(login, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DirectoryEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(login, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDirectory creates a new instance of Directory. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDirectory(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Directory {
+	mock := &Directory{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
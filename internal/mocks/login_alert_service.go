@@ -0,0 +1,52 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LoginAlertService is an autogenerated mock type for the LoginAlertService type
+type LoginAlertService struct {
+	mock.Mock
+}
+
+// CheckLogin provides a mock function with given fields: ctx, user, meta
+func (_m *LoginAlertService) CheckLogin(ctx context.Context, user model.User, meta model.RequestMetadata) {
+	_m.Called(ctx, user, meta)
+}
+
+// RevokeSessionsFromAlert provides a mock function with given fields: ctx, token, meta
+func (_m *LoginAlertService) RevokeSessionsFromAlert(ctx context.Context, token string, meta model.RequestMetadata) error {
+	ret := _m.Called(ctx, token, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSessionsFromAlert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.RequestMetadata) error); ok {
+		r0 = rf(ctx, token, meta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLoginAlertService creates a new instance of LoginAlertService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoginAlertService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoginAlertService {
+	mock := &LoginAlertService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,279 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// OAuthRepository is an autogenerated mock type for the OAuthRepository type
+type OAuthRepository struct {
+	mock.Mock
+}
+
+// ConsumeAuthorizationCode provides a mock function with given fields: ctx, codeHash, usedAt
+func (_m *OAuthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string, usedAt time.Time) (*model.OAuthAuthorizationCode, error) {
+	ret := _m.Called(ctx, codeHash, usedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeAuthorizationCode")
+	}
+
+	var r0 *model.OAuthAuthorizationCode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (*model.OAuthAuthorizationCode, error)); ok {
+		return rf(ctx, codeHash, usedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) *model.OAuthAuthorizationCode); ok {
+		r0 = rf(ctx, codeHash, usedAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OAuthAuthorizationCode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, codeHash, usedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAuthorizationCode provides a mock function with given fields: ctx, code
+func (_m *OAuthRepository) CreateAuthorizationCode(ctx context.Context, code model.OAuthAuthorizationCode) error {
+	ret := _m.Called(ctx, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAuthorizationCode")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.OAuthAuthorizationCode) error); ok {
+		r0 = rf(ctx, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateOAuthClient provides a mock function with given fields: ctx, client
+func (_m *OAuthRepository) CreateOAuthClient(ctx context.Context, client model.OAuthClient) (int, error) {
+	ret := _m.Called(ctx, client)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOAuthClient")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.OAuthClient) (int, error)); ok {
+		return rf(ctx, client)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.OAuthClient) int); ok {
+		r0 = rf(ctx, client)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.OAuthClient) error); ok {
+		r1 = rf(ctx, client)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateOAuthToken provides a mock function with given fields: ctx, token
+func (_m *OAuthRepository) CreateOAuthToken(ctx context.Context, token model.OAuthToken) error {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOAuthToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.OAuthToken) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteOAuthClient provides a mock function with given fields: ctx, clientID
+func (_m *OAuthRepository) DeleteOAuthClient(ctx context.Context, clientID string) error {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOAuthClient")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetOAuthClientByClientID provides a mock function with given fields: ctx, clientID
+func (_m *OAuthRepository) GetOAuthClientByClientID(ctx context.Context, clientID string) (*model.OAuthClient, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOAuthClientByClientID")
+	}
+
+	var r0 *model.OAuthClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.OAuthClient, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.OAuthClient); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OAuthClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOAuthClients provides a mock function with given fields: ctx
+func (_m *OAuthRepository) GetOAuthClients(ctx context.Context) ([]model.OAuthClient, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOAuthClients")
+	}
+
+	var r0 []model.OAuthClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.OAuthClient, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.OAuthClient); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OAuthClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOAuthTokenByHash provides a mock function with given fields: ctx, tokenHash
+func (_m *OAuthRepository) GetOAuthTokenByHash(ctx context.Context, tokenHash string) (*model.OAuthToken, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOAuthTokenByHash")
+	}
+
+	var r0 *model.OAuthToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.OAuthToken, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.OAuthToken); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OAuthToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeOAuthGrant provides a mock function with given fields: ctx, grantID, revokedAt
+func (_m *OAuthRepository) RevokeOAuthGrant(ctx context.Context, grantID string, revokedAt time.Time) error {
+	ret := _m.Called(ctx, grantID, revokedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeOAuthGrant")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, grantID, revokedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeOAuthToken provides a mock function with given fields: ctx, tokenHash, revokedAt
+func (_m *OAuthRepository) RevokeOAuthToken(ctx context.Context, tokenHash string, revokedAt time.Time) (bool, error) {
+	ret := _m.Called(ctx, tokenHash, revokedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeOAuthToken")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (bool, error)); ok {
+		return rf(ctx, tokenHash, revokedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, tokenHash, revokedAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, tokenHash, revokedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOAuthRepository creates a new instance of OAuthRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOAuthRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OAuthRepository {
+	mock := &OAuthRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,287 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OAuthService is an autogenerated mock type for the OAuthService type
+type OAuthService struct {
+	mock.Mock
+}
+
+// Authorize provides a mock function with given fields: ctx, claims, req
+func (_m *OAuthService) Authorize(ctx context.Context, claims *model.Claims, req model.AuthorizationRequest) (string, error) {
+	ret := _m.Called(ctx, claims, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authorize")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Claims, model.AuthorizationRequest) (string, error)); ok {
+		return rf(ctx, claims, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Claims, model.AuthorizationRequest) string); ok {
+		r0 = rf(ctx, claims, req)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.Claims, model.AuthorizationRequest) error); ok {
+		r1 = rf(ctx, claims, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteClient provides a mock function with given fields: ctx, clientID
+func (_m *OAuthService) DeleteClient(ctx context.Context, clientID string) error {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteClient")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DenyAuthorization provides a mock function with given fields: ctx, req
+func (_m *OAuthService) DenyAuthorization(ctx context.Context, req model.AuthorizationRequest) (string, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DenyAuthorization")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.AuthorizationRequest) (string, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.AuthorizationRequest) string); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.AuthorizationRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetClients provides a mock function with given fields: ctx
+func (_m *OAuthService) GetClients(ctx context.Context) ([]model.OAuthClient, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClients")
+	}
+
+	var r0 []model.OAuthClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.OAuthClient, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.OAuthClient); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.OAuthClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Introspect provides a mock function with given fields: ctx, clientID, clientSecret, token
+func (_m *OAuthService) Introspect(ctx context.Context, clientID string, clientSecret string, token string) (*model.IntrospectionResponse, error) {
+	ret := _m.Called(ctx, clientID, clientSecret, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Introspect")
+	}
+
+	var r0 *model.IntrospectionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*model.IntrospectionResponse, error)); ok {
+		return rf(ctx, clientID, clientSecret, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *model.IntrospectionResponse); ok {
+		r0 = rf(ctx, clientID, clientSecret, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.IntrospectionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, clientID, clientSecret, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterClient provides a mock function with given fields: ctx, req
+func (_m *OAuthService) RegisterClient(ctx context.Context, req model.OAuthClientRegistrationRequest) (*model.OAuthClient, string, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RegisterClient")
+	}
+
+	var r0 *model.OAuthClient
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.OAuthClientRegistrationRequest) (*model.OAuthClient, string, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.OAuthClientRegistrationRequest) *model.OAuthClient); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OAuthClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.OAuthClientRegistrationRequest) string); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, model.OAuthClientRegistrationRequest) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Revoke provides a mock function with given fields: ctx, clientID, clientSecret, token, meta
+func (_m *OAuthService) Revoke(ctx context.Context, clientID string, clientSecret string, token string, meta model.RequestMetadata) error {
+	ret := _m.Called(ctx, clientID, clientSecret, token, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, model.RequestMetadata) error); ok {
+		r0 = rf(ctx, clientID, clientSecret, token, meta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Token provides a mock function with given fields: ctx, req
+func (_m *OAuthService) Token(ctx context.Context, req model.TokenRequest) (*model.TokenResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Token")
+	}
+
+	var r0 *model.TokenResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.TokenRequest) (*model.TokenResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.TokenRequest) *model.TokenResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.TokenRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateAuthorizationRequest provides a mock function with given fields: ctx, req
+func (_m *OAuthService) ValidateAuthorizationRequest(ctx context.Context, req model.AuthorizationRequest) (*model.OAuthClient, []string, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateAuthorizationRequest")
+	}
+
+	var r0 *model.OAuthClient
+	var r1 []string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.AuthorizationRequest) (*model.OAuthClient, []string, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.AuthorizationRequest) *model.OAuthClient); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OAuthClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.AuthorizationRequest) []string); ok {
+		r1 = rf(ctx, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, model.AuthorizationRequest) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewOAuthService creates a new instance of OAuthService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOAuthService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OAuthService {
+	mock := &OAuthService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
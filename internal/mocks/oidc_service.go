@@ -0,0 +1,151 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OIDCService is an autogenerated mock type for the OIDCService type
+type OIDCService struct {
+	mock.Mock
+}
+
+// Discovery provides a mock function with no fields
+func (_m *OIDCService) Discovery() model.OpenIDConfiguration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Discovery")
+	}
+
+	var r0 model.OpenIDConfiguration
+	if rf, ok := ret.Get(0).(func() model.OpenIDConfiguration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(model.OpenIDConfiguration)
+	}
+
+	return r0
+}
+
+// IssueIDToken provides a mock function with given fields: ctx, clientID, code, accessToken
+func (_m *OIDCService) IssueIDToken(ctx context.Context, clientID string, code model.OAuthAuthorizationCode, accessToken string) (string, error) {
+	ret := _m.Called(ctx, clientID, code, accessToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IssueIDToken")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.OAuthAuthorizationCode, string) (string, error)); ok {
+		return rf(ctx, clientID, code, accessToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, model.OAuthAuthorizationCode, string) string); ok {
+		r0 = rf(ctx, clientID, code, accessToken)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, model.OAuthAuthorizationCode, string) error); ok {
+		r1 = rf(ctx, clientID, code, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// JWKS provides a mock function with no fields
+func (_m *OIDCService) JWKS() model.JSONWebKeySet {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for JWKS")
+	}
+
+	var r0 model.JSONWebKeySet
+	if rf, ok := ret.Get(0).(func() model.JSONWebKeySet); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(model.JSONWebKeySet)
+	}
+
+	return r0
+}
+
+// Logout provides a mock function with given fields: ctx, req, meta
+func (_m *OIDCService) Logout(ctx context.Context, req model.LogoutRequest, meta model.RequestMetadata) (string, error) {
+	ret := _m.Called(ctx, req, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Logout")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.LogoutRequest, model.RequestMetadata) (string, error)); ok {
+		return rf(ctx, req, meta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.LogoutRequest, model.RequestMetadata) string); ok {
+		r0 = rf(ctx, req, meta)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.LogoutRequest, model.RequestMetadata) error); ok {
+		r1 = rf(ctx, req, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserInfo provides a mock function with given fields: ctx, accessToken
+func (_m *OIDCService) UserInfo(ctx context.Context, accessToken string) (*model.UserInfo, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserInfo")
+	}
+
+	var r0 *model.UserInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.UserInfo, error)); ok {
+		return rf(ctx, accessToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.UserInfo); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOIDCService creates a new instance of OIDCService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOIDCService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OIDCService {
+	mock := &OIDCService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,156 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SAMLIdentityProvider is an autogenerated mock type for the SAMLIdentityProvider type
+type SAMLIdentityProvider struct {
+	mock.Mock
+}
+
+// Domains provides a mock function with no fields
+func (_m *SAMLIdentityProvider) Domains() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Domains")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// MakeAuthnRequest provides a mock function with given fields: relayState
+func (_m *SAMLIdentityProvider) MakeAuthnRequest(relayState string) (*model.SAMLAuthnRequest, error) {
+	ret := _m.Called(relayState)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MakeAuthnRequest")
+	}
+
+	var r0 *model.SAMLAuthnRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.SAMLAuthnRequest, error)); ok {
+		return rf(relayState)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.SAMLAuthnRequest); ok {
+		r0 = rf(relayState)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SAMLAuthnRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(relayState)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Metadata provides a mock function with no fields
+func (_m *SAMLIdentityProvider) Metadata() ([]byte, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Metadata")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]byte, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []byte); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Name provides a mock function with no fields
+func (_m *SAMLIdentityProvider) Name() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Name")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// ParseResponse provides a mock function with given fields: samlResponse, requestID
+func (_m *SAMLIdentityProvider) ParseResponse(samlResponse string, requestID string) (*model.ExternalIdentity, error) {
+	ret := _m.Called(samlResponse, requestID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ParseResponse")
+	}
+
+	var r0 *model.ExternalIdentity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*model.ExternalIdentity, error)); ok {
+		return rf(samlResponse, requestID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *model.ExternalIdentity); ok {
+		r0 = rf(samlResponse, requestID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ExternalIdentity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(samlResponse, requestID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSAMLIdentityProvider creates a new instance of SAMLIdentityProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSAMLIdentityProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SAMLIdentityProvider {
+	mock := &SAMLIdentityProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
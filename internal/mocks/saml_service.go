@@ -0,0 +1,117 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SAMLService is an autogenerated mock type for the SAMLService type
+type SAMLService struct {
+	mock.Mock
+}
+
+// ConsumeAssertion provides a mock function with given fields: ctx, connection, samlResponse, relayState, browserRelayState, meta
+func (_m *SAMLService) ConsumeAssertion(ctx context.Context, connection string, samlResponse string, relayState string, browserRelayState string, meta model.RequestMetadata) (string, error) {
+	ret := _m.Called(ctx, connection, samlResponse, relayState, browserRelayState, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeAssertion")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string, model.RequestMetadata) (string, error)); ok {
+		return rf(ctx, connection, samlResponse, relayState, browserRelayState, meta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string, model.RequestMetadata) string); ok {
+		r0 = rf(ctx, connection, samlResponse, relayState, browserRelayState, meta)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string, model.RequestMetadata) error); ok {
+		r1 = rf(ctx, connection, samlResponse, relayState, browserRelayState, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Metadata provides a mock function with given fields: connection
+func (_m *SAMLService) Metadata(connection string) ([]byte, error) {
+	ret := _m.Called(connection)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Metadata")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]byte, error)); ok {
+		return rf(connection)
+	}
+	if rf, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = rf(connection)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(connection)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StartLogin provides a mock function with given fields: ctx, connection
+func (_m *SAMLService) StartLogin(ctx context.Context, connection string) (*model.SAMLAuthnRequest, error) {
+	ret := _m.Called(ctx, connection)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartLogin")
+	}
+
+	var r0 *model.SAMLAuthnRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.SAMLAuthnRequest, error)); ok {
+		return rf(ctx, connection)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.SAMLAuthnRequest); ok {
+		r0 = rf(ctx, connection)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SAMLAuthnRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, connection)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSAMLService creates a new instance of SAMLService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSAMLService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SAMLService {
+	mock := &SAMLService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
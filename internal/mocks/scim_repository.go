@@ -0,0 +1,165 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SCIMRepository is an autogenerated mock type for the SCIMRepository type
+type SCIMRepository struct {
+	mock.Mock
+}
+
+// CreateSCIMClient provides a mock function with given fields: ctx, client
+func (_m *SCIMRepository) CreateSCIMClient(ctx context.Context, client model.SCIMClient) (int, error) {
+	ret := _m.Called(ctx, client)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSCIMClient")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient) (int, error)); ok {
+		return rf(ctx, client)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient) int); ok {
+		r0 = rf(ctx, client)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.SCIMClient) error); ok {
+		r1 = rf(ctx, client)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteSCIMClient provides a mock function with given fields: ctx, id
+func (_m *SCIMRepository) DeleteSCIMClient(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSCIMClient")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSCIMClientByID provides a mock function with given fields: ctx, id
+func (_m *SCIMRepository) GetSCIMClientByID(ctx context.Context, id int) (*model.SCIMClient, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSCIMClientByID")
+	}
+
+	var r0 *model.SCIMClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*model.SCIMClient, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *model.SCIMClient); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SCIMClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSCIMClientByTokenHash provides a mock function with given fields: ctx, tokenHash
+func (_m *SCIMRepository) GetSCIMClientByTokenHash(ctx context.Context, tokenHash string) (*model.SCIMClient, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSCIMClientByTokenHash")
+	}
+
+	var r0 *model.SCIMClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.SCIMClient, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.SCIMClient); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SCIMClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSCIMClients provides a mock function with given fields: ctx
+func (_m *SCIMRepository) GetSCIMClients(ctx context.Context) ([]model.SCIMClient, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSCIMClients")
+	}
+
+	var r0 []model.SCIMClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.SCIMClient, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.SCIMClient); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SCIMClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSCIMRepository creates a new instance of SCIMRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSCIMRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SCIMRepository {
+	mock := &SCIMRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,370 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SCIMService is an autogenerated mock type for the SCIMService type
+type SCIMService struct {
+	mock.Mock
+}
+
+// AuthenticateClient provides a mock function with given fields: ctx, token
+func (_m *SCIMService) AuthenticateClient(ctx context.Context, token string) (*model.SCIMClient, error) {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthenticateClient")
+	}
+
+	var r0 *model.SCIMClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.SCIMClient, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.SCIMClient); ok {
+		r0 = rf(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SCIMClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateClient provides a mock function with given fields: ctx, req
+func (_m *SCIMService) CreateClient(ctx context.Context, req model.SCIMClientCreateRequest) (*model.SCIMClient, string, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateClient")
+	}
+
+	var r0 *model.SCIMClient
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClientCreateRequest) (*model.SCIMClient, string, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClientCreateRequest) *model.SCIMClient); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SCIMClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.SCIMClientCreateRequest) string); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, model.SCIMClientCreateRequest) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// CreateUser provides a mock function with given fields: ctx, client, req, meta
+func (_m *SCIMService) CreateUser(ctx context.Context, client model.SCIMClient, req model.SCIMUser, meta model.RequestMetadata) (*model.SCIMUser, error) {
+	ret := _m.Called(ctx, client, req, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUser")
+	}
+
+	var r0 *model.SCIMUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, model.SCIMUser, model.RequestMetadata) (*model.SCIMUser, error)); ok {
+		return rf(ctx, client, req, meta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, model.SCIMUser, model.RequestMetadata) *model.SCIMUser); ok {
+		r0 = rf(ctx, client, req, meta)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SCIMUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.SCIMClient, model.SCIMUser, model.RequestMetadata) error); ok {
+		r1 = rf(ctx, client, req, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteClient provides a mock function with given fields: ctx, id
+func (_m *SCIMService) DeleteClient(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteClient")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUser provides a mock function with given fields: ctx, client, id, meta
+func (_m *SCIMService) DeleteUser(ctx context.Context, client model.SCIMClient, id string, meta model.RequestMetadata) error {
+	ret := _m.Called(ctx, client, id, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, string, model.RequestMetadata) error); ok {
+		r0 = rf(ctx, client, id, meta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetClients provides a mock function with given fields: ctx
+func (_m *SCIMService) GetClients(ctx context.Context) ([]model.SCIMClient, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClients")
+	}
+
+	var r0 []model.SCIMClient
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.SCIMClient, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.SCIMClient); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SCIMClient)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUser provides a mock function with given fields: ctx, client, id
+func (_m *SCIMService) GetUser(ctx context.Context, client model.SCIMClient, id string) (*model.SCIMUser, error) {
+	ret := _m.Called(ctx, client, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUser")
+	}
+
+	var r0 *model.SCIMUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, string) (*model.SCIMUser, error)); ok {
+		return rf(ctx, client, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, string) *model.SCIMUser); ok {
+		r0 = rf(ctx, client, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SCIMUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.SCIMClient, string) error); ok {
+		r1 = rf(ctx, client, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListUsers provides a mock function with given fields: ctx, client, filter, startIndex, count
+func (_m *SCIMService) ListUsers(ctx context.Context, client model.SCIMClient, filter string, startIndex int, count int) (*model.SCIMListResponse, error) {
+	ret := _m.Called(ctx, client, filter, startIndex, count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsers")
+	}
+
+	var r0 *model.SCIMListResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, string, int, int) (*model.SCIMListResponse, error)); ok {
+		return rf(ctx, client, filter, startIndex, count)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, string, int, int) *model.SCIMListResponse); ok {
+		r0 = rf(ctx, client, filter, startIndex, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SCIMListResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.SCIMClient, string, int, int) error); ok {
+		r1 = rf(ctx, client, filter, startIndex, count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchUser provides a mock function with given fields: ctx, client, id, req, meta
+func (_m *SCIMService) PatchUser(ctx context.Context, client model.SCIMClient, id string, req model.SCIMPatchRequest, meta model.RequestMetadata) (*model.SCIMUser, error) {
+	ret := _m.Called(ctx, client, id, req, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchUser")
+	}
+
+	var r0 *model.SCIMUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, string, model.SCIMPatchRequest, model.RequestMetadata) (*model.SCIMUser, error)); ok {
+		return rf(ctx, client, id, req, meta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, string, model.SCIMPatchRequest, model.RequestMetadata) *model.SCIMUser); ok {
+		r0 = rf(ctx, client, id, req, meta)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SCIMUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.SCIMClient, string, model.SCIMPatchRequest, model.RequestMetadata) error); ok {
+		r1 = rf(ctx, client, id, req, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceUser provides a mock function with given fields: ctx, client, id, req, meta
+func (_m *SCIMService) ReplaceUser(ctx context.Context, client model.SCIMClient, id string, req model.SCIMUser, meta model.RequestMetadata) (*model.SCIMUser, error) {
+	ret := _m.Called(ctx, client, id, req, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceUser")
+	}
+
+	var r0 *model.SCIMUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, string, model.SCIMUser, model.RequestMetadata) (*model.SCIMUser, error)); ok {
+		return rf(ctx, client, id, req, meta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.SCIMClient, string, model.SCIMUser, model.RequestMetadata) *model.SCIMUser); ok {
+		r0 = rf(ctx, client, id, req, meta)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SCIMUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.SCIMClient, string, model.SCIMUser, model.RequestMetadata) error); ok {
+		r1 = rf(ctx, client, id, req, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResourceTypes provides a mock function with no fields
+func (_m *SCIMService) ResourceTypes() []model.SCIMResourceType {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResourceTypes")
+	}
+
+	var r0 []model.SCIMResourceType
+	if rf, ok := ret.Get(0).(func() []model.SCIMResourceType); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SCIMResourceType)
+		}
+	}
+
+	return r0
+}
+
+// Schemas provides a mock function with no fields
+func (_m *SCIMService) Schemas() []model.SCIMSchema {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Schemas")
+	}
+
+	var r0 []model.SCIMSchema
+	if rf, ok := ret.Get(0).(func() []model.SCIMSchema); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SCIMSchema)
+		}
+	}
+
+	return r0
+}
+
+// ServiceProviderConfig provides a mock function with no fields
+func (_m *SCIMService) ServiceProviderConfig() model.SCIMServiceProviderConfig {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ServiceProviderConfig")
+	}
+
+	var r0 model.SCIMServiceProviderConfig
+	if rf, ok := ret.Get(0).(func() model.SCIMServiceProviderConfig); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(model.SCIMServiceProviderConfig)
+	}
+
+	return r0
+}
+
+// NewSCIMService creates a new instance of SCIMService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSCIMService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SCIMService {
+	mock := &SCIMService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
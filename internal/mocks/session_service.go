@@ -0,0 +1,145 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// SessionService is an autogenerated mock type for the SessionService type
+type SessionService struct {
+	mock.Mock
+}
+
+// CreateSession provides a mock function with given fields: ctx, user, meta, expiresAt
+func (_m *SessionService) CreateSession(ctx context.Context, user model.User, meta model.RequestMetadata, expiresAt time.Time) (*model.Session, error) {
+	ret := _m.Called(ctx, user, meta, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 *model.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.User, model.RequestMetadata, time.Time) (*model.Session, error)); ok {
+		return rf(ctx, user, meta, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.User, model.RequestMetadata, time.Time) *model.Session); ok {
+		r0 = rf(ctx, user, meta, expiresAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.User, model.RequestMetadata, time.Time) error); ok {
+		r1 = rf(ctx, user, meta, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSessions provides a mock function with given fields: ctx, userID
+func (_m *SessionService) GetSessions(ctx context.Context, userID int) ([]model.Session, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSessions")
+	}
+
+	var r0 []model.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]model.Session, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []model.Session); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAllSessions provides a mock function with given fields: ctx, userID, username, meta
+func (_m *SessionService) RevokeAllSessions(ctx context.Context, userID int, username string, meta model.RequestMetadata) error {
+	ret := _m.Called(ctx, userID, username, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAllSessions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, model.RequestMetadata) error); ok {
+		r0 = rf(ctx, userID, username, meta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeSession provides a mock function with given fields: ctx, userID, username, sessionID, meta
+func (_m *SessionService) RevokeSession(ctx context.Context, userID int, username string, sessionID string, meta model.RequestMetadata) error {
+	ret := _m.Called(ctx, userID, username, sessionID, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string, model.RequestMetadata) error); ok {
+		r0 = rf(ctx, userID, username, sessionID, meta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ValidateSession provides a mock function with given fields: ctx, sessionID, userID
+func (_m *SessionService) ValidateSession(ctx context.Context, sessionID string, userID int) error {
+	ret := _m.Called(ctx, sessionID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) error); ok {
+		r0 = rf(ctx, sessionID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSessionService creates a new instance of SessionService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSessionService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SessionService {
+	mock := &SessionService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
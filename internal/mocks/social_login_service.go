@@ -0,0 +1,187 @@
+// Code generated by mockery v2.42.0 DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	model "exercise-login-back-go/internal/model"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SocialLoginService is an autogenerated mock type for the SocialLoginService type
+type SocialLoginService struct {
+	mock.Mock
+}
+
+// Callback provides a mock function with given fields: ctx, provider, state, browserState, code, meta
+func (_m *SocialLoginService) Callback(ctx context.Context, provider string, state string, browserState string, code string, meta model.RequestMetadata) (*model.SocialLoginResult, error) {
+	ret := _m.Called(ctx, provider, state, browserState, code, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Callback")
+	}
+
+	var r0 *model.SocialLoginResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string, model.RequestMetadata) (*model.SocialLoginResult, error)); ok {
+		return rf(ctx, provider, state, browserState, code, meta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string, model.RequestMetadata) *model.SocialLoginResult); ok {
+		r0 = rf(ctx, provider, state, browserState, code, meta)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SocialLoginResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string, model.RequestMetadata) error); ok {
+		r1 = rf(ctx, provider, state, browserState, code, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetIdentities provides a mock function with given fields: ctx, userID
+func (_m *SocialLoginService) GetIdentities(ctx context.Context, userID int) ([]model.UserIdentity, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdentities")
+	}
+
+	var r0 []model.UserIdentity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]model.UserIdentity, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []model.UserIdentity); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.UserIdentity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Providers provides a mock function with no fields
+func (_m *SocialLoginService) Providers() []string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Providers")
+	}
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
+// StartLink provides a mock function with given fields: ctx, userID, provider
+func (_m *SocialLoginService) StartLink(ctx context.Context, userID int, provider string) (*model.SocialLoginStart, error) {
+	ret := _m.Called(ctx, userID, provider)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartLink")
+	}
+
+	var r0 *model.SocialLoginStart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (*model.SocialLoginStart, error)); ok {
+		return rf(ctx, userID, provider)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) *model.SocialLoginStart); ok {
+		r0 = rf(ctx, userID, provider)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SocialLoginStart)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userID, provider)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StartLogin provides a mock function with given fields: ctx, provider
+func (_m *SocialLoginService) StartLogin(ctx context.Context, provider string) (*model.SocialLoginStart, error) {
+	ret := _m.Called(ctx, provider)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartLogin")
+	}
+
+	var r0 *model.SocialLoginStart
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.SocialLoginStart, error)); ok {
+		return rf(ctx, provider)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.SocialLoginStart); ok {
+		r0 = rf(ctx, provider)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SocialLoginStart)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, provider)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Unlink provides a mock function with given fields: ctx, userID, username, provider, meta
+func (_m *SocialLoginService) Unlink(ctx context.Context, userID int, username string, provider string, meta model.RequestMetadata) error {
+	ret := _m.Called(ctx, userID, username, provider, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unlink")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string, model.RequestMetadata) error); ok {
+		r0 = rf(ctx, userID, username, provider, meta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSocialLoginService creates a new instance of SocialLoginService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSocialLoginService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SocialLoginService {
+	mock := &SocialLoginService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
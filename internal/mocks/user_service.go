@@ -1,8 +1,9 @@
-// Code generated by mockery v2.42.0. DO NOT EDIT.
+// Code generated by mockery v2.42.0 DO NOT EDIT.
 
 package mocks
 
 import (
+	context "context"
 	model "exercise-login-back-go/internal/model"
 
 	mock "github.com/stretchr/testify/mock"
@@ -13,9 +14,37 @@ type UserService struct {
 	mock.Mock
 }
 
-// LoginUser provides a mock function with given fields: emailOrUsername, password
-func (_m *UserService) LoginUser(emailOrUsername string, password string) (string, error) {
-	ret := _m.Called(emailOrUsername, password)
+// LoginExternalUser provides a mock function with given fields: ctx, user, meta
+func (_m *UserService) LoginExternalUser(ctx context.Context, user model.User, meta model.RequestMetadata) (string, error) {
+	ret := _m.Called(ctx, user, meta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoginExternalUser")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.User, model.RequestMetadata) (string, error)); ok {
+		return rf(ctx, user, meta)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.User, model.RequestMetadata) string); ok {
+		r0 = rf(ctx, user, meta)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.User, model.RequestMetadata) error); ok {
+		r1 = rf(ctx, user, meta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoginUser provides a mock function with given fields: ctx, emailOrUsername, password, meta
+func (_m *UserService) LoginUser(ctx context.Context, emailOrUsername string, password string, meta model.RequestMetadata) (string, error) {
+	ret := _m.Called(ctx, emailOrUsername, password, meta)
 
 	if len(ret) == 0 {
 		panic("no return value specified for LoginUser")
@@ -23,17 +52,17 @@ func (_m *UserService) LoginUser(emailOrUsername string, password string) (strin
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
-		return rf(emailOrUsername, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.RequestMetadata) (string, error)); ok {
+		return rf(ctx, emailOrUsername, password, meta)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) string); ok {
-		r0 = rf(emailOrUsername, password)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, model.RequestMetadata) string); ok {
+		r0 = rf(ctx, emailOrUsername, password, meta)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(emailOrUsername, password)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, model.RequestMetadata) error); ok {
+		r1 = rf(ctx, emailOrUsername, password, meta)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -41,17 +70,35 @@ func (_m *UserService) LoginUser(emailOrUsername string, password string) (strin
 	return r0, r1
 }
 
-// RegisterUser provides a mock function with given fields: user
-func (_m *UserService) RegisterUser(user model.UserRegistrationRequest) error {
-	ret := _m.Called(user)
+// RegisterUser provides a mock function with given fields: ctx, user, meta
+func (_m *UserService) RegisterUser(ctx context.Context, user model.UserRegistrationRequest, meta model.RequestMetadata) error {
+	ret := _m.Called(ctx, user, meta)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RegisterUser")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(model.UserRegistrationRequest) error); ok {
-		r0 = rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserRegistrationRequest, model.RequestMetadata) error); ok {
+		r0 = rf(ctx, user, meta)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateLocale provides a mock function with given fields: ctx, userID, locale
+func (_m *UserService) UpdateLocale(ctx context.Context, userID int, locale string) error {
+	ret := _m.Called(ctx, userID, locale)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateLocale")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, userID, locale)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -59,6 +106,36 @@ func (_m *UserService) RegisterUser(user model.UserRegistrationRequest) error {
 	return r0
 }
 
+// ValidateToken provides a mock function with given fields: ctx, tokenString
+func (_m *UserService) ValidateToken(ctx context.Context, tokenString string) (*model.Claims, error) {
+	ret := _m.Called(ctx, tokenString)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateToken")
+	}
+
+	var r0 *model.Claims
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Claims, error)); ok {
+		return rf(ctx, tokenString)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Claims); ok {
+		r0 = rf(ctx, tokenString)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Claims)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenString)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewUserService creates a new instance of UserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserService(t interface {
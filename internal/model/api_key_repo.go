@@ -0,0 +1,15 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key APIKey) (int, error)
+	GetAPIKeyByID(ctx context.Context, id int) (*APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	GetAPIKeysByUserID(ctx context.Context, userID int) ([]APIKey, error)
+	UpdateAPIKeyLastUsed(ctx context.Context, id int, lastUsedAt time.Time) error
+	RevokeAPIKey(ctx context.Context, id int, revokedAt time.Time) error
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Audit event types recorded for authentication related actions.
+const (
+	AuditEventRegistration    = "registration"
+	AuditEventLogin           = "login"
+	AuditEventPasswordChange  = "password_change"
+	AuditEventTokenRevocation = "token_revocation"
+	AuditEventAPIKeyCreation  = "api_key_creation"
+	AuditEventIdentityLink    = "identity_link"
+	AuditEventIdentityUnlink  = "identity_unlink"
+	AuditEventUserUpdate      = "user_update"
+	AuditEventUserDeletion    = "user_deletion"
+)
+
+// Audit event outcomes.
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// RequestMetadata describes the client that originated a request.
+type RequestMetadata struct {
+	IPAddress string
+	UserAgent string
+}
+
+type AuditEvent struct {
+	ID        int       `json:"id"`
+	EventType string    `json:"eventType"`
+	UserID    int       `json:"userId,omitempty"`
+	Actor     string    `json:"actor"`
+	IPAddress string    `json:"ipAddress"`
+	UserAgent string    `json:"userAgent"`
+	Outcome   string    `json:"outcome"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AuditEventFilter narrows down the audit events returned by a query.
+// Zero values mean "no restriction" for that field.
+type AuditEventFilter struct {
+	UserID int
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
@@ -0,0 +1,8 @@
+package model
+
+import "context"
+
+type AuditRepository interface {
+	CreateAuditEvent(ctx context.Context, event AuditEvent) error
+	GetAuditEvents(ctx context.Context, filter AuditEventFilter) ([]AuditEvent, error)
+}
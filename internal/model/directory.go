@@ -0,0 +1,16 @@
+package model
+
+// DirectoryEntry is a user found in a corporate directory, with the
+// attributes mapped onto the fields of a local user.
+type DirectoryEntry struct {
+	DN       string
+	Username string
+	Email    string
+	Phone    string
+}
+
+// Directory verifies credentials against a corporate directory such as LDAP
+// or Active Directory. It returns nil when the credentials are invalid.
+type Directory interface {
+	Authenticate(login, password string) (*DirectoryEntry, error)
+}
@@ -0,0 +1,25 @@
+package model
+
+// Statuses of the server and its dependencies in a health report
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+	HealthStatusDraining    = "draining"
+)
+
+// HealthCheck is the status of a dependency of the server, such as the database.
+type HealthCheck struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// HealthReport tells whether the server can take traffic and why.
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+// Ready reports whether the server can take traffic.
+func (r HealthReport) Ready() bool {
+	return r.Status == HealthStatusOK
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// ExternalIdentity is the identity asserted by an upstream identity provider
+// after a successful sign in.
+type ExternalIdentity struct {
+	Provider          string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	Phone             string
+}
+
+// IdentityProvider is an upstream identity provider users can sign in with.
+type IdentityProvider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
+
+// UserIdentity links a local user to an account of an upstream identity provider.
+type UserIdentity struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SocialLoginState is a pending sign in with an upstream identity provider.
+// UserID is set when the flow links the provider to an existing user. For
+// SAML sign ins, Nonce holds the ID of the authentication request.
+type SocialLoginState struct {
+	StateHash    string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	UserID       int
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// SocialLoginStart is a sign in started with an upstream provider: the URL
+// the browser must visit and the state the provider sends back, which the
+// browser must also present on the callback until ExpiresAt.
+type SocialLoginStart struct {
+	AuthorizationURL string
+	State            string
+	ExpiresAt        time.Time
+}
+
+// SocialLoginResult is the outcome of the callback of an upstream sign in:
+// a token for a login, or the linked provider when linking an account.
+type SocialLoginResult struct {
+	Token          string `json:"token,omitempty"`
+	LinkedProvider string `json:"linkedProvider,omitempty"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "context"
+
+type IdentityRepository interface {
+	CreateUserIdentity(ctx context.Context, identity UserIdentity) error
+	GetUserIdentity(ctx context.Context, provider, subject string) (*UserIdentity, error)
+	GetUserIdentitiesByUserID(ctx context.Context, userID int) ([]UserIdentity, error)
+	DeleteUserIdentity(ctx context.Context, userID int, provider string) error
+	CreateSocialLoginState(ctx context.Context, state SocialLoginState) error
+	ConsumeSocialLoginState(ctx context.Context, stateHash string) (*SocialLoginState, error)
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Notification is a message sent to a user outside of the API.
+type Notification struct {
+	To        string    `json:"to"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Notifier delivers notifications to users.
+type Notifier interface {
+	Notify(notification Notification) error
+}
@@ -0,0 +1,155 @@
+package model
+
+import "time"
+
+// OAuth 2.0 grant types supported by the authorization server.
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// OAuth 2.0 token types stored by the authorization server.
+const (
+	OAuthTokenTypeAccess  = "access_token"
+	OAuthTokenTypeRefresh = "refresh_token"
+)
+
+// OAuthClient is an application registered to obtain tokens from the
+// authorization server. Public clients have no secret and must use PKCE.
+type OAuthClient struct {
+	ID           int      `json:"-"`
+	ClientID     string   `json:"clientId"`
+	SecretHash   string   `json:"-"`
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirectUris"`
+	// PostLogoutRedirectURIs are the URIs the user may be sent to after an
+	// RP-initiated logout.
+	PostLogoutRedirectURIs []string  `json:"postLogoutRedirectUris"`
+	GrantTypes             []string  `json:"grantTypes"`
+	Scopes                 []string  `json:"scopes"`
+	Public                 bool      `json:"public"`
+	CreatedAt              time.Time `json:"createdAt"`
+}
+
+// AllowsGrant reports whether the client may use the grant type.
+func (c OAuthClient) AllowsGrant(grantType string) bool {
+	return containsString(c.GrantTypes, grantType)
+}
+
+// AllowsRedirectURI reports whether the redirect URI was registered by the client.
+func (c OAuthClient) AllowsRedirectURI(redirectURI string) bool {
+	return containsString(c.RedirectURIs, redirectURI)
+}
+
+// AllowsPostLogoutRedirectURI reports whether the URI was registered by the
+// client as a post logout redirect URI.
+func (c OAuthClient) AllowsPostLogoutRedirectURI(redirectURI string) bool {
+	return containsString(c.PostLogoutRedirectURIs, redirectURI)
+}
+
+// OAuthAuthorizationCode is a single-use code issued by /oauth/authorize.
+// Only the hash of the code is stored. The nonce, session and authentication
+// time are carried over to the ID token of OpenID Connect requests.
+// RedirectURIExplicit records that the authorization request included
+// redirect_uri, in which case the token request must repeat it.
+type OAuthAuthorizationCode struct {
+	CodeHash            string
+	ClientID            string
+	UserID              int
+	RedirectURI         string
+	RedirectURIExplicit bool
+	Scopes              []string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
+	SessionID           string
+	AuthTime            time.Time
+	ExpiresAt           time.Time
+	CreatedAt           time.Time
+}
+
+// OAuthToken is an opaque access or refresh token. Tokens issued from the
+// same grant share a GrantID so they can be revoked together.
+type OAuthToken struct {
+	TokenHash string
+	TokenType string
+	GrantID   string
+	ClientID  string
+	UserID    int
+	Scopes    []string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// IsActive reports whether the token can still be used at the given time.
+func (t OAuthToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+type OAuthClientRegistrationRequest struct {
+	Name                   string   `json:"name"`
+	RedirectURIs           []string `json:"redirectUris"`
+	PostLogoutRedirectURIs []string `json:"postLogoutRedirectUris"`
+	GrantTypes             []string `json:"grantTypes"`
+	Scopes                 []string `json:"scopes"`
+	Public                 bool     `json:"public"`
+}
+
+// AuthorizationRequest holds the parameters of an /oauth/authorize request.
+type AuthorizationRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Nonce               string `json:"nonce"`
+}
+
+// TokenRequest holds the parameters of an /oauth/token request.
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
+
+// TokenResponse is the successful response of the token endpoint (RFC 6749, section 5.1).
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// IntrospectionResponse is the response of the introspection endpoint (RFC 7662).
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type OAuthRepository interface {
+	CreateOAuthClient(ctx context.Context, client OAuthClient) (int, error)
+	GetOAuthClientByClientID(ctx context.Context, clientID string) (*OAuthClient, error)
+	GetOAuthClients(ctx context.Context) ([]OAuthClient, error)
+	DeleteOAuthClient(ctx context.Context, clientID string) error
+	CreateAuthorizationCode(ctx context.Context, code OAuthAuthorizationCode) error
+	ConsumeAuthorizationCode(ctx context.Context, codeHash string, usedAt time.Time) (*OAuthAuthorizationCode, error)
+	CreateOAuthToken(ctx context.Context, token OAuthToken) error
+	GetOAuthTokenByHash(ctx context.Context, tokenHash string) (*OAuthToken, error)
+	RevokeOAuthToken(ctx context.Context, tokenHash string, revokedAt time.Time) (bool, error)
+	RevokeOAuthGrant(ctx context.Context, grantID string, revokedAt time.Time) error
+}
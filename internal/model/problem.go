@@ -0,0 +1,29 @@
+package model
+
+// Problem is an error response in the format of RFC 7807, problem details
+// for HTTP APIs. Code, MessageKey, MessageParams and RequestID are extension
+// members; MessageKey and MessageParams identify Detail in the message
+// catalog so clients can localize it themselves.
+type Problem struct {
+	Type          string            `json:"type"`
+	Title         string            `json:"title"`
+	Status        int               `json:"status"`
+	Detail        string            `json:"detail,omitempty"`
+	Instance      string            `json:"instance,omitempty"`
+	Code          string            `json:"code,omitempty"`
+	MessageKey    string            `json:"messageKey,omitempty"`
+	MessageParams map[string]string `json:"messageParams,omitempty"`
+	RequestID     string            `json:"requestId,omitempty"`
+	InvalidParams []InvalidParam    `json:"invalid-params,omitempty"`
+}
+
+// InvalidParam describes why a request parameter was rejected. Code is a
+// stable identifier of the rule that failed; Key and Params identify Reason
+// in the message catalog.
+type InvalidParam struct {
+	Name   string            `json:"name"`
+	Reason string            `json:"reason"`
+	Code   string            `json:"code,omitempty"`
+	Key    string            `json:"key,omitempty"`
+	Params map[string]string `json:"params,omitempty"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// SAMLAuthnRequest is an authentication request ready to be delivered to a
+// SAML identity provider, either as a redirect URL (HTTP-Redirect binding) or
+// as a self-submitting HTML form (HTTP-POST binding). RelayState comes back
+// with the response, and the browser must also present it on the assertion
+// consumer service until ExpiresAt.
+type SAMLAuthnRequest struct {
+	ID          string
+	RedirectURL string
+	PostForm    []byte
+	RelayState  string
+	ExpiresAt   time.Time
+}
+
+// SAMLIdentityProvider is the SAML 2.0 identity provider of an enterprise
+// tenant. Domains are the email domains the tenant owns.
+type SAMLIdentityProvider interface {
+	Name() string
+	Domains() []string
+	Metadata() ([]byte, error)
+	MakeAuthnRequest(relayState string) (*SAMLAuthnRequest, error)
+	ParseResponse(samlResponse, requestID string) (*ExternalIdentity, error)
+}
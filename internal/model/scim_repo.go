@@ -0,0 +1,11 @@
+package model
+
+import "context"
+
+type SCIMRepository interface {
+	CreateSCIMClient(ctx context.Context, client SCIMClient) (int, error)
+	GetSCIMClientByID(ctx context.Context, id int) (*SCIMClient, error)
+	GetSCIMClientByTokenHash(ctx context.Context, tokenHash string) (*SCIMClient, error)
+	GetSCIMClients(ctx context.Context) ([]SCIMClient, error)
+	DeleteSCIMClient(ctx context.Context, id int) error
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Session represents a token issued to a user on a specific device.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     int        `json:"-"`
+	Device     string     `json:"device"`
+	IPAddress  string     `json:"ipAddress"`
+	UserAgent  string     `json:"userAgent"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// IsActive reports whether the session can still be used at the given time.
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RevokeLink is a "this wasn't me" link sent in a login alert. Its ID is the
+// jti claim of the link token; each link can only be used once.
+type RevokeLink struct {
+	ID        string
+	UserID    int
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session Session) error
+	GetSessionByID(ctx context.Context, id string) (*Session, error)
+	GetSessionsByUserID(ctx context.Context, userID int, limit int) ([]Session, error)
+	UpdateSessionLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error
+	RevokeSession(ctx context.Context, id string, revokedAt time.Time) error
+	RevokeUserSessions(ctx context.Context, userID int, revokedAt time.Time) error
+	CreateRevokeLink(ctx context.Context, link RevokeLink) error
+	ConsumeRevokeLink(ctx context.Context, id string, usedAt time.Time) (*RevokeLink, error)
+}
@@ -8,21 +8,47 @@ type User struct {
 	Email    string
 	Phone    string
 	Password string
+	// Disabled users cannot sign in; provisioning systems use it to deactivate accounts.
+	Disabled bool
+	// Locale is the language the user prefers for messages; empty follows
+	// the Accept-Language header of each request.
+	Locale string
+	// SCIMClientID is the provisioning client that created the user, the
+	// only one allowed to manage it; zero for users created otherwise.
+	SCIMClientID int
+}
+
+// UserFilter narrows down the users returned by a query. Zero values mean
+// "no restriction" for that field.
+type UserFilter struct {
+	Username     string
+	Email        string
+	SCIMClientID int
+	Offset       int
+	Limit        int
 }
 
 type Claims struct {
+	UserID   int    `json:"userId"`
 	Username string `json:"username"`
+	Locale   string `json:"locale,omitempty"`
 	jwt.StandardClaims
 }
 
+// UserRegistrationRequest is validated with the rules of its validate tags;
+// see the validation package.
 type UserRegistrationRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required,max=255"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Phone    string `json:"phone" validate:"required,phone"`
+	Password string `json:"password" validate:"required,password"`
+}
+
+type UserLocaleRequest struct {
+	Locale string `json:"locale"`
 }
 
 type UserLoginRequest struct {
-	EmailOrUsername string `json:"emailOrUsername"`
-	Password        string `json:"password"`
+	EmailOrUsername string `json:"emailOrUsername" validate:"required"`
+	Password        string `json:"password" validate:"required"`
 }
@@ -1,7 +1,21 @@
 package model
 
+import (
+	"context"
+	"errors"
+)
+
+// ErrDuplicateUser is returned by CreateUser and UpdateUser when the email or
+// phone of the user is taken by another user.
+var ErrDuplicateUser = errors.New("duplicate user")
+
 type UserRepository interface {
-	CreateUser(user User) error
-	GetUserByEmailOrUsername(emailOrUsername string) (*User, error)
-	GetUserByEmailOrPhone(email, Phone string) (*User, error)
+	CreateUser(ctx context.Context, user User) error
+	GetUserByID(ctx context.Context, id int) (*User, error)
+	GetUserByEmailOrUsername(ctx context.Context, emailOrUsername string) (*User, error)
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	GetUserByEmailOrPhone(ctx context.Context, email, Phone string) (*User, error)
+	GetUsers(ctx context.Context, filter UserFilter) ([]User, int, error)
+	UpdateUser(ctx context.Context, user User) error
+	DeleteUser(ctx context.Context, id int) error
 }
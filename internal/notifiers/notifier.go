@@ -0,0 +1,68 @@
+package notifiers
+
+import (
+	"encoding/json"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// NewNotifier returns the notifier configured by name ("log" or "file").
+func NewNotifier(kind, filePath string) (model.Notifier, error) {
+	switch kind {
+	case "", "log":
+		return NewLogNotifier(), nil
+	case "file":
+		if filePath == "" {
+			return nil, fmt.Errorf("the file notifier requires a file path")
+		}
+		return NewFileNotifier(filePath), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", kind)
+	}
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier creates a notifier that writes notifications to the standard logger.
+func NewLogNotifier() *logNotifier {
+	return &logNotifier{}
+}
+
+// Notify writes the notification to the log.
+func (n *logNotifier) Notify(notification model.Notification) error {
+	slog.Info("Notification", "to", notification.To, "subject", notification.Subject, "body", notification.Body)
+	return nil
+}
+
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier creates a notifier that appends notifications as JSON lines to a file.
+func NewFileNotifier(path string) *fileNotifier {
+	return &fileNotifier{path: path}
+}
+
+// Notify appends the notification to the file.
+func (n *fileNotifier) Notify(notification model.Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
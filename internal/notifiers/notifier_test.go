@@ -0,0 +1,43 @@
+package notifiers_test
+
+import (
+	"encoding/json"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/notifiers"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	notifier := notifiers.NewFileNotifier(path)
+
+	assert.NoError(t, notifier.Notify(model.Notification{To: "a@example.com", Subject: "uno"}))
+	assert.NoError(t, notifier.Notify(model.Notification{To: "b@example.com", Subject: "dos"}))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 2)
+
+	var notification model.Notification
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &notification))
+	assert.Equal(t, "b@example.com", notification.To)
+	assert.Equal(t, "dos", notification.Subject)
+}
+
+func TestNewNotifier(t *testing.T) {
+	_, err := notifiers.NewNotifier("log", "")
+	assert.NoError(t, err)
+
+	_, err = notifiers.NewNotifier("file", "")
+	assert.Error(t, err)
+
+	_, err = notifiers.NewNotifier("carrier-pigeon", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates and returns a new instance of the API key repository.
+func NewAPIKeyRepository(db *sql.DB) *apiKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// CreateAPIKey stores a new API key and returns its identifier.
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, key model.APIKey) (int, error) {
+	var id int
+	query := "EXEC CreateAPIKey @UserID = @p1, @Name = @p2, @Prefix = @p3, @SecretHash = @p4, @Scopes = @p5, @ExpiresAt = @p6, @CreatedAt = @p7"
+	err := r.db.QueryRowContext(ctx, query,
+		sql.Named("p1", key.UserID),
+		sql.Named("p2", key.Name),
+		sql.Named("p3", key.Prefix),
+		sql.Named("p4", key.SecretHash),
+		sql.Named("p5", strings.Join(key.Scopes, ",")),
+		sql.Named("p6", nullableTimePtr(key.ExpiresAt)),
+		sql.Named("p7", key.CreatedAt)).Scan(&id)
+	if err != nil {
+		return 0, queryError(ctx, query, err)
+	}
+	return id, nil
+}
+
+// GetAPIKeyByID retrieves an API key by its identifier
+func (r *apiKeyRepository) GetAPIKeyByID(ctx context.Context, id int) (*model.APIKey, error) {
+	query := "EXEC GetAPIKeyByID @ID = @p1"
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, sql.Named("p1", id)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No key was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return key, nil
+}
+
+// GetAPIKeyByPrefix retrieves an API key by its visible prefix
+func (r *apiKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	query := "EXEC GetAPIKeyByPrefix @Prefix = @p1"
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, sql.Named("p1", prefix)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No key was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return key, nil
+}
+
+// GetAPIKeysByUserID retrieves every API key of a user, newest first
+func (r *apiKeyRepository) GetAPIKeysByUserID(ctx context.Context, userID int) ([]model.APIKey, error) {
+	query := "EXEC GetAPIKeysByUserID @UserID = @p1"
+	rows, err := r.db.QueryContext(ctx, query, sql.Named("p1", userID))
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return keys, nil
+}
+
+// UpdateAPIKeyLastUsed records the last time an API key was used.
+func (r *apiKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, id int, lastUsedAt time.Time) error {
+	query := "EXEC UpdateAPIKeyLastUsed @ID = @p1, @LastUsedAt = @p2"
+	_, err := r.db.ExecContext(ctx, query, sql.Named("p1", id), sql.Named("p2", lastUsedAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// RevokeAPIKey marks an API key as revoked.
+func (r *apiKeyRepository) RevokeAPIKey(ctx context.Context, id int, revokedAt time.Time) error {
+	query := "EXEC RevokeAPIKey @ID = @p1, @RevokedAt = @p2"
+	_, err := r.db.ExecContext(ctx, query, sql.Named("p1", id), sql.Named("p2", revokedAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// scanAPIKey reads an API key from a row with the columns returned by the API key procedures
+func scanAPIKey(row rowScanner) (*model.APIKey, error) {
+	var key model.APIKey
+	var scopes string
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.SecretHash, &scopes,
+		&expiresAt, &lastUsedAt, &key.CreatedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	key.Scopes = splitScopes(scopes)
+	key.ExpiresAt = timePtr(expiresAt)
+	key.LastUsedAt = timePtr(lastUsedAt)
+	key.RevokedAt = timePtr(revokedAt)
+	return &key, nil
+}
+
+// splitScopes parses the comma separated list of scopes stored in the database
+func splitScopes(scopes string) []string {
+	result := []string{}
+	for _, scope := range strings.Split(scopes, ",") {
+		if scope != "" {
+			result = append(result, scope)
+		}
+	}
+	return result
+}
+
+// NewAPIKeyRepositoryForDriver creates the API key repository matching the
+// database driver.
+func NewAPIKeyRepositoryForDriver(driver string, db *sql.DB) (model.APIKeyRepository, error) {
+	switch driver {
+	case "sqlserver", "mssql":
+		return NewAPIKeyRepository(db), nil
+	case "postgres":
+		return NewPostgresAPIKeyRepository(db), nil
+	case "sqlite":
+		return NewSQLiteAPIKeyRepository(db), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
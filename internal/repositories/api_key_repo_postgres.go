@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"strings"
+	"time"
+)
+
+// apiKeyColumns are the columns read by scanAPIKey
+const apiKeyColumns = "id, user_id, name, prefix, secret_hash, scopes, expires_at, last_used_at, created_at, revoked_at"
+
+type postgresAPIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAPIKeyRepository creates an API key repository for PostgreSQL.
+func NewPostgresAPIKeyRepository(db *sql.DB) *postgresAPIKeyRepository {
+	return &postgresAPIKeyRepository{db: db}
+}
+
+// CreateAPIKey stores a new API key and returns its identifier.
+func (r *postgresAPIKeyRepository) CreateAPIKey(ctx context.Context, key model.APIKey) (int, error) {
+	var id int
+	query := "INSERT INTO api_keys (user_id, name, prefix, secret_hash, scopes, expires_at, created_at)" +
+		" VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+	err := r.db.QueryRowContext(ctx, query, key.UserID, key.Name, key.Prefix, key.SecretHash,
+		strings.Join(key.Scopes, ","), nullableTimePtr(key.ExpiresAt), key.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, queryError(ctx, query, err)
+	}
+	return id, nil
+}
+
+// GetAPIKeyByID retrieves an API key by its identifier
+func (r *postgresAPIKeyRepository) GetAPIKeyByID(ctx context.Context, id int) (*model.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE id = $1"
+	return r.getAPIKey(ctx, query, id)
+}
+
+// GetAPIKeyByPrefix retrieves an API key by its visible prefix
+func (r *postgresAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE prefix = $1"
+	return r.getAPIKey(ctx, query, prefix)
+}
+
+// GetAPIKeysByUserID retrieves every API key of a user, newest first
+func (r *postgresAPIKeyRepository) GetAPIKeysByUserID(ctx context.Context, userID int) ([]model.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC"
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return keys, nil
+}
+
+// UpdateAPIKeyLastUsed records the last time an API key was used.
+func (r *postgresAPIKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, id int, lastUsedAt time.Time) error {
+	query := "UPDATE api_keys SET last_used_at = $2 WHERE id = $1"
+	_, err := r.db.ExecContext(ctx, query, id, lastUsedAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// RevokeAPIKey marks an API key as revoked.
+func (r *postgresAPIKeyRepository) RevokeAPIKey(ctx context.Context, id int, revokedAt time.Time) error {
+	query := "UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, id, revokedAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// getAPIKey runs a query returning at most one API key
+func (r *postgresAPIKeyRepository) getAPIKey(ctx context.Context, query string, args ...interface{}) (*model.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No key was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return key, nil
+}
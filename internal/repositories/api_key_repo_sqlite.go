@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"strings"
+	"time"
+)
+
+type sqliteAPIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAPIKeyRepository creates an API key repository for an embedded
+// SQLite database.
+func NewSQLiteAPIKeyRepository(db *sql.DB) *sqliteAPIKeyRepository {
+	return &sqliteAPIKeyRepository{db: db}
+}
+
+// CreateAPIKey stores a new API key and returns its identifier.
+func (r *sqliteAPIKeyRepository) CreateAPIKey(ctx context.Context, key model.APIKey) (int, error) {
+	var id int
+	query := "INSERT INTO api_keys (user_id, name, prefix, secret_hash, scopes, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id"
+	err := r.db.QueryRowContext(ctx, query, key.UserID, key.Name, key.Prefix, key.SecretHash,
+		strings.Join(key.Scopes, ","), sqliteTimePtr(key.ExpiresAt), sqliteTime(key.CreatedAt)).Scan(&id)
+	if err != nil {
+		return 0, queryError(ctx, query, err)
+	}
+	return id, nil
+}
+
+// GetAPIKeyByID retrieves an API key by its identifier
+func (r *sqliteAPIKeyRepository) GetAPIKeyByID(ctx context.Context, id int) (*model.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE id = ?"
+	return r.getAPIKey(ctx, query, id)
+}
+
+// GetAPIKeyByPrefix retrieves an API key by its visible prefix
+func (r *sqliteAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE prefix = ?"
+	return r.getAPIKey(ctx, query, prefix)
+}
+
+// GetAPIKeysByUserID retrieves every API key of a user, newest first
+func (r *sqliteAPIKeyRepository) GetAPIKeysByUserID(ctx context.Context, userID int) ([]model.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE user_id = ? ORDER BY created_at DESC"
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return keys, nil
+}
+
+// UpdateAPIKeyLastUsed records the last time an API key was used.
+func (r *sqliteAPIKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, id int, lastUsedAt time.Time) error {
+	query := "UPDATE api_keys SET last_used_at = ? WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, sqliteTime(lastUsedAt), id)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// RevokeAPIKey marks an API key as revoked.
+func (r *sqliteAPIKeyRepository) RevokeAPIKey(ctx context.Context, id int, revokedAt time.Time) error {
+	query := "UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, sqliteTime(revokedAt), id)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// getAPIKey runs a query returning at most one API key
+func (r *sqliteAPIKeyRepository) getAPIKey(ctx context.Context, query string, args ...interface{}) (*model.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No key was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return key, nil
+}
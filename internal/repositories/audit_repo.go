@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+)
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates and returns a new instance of the audit repository.
+func NewAuditRepository(db *sql.DB) *auditRepository {
+	return &auditRepository{db: db}
+}
+
+// CreateAuditEvent stores a new audit event in the database.
+func (r *auditRepository) CreateAuditEvent(ctx context.Context, event model.AuditEvent) error {
+	query := "EXEC CreateAuditEvent @EventType = @p1, @UserID = @p2, @Actor = @p3, @IPAddress = @p4, @UserAgent = @p5, @Outcome = @p6, @Detail = @p7, @CreatedAt = @p8"
+	_, err := r.db.ExecContext(ctx, query,
+		sql.Named("p1", event.EventType),
+		sql.Named("p2", nullableInt(event.UserID)),
+		sql.Named("p3", event.Actor),
+		sql.Named("p4", event.IPAddress),
+		sql.Named("p5", event.UserAgent),
+		sql.Named("p6", event.Outcome),
+		sql.Named("p7", event.Detail),
+		sql.Named("p8", event.CreatedAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// GetAuditEvents retrieves the audit events matching the filter, newest first.
+func (r *auditRepository) GetAuditEvents(ctx context.Context, filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	query := "EXEC GetAuditEvents @UserID = @p1, @From = @p2, @To = @p3, @Limit = @p4"
+	rows, err := r.db.QueryContext(ctx, query,
+		sql.Named("p1", nullableInt(filter.UserID)),
+		sql.Named("p2", nullableTime(filter.From)),
+		sql.Named("p3", nullableTime(filter.To)),
+		sql.Named("p4", filter.Limit))
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	return scanAuditEvents(ctx, query, rows)
+}
+
+// scanAuditEvents reads every audit event of rows with the columns returned by GetAuditEvents
+func scanAuditEvents(ctx context.Context, query string, rows *sql.Rows) ([]model.AuditEvent, error) {
+	events := []model.AuditEvent{}
+	for rows.Next() {
+		var event model.AuditEvent
+		var userID sql.NullInt64
+		err := rows.Scan(&event.ID, &event.EventType, &userID, &event.Actor, &event.IPAddress,
+			&event.UserAgent, &event.Outcome, &event.Detail, &event.CreatedAt)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		event.UserID = int(userID.Int64)
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return events, nil
+}
+
+// NewAuditRepositoryForDriver creates the audit repository matching the
+// database driver.
+func NewAuditRepositoryForDriver(driver string, db *sql.DB) (model.AuditRepository, error) {
+	switch driver {
+	case "sqlserver", "mssql":
+		return NewAuditRepository(db), nil
+	case "postgres":
+		return NewPostgresAuditRepository(db), nil
+	case "sqlite":
+		return NewSQLiteAuditRepository(db), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
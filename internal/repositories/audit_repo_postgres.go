@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+)
+
+// auditEventColumns are the columns read by scanAuditEvents
+const auditEventColumns = "id, event_type, user_id, actor, ip_address, user_agent, outcome, detail, created_at"
+
+type postgresAuditRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAuditRepository creates an audit repository for PostgreSQL.
+func NewPostgresAuditRepository(db *sql.DB) *postgresAuditRepository {
+	return &postgresAuditRepository{db: db}
+}
+
+// CreateAuditEvent stores a new audit event in the database. Like the SQL
+// Server procedure, it truncates the free text columns to their size.
+func (r *postgresAuditRepository) CreateAuditEvent(ctx context.Context, event model.AuditEvent) error {
+	query := "INSERT INTO audit_events (event_type, user_id, actor, ip_address, user_agent, outcome, detail, created_at)" +
+		" VALUES ($1, $2, LEFT($3, 255), LEFT($4, 45), LEFT($5, 512), $6, LEFT($7, 1000), $8)"
+	_, err := r.db.ExecContext(ctx, query, event.EventType, nullableInt(event.UserID), event.Actor, event.IPAddress,
+		event.UserAgent, event.Outcome, event.Detail, event.CreatedAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// GetAuditEvents retrieves the audit events matching the filter, newest first.
+func (r *postgresAuditRepository) GetAuditEvents(ctx context.Context, filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	query := "SELECT " + auditEventColumns + " FROM audit_events" +
+		" WHERE ($1::integer IS NULL OR user_id = $1) AND ($2::timestamptz IS NULL OR created_at >= $2) AND ($3::timestamptz IS NULL OR created_at < $3)" +
+		" ORDER BY created_at DESC LIMIT $4"
+	rows, err := r.db.QueryContext(ctx, query, nullableInt(filter.UserID), nullableTime(filter.From), nullableTime(filter.To), filter.Limit)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	return scanAuditEvents(ctx, query, rows)
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+)
+
+type sqliteAuditRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAuditRepository creates an audit repository for an embedded SQLite
+// database.
+func NewSQLiteAuditRepository(db *sql.DB) *sqliteAuditRepository {
+	return &sqliteAuditRepository{db: db}
+}
+
+// CreateAuditEvent stores a new audit event in the database.
+func (r *sqliteAuditRepository) CreateAuditEvent(ctx context.Context, event model.AuditEvent) error {
+	query := "INSERT INTO audit_events (event_type, user_id, actor, ip_address, user_agent, outcome, detail, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+	_, err := r.db.ExecContext(ctx, query, event.EventType, nullableInt(event.UserID), event.Actor, event.IPAddress,
+		event.UserAgent, event.Outcome, event.Detail, sqliteTime(event.CreatedAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// GetAuditEvents retrieves the audit events matching the filter, newest first.
+func (r *sqliteAuditRepository) GetAuditEvents(ctx context.Context, filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	query := "SELECT " + auditEventColumns + " FROM audit_events" +
+		" WHERE (?1 IS NULL OR user_id = ?1) AND (?2 IS NULL OR created_at >= ?2) AND (?3 IS NULL OR created_at < ?3)" +
+		" ORDER BY created_at DESC LIMIT ?4"
+	rows, err := r.db.QueryContext(ctx, query,
+		nullableInt(filter.UserID),
+		nullableTime(sqliteTime(filter.From)),
+		nullableTime(sqliteTime(filter.To)),
+		filter.Limit)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	return scanAuditEvents(ctx, query, rows)
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"exercise-login-back-go/internal/model"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// userWriteError logs a failed write to the users table and maps a violation
+// of its unique constraints to model.ErrDuplicateUser
+func userWriteError(ctx context.Context, query string, err error) error {
+	err = queryError(ctx, query, err)
+	if isUniqueViolation(err) {
+		return model.ErrDuplicateUser
+	}
+	return err
+}
+
+// isUniqueViolation reports whether err is a unique constraint or unique
+// index violation of any of the supported database drivers
+func isUniqueViolation(err error) bool {
+	var mssqlErr mssql.Error
+	if errors.As(err, &mssqlErr) {
+		return mssqlErr.Number == 2627 || mssqlErr.Number == 2601
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+)
+
+type identityRepository struct {
+	db *sql.DB
+}
+
+// NewIdentityRepository creates and returns a new instance of the identity repository.
+func NewIdentityRepository(db *sql.DB) *identityRepository {
+	return &identityRepository{db: db}
+}
+
+// CreateUserIdentity links a user to an account of an upstream identity provider.
+func (r *identityRepository) CreateUserIdentity(ctx context.Context, identity model.UserIdentity) error {
+	query := "EXEC CreateUserIdentity @UserID = @p1, @Provider = @p2, @Subject = @p3, @Email = @p4, @CreatedAt = @p5"
+	_, err := r.db.ExecContext(ctx, query,
+		sql.Named("p1", identity.UserID),
+		sql.Named("p2", identity.Provider),
+		sql.Named("p3", identity.Subject),
+		sql.Named("p4", identity.Email),
+		sql.Named("p5", identity.CreatedAt))
+	return queryError(ctx, query, err)
+}
+
+// GetUserIdentity retrieves the link of an upstream account by its provider and subject
+func (r *identityRepository) GetUserIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	query := "EXEC GetUserIdentity @Provider = @p1, @Subject = @p2"
+	identity, err := scanUserIdentity(r.db.QueryRowContext(ctx, query, sql.Named("p1", provider), sql.Named("p2", subject)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No identity was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return identity, nil
+}
+
+// GetUserIdentitiesByUserID retrieves the upstream accounts linked to a user
+func (r *identityRepository) GetUserIdentitiesByUserID(ctx context.Context, userID int) ([]model.UserIdentity, error) {
+	query := "EXEC GetUserIdentitiesByUserID @UserID = @p1"
+	rows, err := r.db.QueryContext(ctx, query, sql.Named("p1", userID))
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	identities := []model.UserIdentity{}
+	for rows.Next() {
+		identity, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		identities = append(identities, *identity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return identities, nil
+}
+
+// DeleteUserIdentity removes the link between a user and a provider.
+func (r *identityRepository) DeleteUserIdentity(ctx context.Context, userID int, provider string) error {
+	query := "EXEC DeleteUserIdentity @UserID = @p1, @Provider = @p2"
+	_, err := r.db.ExecContext(ctx, query, sql.Named("p1", userID), sql.Named("p2", provider))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// CreateSocialLoginState stores a pending sign in with an upstream provider.
+func (r *identityRepository) CreateSocialLoginState(ctx context.Context, state model.SocialLoginState) error {
+	query := "EXEC CreateSocialLoginState @StateHash = @p1, @Provider = @p2, @Nonce = @p3, @CodeVerifier = @p4, @UserID = @p5, @ExpiresAt = @p6, @CreatedAt = @p7"
+	_, err := r.db.ExecContext(ctx, query,
+		sql.Named("p1", state.StateHash),
+		sql.Named("p2", state.Provider),
+		sql.Named("p3", state.Nonce),
+		sql.Named("p4", state.CodeVerifier),
+		sql.Named("p5", nullableInt(state.UserID)),
+		sql.Named("p6", state.ExpiresAt),
+		sql.Named("p7", state.CreatedAt))
+	return queryError(ctx, query, err)
+}
+
+// ConsumeSocialLoginState deletes a pending sign in and returns it, so each
+// state can only be used once. It returns nil when the state does not exist.
+func (r *identityRepository) ConsumeSocialLoginState(ctx context.Context, stateHash string) (*model.SocialLoginState, error) {
+	query := "EXEC ConsumeSocialLoginState @StateHash = @p1"
+	state, err := scanSocialLoginState(r.db.QueryRowContext(ctx, query, sql.Named("p1", stateHash)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No pending sign in was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return state, nil
+}
+
+// scanUserIdentity reads a user identity from a row with the columns returned by the identity procedures
+func scanUserIdentity(row rowScanner) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := row.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// scanSocialLoginState reads a pending sign in from a row with the columns returned by ConsumeSocialLoginState
+func scanSocialLoginState(row rowScanner) (*model.SocialLoginState, error) {
+	var state model.SocialLoginState
+	var userID sql.NullInt64
+	err := row.Scan(&state.StateHash, &state.Provider, &state.Nonce, &state.CodeVerifier, &userID, &state.ExpiresAt, &state.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	state.UserID = int(userID.Int64)
+	return &state, nil
+}
+
+// NewIdentityRepositoryForDriver creates the identity repository matching the
+// database driver.
+func NewIdentityRepositoryForDriver(driver string, db *sql.DB) (model.IdentityRepository, error) {
+	switch driver {
+	case "sqlserver", "mssql":
+		return NewIdentityRepository(db), nil
+	case "postgres":
+		return NewPostgresIdentityRepository(db), nil
+	case "sqlite":
+		return NewSQLiteIdentityRepository(db), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+)
+
+type sqliteIdentityRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteIdentityRepository creates an identity repository for an embedded
+// SQLite database.
+func NewSQLiteIdentityRepository(db *sql.DB) *sqliteIdentityRepository {
+	return &sqliteIdentityRepository{db: db}
+}
+
+// CreateUserIdentity links a user to an account of an upstream identity provider.
+func (r *sqliteIdentityRepository) CreateUserIdentity(ctx context.Context, identity model.UserIdentity) error {
+	query := "INSERT INTO user_identities (user_id, provider, subject, email, created_at) VALUES (?, ?, ?, ?, ?)"
+	_, err := r.db.ExecContext(ctx, query, identity.UserID, identity.Provider, identity.Subject, identity.Email, sqliteTime(identity.CreatedAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// GetUserIdentity retrieves the link of an upstream account by its provider and subject
+func (r *sqliteIdentityRepository) GetUserIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	query := "SELECT " + userIdentityColumns + " FROM user_identities WHERE provider = ? AND subject = ?"
+	identity, err := scanUserIdentity(r.db.QueryRowContext(ctx, query, provider, subject))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No identity was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return identity, nil
+}
+
+// GetUserIdentitiesByUserID retrieves the upstream accounts linked to a user
+func (r *sqliteIdentityRepository) GetUserIdentitiesByUserID(ctx context.Context, userID int) ([]model.UserIdentity, error) {
+	query := "SELECT " + userIdentityColumns + " FROM user_identities WHERE user_id = ? ORDER BY created_at"
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	identities := []model.UserIdentity{}
+	for rows.Next() {
+		identity, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		identities = append(identities, *identity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return identities, nil
+}
+
+// DeleteUserIdentity removes the link between a user and a provider.
+func (r *sqliteIdentityRepository) DeleteUserIdentity(ctx context.Context, userID int, provider string) error {
+	query := "DELETE FROM user_identities WHERE user_id = ? AND provider = ?"
+	_, err := r.db.ExecContext(ctx, query, userID, provider)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// CreateSocialLoginState stores a pending sign in with an upstream provider.
+func (r *sqliteIdentityRepository) CreateSocialLoginState(ctx context.Context, state model.SocialLoginState) error {
+	query := "INSERT INTO social_login_states (" + socialLoginStateColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?)"
+	_, err := r.db.ExecContext(ctx, query, state.StateHash, state.Provider, state.Nonce, state.CodeVerifier,
+		nullableInt(state.UserID), sqliteTime(state.ExpiresAt), sqliteTime(state.CreatedAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// ConsumeSocialLoginState deletes a pending sign in and returns it, so each
+// state can only be used once. It returns nil when the state does not exist.
+func (r *sqliteIdentityRepository) ConsumeSocialLoginState(ctx context.Context, stateHash string) (*model.SocialLoginState, error) {
+	query := "DELETE FROM social_login_states WHERE state_hash = ? RETURNING " + socialLoginStateColumns
+	state, err := scanSocialLoginState(r.db.QueryRowContext(ctx, query, stateHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No pending sign in was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return state, nil
+}
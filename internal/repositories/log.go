@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+	"log/slog"
+)
+
+// queryError logs a failed query at debug level, with the request it was
+// made for, and returns err
+func queryError(ctx context.Context, query string, err error) error {
+	slog.DebugContext(ctx, "Query failed", "query", query, "error", err)
+	return err
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+// nullableInt maps the zero value of an identifier to a SQL NULL.
+func nullableInt(value int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(value), Valid: value != 0}
+}
+
+// nullableString maps an empty string to a SQL NULL.
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// nullableTime maps the zero time to a SQL NULL.
+func nullableTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
+
+// nullableTimePtr maps a nil time to a SQL NULL.
+func nullableTimePtr(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *value, Valid: true}
+}
+
+// timePtr maps a SQL NULL to a nil time.
+func timePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type oauthRepository struct {
+	db *sql.DB
+}
+
+// NewOAuthRepository creates and returns a new instance of the OAuth repository.
+func NewOAuthRepository(db *sql.DB) *oauthRepository {
+	return &oauthRepository{db: db}
+}
+
+// CreateOAuthClient stores a new OAuth client and returns its identifier.
+func (r *oauthRepository) CreateOAuthClient(ctx context.Context, client model.OAuthClient) (int, error) {
+	var id int
+	query := "EXEC CreateOAuthClient @ClientID = @p1, @SecretHash = @p2, @Name = @p3, @RedirectURIs = @p4, @PostLogoutRedirectURIs = @p5, @GrantTypes = @p6, @Scopes = @p7, @IsPublic = @p8, @CreatedAt = @p9"
+	err := r.db.QueryRowContext(ctx, query,
+		sql.Named("p1", client.ClientID),
+		sql.Named("p2", client.SecretHash),
+		sql.Named("p3", client.Name),
+		sql.Named("p4", strings.Join(client.RedirectURIs, " ")),
+		sql.Named("p5", strings.Join(client.PostLogoutRedirectURIs, " ")),
+		sql.Named("p6", strings.Join(client.GrantTypes, " ")),
+		sql.Named("p7", strings.Join(client.Scopes, " ")),
+		sql.Named("p8", client.Public),
+		sql.Named("p9", client.CreatedAt)).Scan(&id)
+	if err != nil {
+		return 0, queryError(ctx, query, err)
+	}
+	return id, nil
+}
+
+// GetOAuthClientByClientID retrieves an OAuth client by its public identifier
+func (r *oauthRepository) GetOAuthClientByClientID(ctx context.Context, clientID string) (*model.OAuthClient, error) {
+	query := "EXEC GetOAuthClientByClientID @ClientID = @p1"
+	client, err := scanOAuthClient(r.db.QueryRowContext(ctx, query, sql.Named("p1", clientID)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No client was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return client, nil
+}
+
+// GetOAuthClients retrieves every registered OAuth client
+func (r *oauthRepository) GetOAuthClients(ctx context.Context) ([]model.OAuthClient, error) {
+	query := "EXEC GetOAuthClients"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	clients := []model.OAuthClient{}
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		clients = append(clients, *client)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return clients, nil
+}
+
+// DeleteOAuthClient removes an OAuth client together with its codes and tokens.
+func (r *oauthRepository) DeleteOAuthClient(ctx context.Context, clientID string) error {
+	query := "EXEC DeleteOAuthClient @ClientID = @p1"
+	_, err := r.db.ExecContext(ctx, query, sql.Named("p1", clientID))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// CreateAuthorizationCode stores a new authorization code.
+func (r *oauthRepository) CreateAuthorizationCode(ctx context.Context, code model.OAuthAuthorizationCode) error {
+	query := "EXEC CreateOAuthAuthorizationCode @CodeHash = @p1, @ClientID = @p2, @UserID = @p3, @RedirectURI = @p4, @Scopes = @p5, @CodeChallenge = @p6, @CodeChallengeMethod = @p7, @Nonce = @p8, @SessionID = @p9, @AuthTime = @p10, @ExpiresAt = @p11, @CreatedAt = @p12, @RedirectURIExplicit = @p13"
+	_, err := r.db.ExecContext(ctx, query,
+		sql.Named("p1", code.CodeHash),
+		sql.Named("p2", code.ClientID),
+		sql.Named("p3", code.UserID),
+		sql.Named("p4", code.RedirectURI),
+		sql.Named("p5", strings.Join(code.Scopes, " ")),
+		sql.Named("p6", code.CodeChallenge),
+		sql.Named("p7", code.CodeChallengeMethod),
+		sql.Named("p8", code.Nonce),
+		sql.Named("p9", code.SessionID),
+		sql.Named("p10", nullableTime(code.AuthTime)),
+		sql.Named("p11", code.ExpiresAt),
+		sql.Named("p12", code.CreatedAt),
+		sql.Named("p13", code.RedirectURIExplicit))
+	return queryError(ctx, query, err)
+}
+
+// ConsumeAuthorizationCode marks an authorization code as used and returns it.
+// It returns nil when the code does not exist or was already used, so a code
+// can only be exchanged once.
+func (r *oauthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string, usedAt time.Time) (*model.OAuthAuthorizationCode, error) {
+	query := "EXEC ConsumeOAuthAuthorizationCode @CodeHash = @p1, @UsedAt = @p2"
+	code, err := scanAuthorizationCode(r.db.QueryRowContext(ctx, query, sql.Named("p1", codeHash), sql.Named("p2", usedAt)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No unused code was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return code, nil
+}
+
+// CreateOAuthToken stores a new access or refresh token.
+func (r *oauthRepository) CreateOAuthToken(ctx context.Context, token model.OAuthToken) error {
+	query := "EXEC CreateOAuthToken @TokenHash = @p1, @TokenType = @p2, @GrantID = @p3, @ClientID = @p4, @UserID = @p5, @Scopes = @p6, @ExpiresAt = @p7, @CreatedAt = @p8"
+	_, err := r.db.ExecContext(ctx, query,
+		sql.Named("p1", token.TokenHash),
+		sql.Named("p2", token.TokenType),
+		sql.Named("p3", token.GrantID),
+		sql.Named("p4", token.ClientID),
+		sql.Named("p5", nullableInt(token.UserID)),
+		sql.Named("p6", strings.Join(token.Scopes, " ")),
+		sql.Named("p7", token.ExpiresAt),
+		sql.Named("p8", token.CreatedAt))
+	return queryError(ctx, query, err)
+}
+
+// GetOAuthTokenByHash retrieves an access or refresh token by its hash
+func (r *oauthRepository) GetOAuthTokenByHash(ctx context.Context, tokenHash string) (*model.OAuthToken, error) {
+	query := "EXEC GetOAuthTokenByHash @TokenHash = @p1"
+	token, err := scanOAuthToken(r.db.QueryRowContext(ctx, query, sql.Named("p1", tokenHash)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No token was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return token, nil
+}
+
+// RevokeOAuthToken revokes a single access or refresh token. It reports
+// whether the token was still active, so only one caller can revoke it.
+func (r *oauthRepository) RevokeOAuthToken(ctx context.Context, tokenHash string, revokedAt time.Time) (bool, error) {
+	query := "EXEC RevokeOAuthToken @TokenHash = @p1, @RevokedAt = @p2"
+	result, err := r.db.ExecContext(ctx, query, sql.Named("p1", tokenHash), sql.Named("p2", revokedAt))
+	if err != nil {
+		return false, queryError(ctx, query, err)
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return revoked > 0, nil
+}
+
+// RevokeOAuthGrant revokes every token issued from the same grant.
+func (r *oauthRepository) RevokeOAuthGrant(ctx context.Context, grantID string, revokedAt time.Time) error {
+	query := "EXEC RevokeOAuthGrant @GrantID = @p1, @RevokedAt = @p2"
+	_, err := r.db.ExecContext(ctx, query, sql.Named("p1", grantID), sql.Named("p2", revokedAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// scanOAuthClient reads an OAuth client from a row with the columns returned by the client procedures
+func scanOAuthClient(row rowScanner) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	var redirectURIs, postLogoutRedirectURIs, grantTypes, scopes string
+	err := row.Scan(&client.ID, &client.ClientID, &client.SecretHash, &client.Name,
+		&redirectURIs, &postLogoutRedirectURIs, &grantTypes, &scopes, &client.Public, &client.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	client.PostLogoutRedirectURIs = strings.Fields(postLogoutRedirectURIs)
+	client.GrantTypes = strings.Fields(grantTypes)
+	client.Scopes = strings.Fields(scopes)
+	return &client, nil
+}
+
+// scanAuthorizationCode reads an authorization code from a row with the columns returned by ConsumeOAuthAuthorizationCode
+func scanAuthorizationCode(row rowScanner) (*model.OAuthAuthorizationCode, error) {
+	var code model.OAuthAuthorizationCode
+	var scopes string
+	var authTime sql.NullTime
+	err := row.Scan(&code.CodeHash, &code.ClientID, &code.UserID, &code.RedirectURI, &code.RedirectURIExplicit, &scopes,
+		&code.CodeChallenge, &code.CodeChallengeMethod, &code.Nonce, &code.SessionID, &authTime,
+		&code.ExpiresAt, &code.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	code.Scopes = strings.Fields(scopes)
+	code.AuthTime = authTime.Time
+	return &code, nil
+}
+
+// scanOAuthToken reads a token from a row with the columns returned by GetOAuthTokenByHash
+func scanOAuthToken(row rowScanner) (*model.OAuthToken, error) {
+	var token model.OAuthToken
+	var userID sql.NullInt64
+	var scopes string
+	var revokedAt sql.NullTime
+	err := row.Scan(&token.TokenHash, &token.TokenType, &token.GrantID, &token.ClientID, &userID, &scopes,
+		&token.ExpiresAt, &token.CreatedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	token.UserID = int(userID.Int64)
+	token.Scopes = strings.Fields(scopes)
+	token.RevokedAt = timePtr(revokedAt)
+	return &token, nil
+}
+
+// NewOAuthRepositoryForDriver creates the OAuth repository matching the
+// database driver.
+func NewOAuthRepositoryForDriver(driver string, db *sql.DB) (model.OAuthRepository, error) {
+	switch driver {
+	case "sqlserver", "mssql":
+		return NewOAuthRepository(db), nil
+	case "postgres":
+		return NewPostgresOAuthRepository(db), nil
+	case "sqlite":
+		return NewSQLiteOAuthRepository(db), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
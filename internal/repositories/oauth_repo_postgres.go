@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"strings"
+	"time"
+)
+
+// oauthClientColumns are the columns read by scanOAuthClient
+const oauthClientColumns = "id, client_id, COALESCE(secret_hash, ''), name, redirect_uris, post_logout_redirect_uris, grant_types, scopes, is_public, created_at"
+
+// authorizationCodeColumns are the columns read by scanAuthorizationCode
+const authorizationCodeColumns = "code_hash, client_id, user_id, redirect_uri, redirect_uri_explicit, scopes, code_challenge, code_challenge_method, nonce, session_id, auth_time, expires_at, created_at"
+
+// oauthTokenColumns are the columns read by scanOAuthToken
+const oauthTokenColumns = "token_hash, token_type, grant_id, client_id, user_id, scopes, expires_at, created_at, revoked_at"
+
+type postgresOAuthRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresOAuthRepository creates an OAuth repository for PostgreSQL.
+func NewPostgresOAuthRepository(db *sql.DB) *postgresOAuthRepository {
+	return &postgresOAuthRepository{db: db}
+}
+
+// CreateOAuthClient stores a new OAuth client and returns its identifier.
+func (r *postgresOAuthRepository) CreateOAuthClient(ctx context.Context, client model.OAuthClient) (int, error) {
+	var id int
+	query := "INSERT INTO oauth_clients (client_id, secret_hash, name, redirect_uris, post_logout_redirect_uris, grant_types, scopes, is_public, created_at)" +
+		" VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id"
+	err := r.db.QueryRowContext(ctx, query,
+		client.ClientID,
+		nullableString(client.SecretHash),
+		client.Name,
+		strings.Join(client.RedirectURIs, " "),
+		strings.Join(client.PostLogoutRedirectURIs, " "),
+		strings.Join(client.GrantTypes, " "),
+		strings.Join(client.Scopes, " "),
+		client.Public,
+		client.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, queryError(ctx, query, err)
+	}
+	return id, nil
+}
+
+// GetOAuthClientByClientID retrieves an OAuth client by its public identifier
+func (r *postgresOAuthRepository) GetOAuthClientByClientID(ctx context.Context, clientID string) (*model.OAuthClient, error) {
+	query := "SELECT " + oauthClientColumns + " FROM oauth_clients WHERE client_id = $1"
+	client, err := scanOAuthClient(r.db.QueryRowContext(ctx, query, clientID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No client was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return client, nil
+}
+
+// GetOAuthClients retrieves every registered OAuth client
+func (r *postgresOAuthRepository) GetOAuthClients(ctx context.Context) ([]model.OAuthClient, error) {
+	query := "SELECT " + oauthClientColumns + " FROM oauth_clients ORDER BY created_at DESC"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	clients := []model.OAuthClient{}
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		clients = append(clients, *client)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return clients, nil
+}
+
+// DeleteOAuthClient removes an OAuth client. Its codes and tokens are removed
+// by the ON DELETE CASCADE foreign keys of the PostgreSQL schema.
+func (r *postgresOAuthRepository) DeleteOAuthClient(ctx context.Context, clientID string) error {
+	query := "DELETE FROM oauth_clients WHERE client_id = $1"
+	_, err := r.db.ExecContext(ctx, query, clientID)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// CreateAuthorizationCode stores a new authorization code.
+func (r *postgresOAuthRepository) CreateAuthorizationCode(ctx context.Context, code model.OAuthAuthorizationCode) error {
+	query := "INSERT INTO oauth_authorization_codes (" + authorizationCodeColumns + ")" +
+		" VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)"
+	_, err := r.db.ExecContext(ctx, query,
+		code.CodeHash,
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		code.RedirectURIExplicit,
+		strings.Join(code.Scopes, " "),
+		code.CodeChallenge,
+		code.CodeChallengeMethod,
+		code.Nonce,
+		code.SessionID,
+		nullableTime(code.AuthTime),
+		code.ExpiresAt,
+		code.CreatedAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// ConsumeAuthorizationCode marks an authorization code as used and returns it.
+// It returns nil when the code does not exist or was already used, so a code
+// can only be exchanged once.
+func (r *postgresOAuthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string, usedAt time.Time) (*model.OAuthAuthorizationCode, error) {
+	query := "UPDATE oauth_authorization_codes SET used_at = $2 WHERE code_hash = $1 AND used_at IS NULL RETURNING " + authorizationCodeColumns
+	code, err := scanAuthorizationCode(r.db.QueryRowContext(ctx, query, codeHash, usedAt))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No unused code was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return code, nil
+}
+
+// CreateOAuthToken stores a new access or refresh token.
+func (r *postgresOAuthRepository) CreateOAuthToken(ctx context.Context, token model.OAuthToken) error {
+	query := "INSERT INTO oauth_tokens (token_hash, token_type, grant_id, client_id, user_id, scopes, expires_at, created_at)" +
+		" VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+	_, err := r.db.ExecContext(ctx, query, token.TokenHash, token.TokenType, token.GrantID, token.ClientID,
+		nullableInt(token.UserID), strings.Join(token.Scopes, " "), token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// GetOAuthTokenByHash retrieves an access or refresh token by its hash
+func (r *postgresOAuthRepository) GetOAuthTokenByHash(ctx context.Context, tokenHash string) (*model.OAuthToken, error) {
+	query := "SELECT " + oauthTokenColumns + " FROM oauth_tokens WHERE token_hash = $1"
+	token, err := scanOAuthToken(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No token was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return token, nil
+}
+
+// RevokeOAuthToken revokes a single access or refresh token. It reports
+// whether the token was still active, so only one caller can revoke it.
+func (r *postgresOAuthRepository) RevokeOAuthToken(ctx context.Context, tokenHash string, revokedAt time.Time) (bool, error) {
+	query := "UPDATE oauth_tokens SET revoked_at = $2 WHERE token_hash = $1 AND revoked_at IS NULL"
+	result, err := r.db.ExecContext(ctx, query, tokenHash, revokedAt)
+	if err != nil {
+		return false, queryError(ctx, query, err)
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return revoked > 0, nil
+}
+
+// RevokeOAuthGrant revokes every token issued from the same grant.
+func (r *postgresOAuthRepository) RevokeOAuthGrant(ctx context.Context, grantID string, revokedAt time.Time) error {
+	query := "UPDATE oauth_tokens SET revoked_at = $2 WHERE grant_id = $1 AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, grantID, revokedAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package repositories_test
+
+import (
+	"context"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteOAuthRepository(t *testing.T) {
+	database := newSQLiteDatabase(t)
+	users, _ := repositories.NewUserRepositoryForDriver("sqlite", database)
+	repo, err := repositories.NewOAuthRepositoryForDriver("sqlite", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	assert.NoError(t, users.CreateUser(ctx, model.User{Username: "jdoe", Email: "jdoe@example.com", Password: "hash"}))
+	client := model.OAuthClient{
+		ClientID:               "app",
+		Name:                   "App",
+		RedirectURIs:           []string{"https://app.example.com/callback"},
+		PostLogoutRedirectURIs: []string{"https://app.example.com/"},
+		GrantTypes:             []string{"authorization_code", "refresh_token"},
+		Scopes:                 []string{"openid", "profile"},
+		Public:                 true,
+		CreatedAt:              now,
+	}
+	id, err := repo.CreateOAuthClient(ctx, client)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	t.Run("clients", func(t *testing.T) {
+		stored, err := repo.GetOAuthClientByClientID(ctx, "app")
+		assert.NoError(t, err)
+		if assert.NotNil(t, stored) {
+			assert.Empty(t, stored.SecretHash)
+			assert.Equal(t, client.RedirectURIs, stored.RedirectURIs)
+			assert.Equal(t, client.PostLogoutRedirectURIs, stored.PostLogoutRedirectURIs)
+			assert.Equal(t, client.Scopes, stored.Scopes)
+			assert.True(t, stored.Public)
+		}
+
+		clients, err := repo.GetOAuthClients(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, clients, 1)
+	})
+
+	t.Run("authorization codes are single use", func(t *testing.T) {
+		assert.NoError(t, repo.CreateAuthorizationCode(ctx, model.OAuthAuthorizationCode{
+			CodeHash:            "code",
+			ClientID:            "app",
+			UserID:              1,
+			RedirectURI:         "https://app.example.com/callback",
+			RedirectURIExplicit: true,
+			Scopes:              []string{"openid"},
+			CodeChallenge:       "challenge",
+			CodeChallengeMethod: "S256",
+			SessionID:           "session",
+			ExpiresAt:           now.Add(time.Minute),
+			CreatedAt:           now,
+		}))
+
+		code, err := repo.ConsumeAuthorizationCode(ctx, "code", now)
+		assert.NoError(t, err)
+		if assert.NotNil(t, code) {
+			assert.True(t, code.RedirectURIExplicit)
+			assert.Equal(t, []string{"openid"}, code.Scopes)
+			assert.True(t, code.AuthTime.IsZero())
+		}
+
+		code, err = repo.ConsumeAuthorizationCode(ctx, "code", now)
+		assert.NoError(t, err)
+		assert.Nil(t, code)
+	})
+
+	t.Run("tokens", func(t *testing.T) {
+		assert.NoError(t, repo.CreateOAuthToken(ctx, model.OAuthToken{TokenHash: "access", TokenType: "access_token", GrantID: "grant", ClientID: "app", UserID: 1, Scopes: []string{"openid"}, ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+		assert.NoError(t, repo.CreateOAuthToken(ctx, model.OAuthToken{TokenHash: "service", TokenType: "access_token", GrantID: "other", ClientID: "app", ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+		assert.NoError(t, repo.RevokeOAuthGrant(ctx, "grant", now))
+
+		token, err := repo.GetOAuthTokenByHash(ctx, "access")
+		assert.NoError(t, err)
+		assert.True(t, token.RevokedAt.Equal(now))
+
+		token, err = repo.GetOAuthTokenByHash(ctx, "service")
+		assert.NoError(t, err)
+		assert.Zero(t, token.UserID)
+		assert.Nil(t, token.RevokedAt)
+	})
+
+	t.Run("a token is revoked only once", func(t *testing.T) {
+		assert.NoError(t, repo.CreateOAuthToken(ctx, model.OAuthToken{TokenHash: "refresh", TokenType: "refresh_token", GrantID: "other", ClientID: "app", ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+
+		revoked, err := repo.RevokeOAuthToken(ctx, "refresh", now)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+
+		revoked, err = repo.RevokeOAuthToken(ctx, "refresh", now)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("deleting the client removes its tokens", func(t *testing.T) {
+		assert.NoError(t, repo.DeleteOAuthClient(ctx, "app"))
+
+		token, err := repo.GetOAuthTokenByHash(ctx, "service")
+		assert.NoError(t, err)
+		assert.Nil(t, token)
+		stored, err := repo.GetOAuthClientByClientID(ctx, "app")
+		assert.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+}
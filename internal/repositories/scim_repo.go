@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+)
+
+type scimRepository struct {
+	db *sql.DB
+}
+
+// NewSCIMRepository creates and returns a new instance of the SCIM repository.
+func NewSCIMRepository(db *sql.DB) *scimRepository {
+	return &scimRepository{db: db}
+}
+
+// CreateSCIMClient stores a new provisioning client and returns its identifier.
+func (r *scimRepository) CreateSCIMClient(ctx context.Context, client model.SCIMClient) (int, error) {
+	var id int
+	query := "EXEC CreateSCIMClient @Name = @p1, @TokenHash = @p2, @CreatedAt = @p3"
+	err := r.db.QueryRowContext(ctx, query,
+		sql.Named("p1", client.Name),
+		sql.Named("p2", client.TokenHash),
+		sql.Named("p3", client.CreatedAt)).Scan(&id)
+	if err != nil {
+		return 0, queryError(ctx, query, err)
+	}
+	return id, nil
+}
+
+// GetSCIMClientByTokenHash retrieves the provisioning client owning a token.
+func (r *scimRepository) GetSCIMClientByTokenHash(ctx context.Context, tokenHash string) (*model.SCIMClient, error) {
+	query := "EXEC GetSCIMClientByTokenHash @TokenHash = @p1"
+	client, err := scanSCIMClient(r.db.QueryRowContext(ctx, query, sql.Named("p1", tokenHash)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No client was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return client, nil
+}
+
+// GetSCIMClientByID retrieves a provisioning client by its identifier.
+func (r *scimRepository) GetSCIMClientByID(ctx context.Context, id int) (*model.SCIMClient, error) {
+	query := "EXEC GetSCIMClientByID @ID = @p1"
+	client, err := scanSCIMClient(r.db.QueryRowContext(ctx, query, sql.Named("p1", id)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No client was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return client, nil
+}
+
+// GetSCIMClients retrieves every provisioning client.
+func (r *scimRepository) GetSCIMClients(ctx context.Context) ([]model.SCIMClient, error) {
+	query := "EXEC GetSCIMClients"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	clients := []model.SCIMClient{}
+	for rows.Next() {
+		client, err := scanSCIMClient(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		clients = append(clients, *client)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return clients, nil
+}
+
+// DeleteSCIMClient removes a provisioning client, invalidating its token.
+func (r *scimRepository) DeleteSCIMClient(ctx context.Context, id int) error {
+	query := "EXEC DeleteSCIMClient @ID = @p1"
+	_, err := r.db.ExecContext(ctx, query, sql.Named("p1", id))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// scanSCIMClient reads a provisioning client from a row with the columns returned by the SCIM client procedures
+func scanSCIMClient(row rowScanner) (*model.SCIMClient, error) {
+	var client model.SCIMClient
+	if err := row.Scan(&client.ID, &client.Name, &client.TokenHash, &client.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// NewSCIMRepositoryForDriver creates the SCIM repository matching the
+// database driver.
+func NewSCIMRepositoryForDriver(driver string, db *sql.DB) (model.SCIMRepository, error) {
+	switch driver {
+	case "sqlserver", "mssql":
+		return NewSCIMRepository(db), nil
+	case "postgres":
+		return NewPostgresSCIMRepository(db), nil
+	case "sqlite":
+		return NewSQLiteSCIMRepository(db), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+)
+
+// scimClientColumns are the columns read by scanSCIMClient
+const scimClientColumns = "id, name, token_hash, created_at"
+
+type postgresSCIMRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSCIMRepository creates a SCIM repository for PostgreSQL.
+func NewPostgresSCIMRepository(db *sql.DB) *postgresSCIMRepository {
+	return &postgresSCIMRepository{db: db}
+}
+
+// CreateSCIMClient stores a new provisioning client and returns its identifier.
+func (r *postgresSCIMRepository) CreateSCIMClient(ctx context.Context, client model.SCIMClient) (int, error) {
+	var id int
+	query := "INSERT INTO scim_clients (name, token_hash, created_at) VALUES ($1, $2, $3) RETURNING id"
+	err := r.db.QueryRowContext(ctx, query, client.Name, client.TokenHash, client.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, queryError(ctx, query, err)
+	}
+	return id, nil
+}
+
+// GetSCIMClientByTokenHash retrieves the provisioning client owning a token.
+func (r *postgresSCIMRepository) GetSCIMClientByTokenHash(ctx context.Context, tokenHash string) (*model.SCIMClient, error) {
+	query := "SELECT " + scimClientColumns + " FROM scim_clients WHERE token_hash = $1"
+	return r.getSCIMClient(ctx, query, tokenHash)
+}
+
+// GetSCIMClientByID retrieves a provisioning client by its identifier.
+func (r *postgresSCIMRepository) GetSCIMClientByID(ctx context.Context, id int) (*model.SCIMClient, error) {
+	query := "SELECT " + scimClientColumns + " FROM scim_clients WHERE id = $1"
+	return r.getSCIMClient(ctx, query, id)
+}
+
+// GetSCIMClients retrieves every provisioning client.
+func (r *postgresSCIMRepository) GetSCIMClients(ctx context.Context) ([]model.SCIMClient, error) {
+	query := "SELECT " + scimClientColumns + " FROM scim_clients ORDER BY created_at DESC"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	clients := []model.SCIMClient{}
+	for rows.Next() {
+		client, err := scanSCIMClient(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		clients = append(clients, *client)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return clients, nil
+}
+
+// DeleteSCIMClient removes a provisioning client, invalidating its token. The
+// foreign key of users.scim_client_id leaves its users unmanaged.
+func (r *postgresSCIMRepository) DeleteSCIMClient(ctx context.Context, id int) error {
+	query := "DELETE FROM scim_clients WHERE id = $1"
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// getSCIMClient runs a query returning at most one provisioning client
+func (r *postgresSCIMRepository) getSCIMClient(ctx context.Context, query string, args ...interface{}) (*model.SCIMClient, error) {
+	client, err := scanSCIMClient(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No client was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return client, nil
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+)
+
+type sqliteSCIMRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteSCIMRepository creates a SCIM repository for an embedded SQLite
+// database.
+func NewSQLiteSCIMRepository(db *sql.DB) *sqliteSCIMRepository {
+	return &sqliteSCIMRepository{db: db}
+}
+
+// CreateSCIMClient stores a new provisioning client and returns its identifier.
+func (r *sqliteSCIMRepository) CreateSCIMClient(ctx context.Context, client model.SCIMClient) (int, error) {
+	var id int
+	query := "INSERT INTO scim_clients (name, token_hash, created_at) VALUES (?, ?, ?) RETURNING id"
+	err := r.db.QueryRowContext(ctx, query, client.Name, client.TokenHash, sqliteTime(client.CreatedAt)).Scan(&id)
+	if err != nil {
+		return 0, queryError(ctx, query, err)
+	}
+	return id, nil
+}
+
+// GetSCIMClientByTokenHash retrieves the provisioning client owning a token.
+func (r *sqliteSCIMRepository) GetSCIMClientByTokenHash(ctx context.Context, tokenHash string) (*model.SCIMClient, error) {
+	query := "SELECT " + scimClientColumns + " FROM scim_clients WHERE token_hash = ?"
+	return r.getSCIMClient(ctx, query, tokenHash)
+}
+
+// GetSCIMClientByID retrieves a provisioning client by its identifier.
+func (r *sqliteSCIMRepository) GetSCIMClientByID(ctx context.Context, id int) (*model.SCIMClient, error) {
+	query := "SELECT " + scimClientColumns + " FROM scim_clients WHERE id = ?"
+	return r.getSCIMClient(ctx, query, id)
+}
+
+// GetSCIMClients retrieves every provisioning client.
+func (r *sqliteSCIMRepository) GetSCIMClients(ctx context.Context) ([]model.SCIMClient, error) {
+	query := "SELECT " + scimClientColumns + " FROM scim_clients ORDER BY created_at DESC"
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	clients := []model.SCIMClient{}
+	for rows.Next() {
+		client, err := scanSCIMClient(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		clients = append(clients, *client)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return clients, nil
+}
+
+// DeleteSCIMClient removes a provisioning client, invalidating its token. Its
+// users are left unmanaged.
+func (r *sqliteSCIMRepository) DeleteSCIMClient(ctx context.Context, id int) error {
+	return execInTransaction(ctx, r.db, []string{
+		"UPDATE users SET scim_client_id = NULL WHERE scim_client_id = ?",
+		"DELETE FROM scim_clients WHERE id = ?",
+	}, id)
+}
+
+// getSCIMClient runs a query returning at most one provisioning client
+func (r *sqliteSCIMRepository) getSCIMClient(ctx context.Context, query string, args ...interface{}) (*model.SCIMClient, error) {
+	client, err := scanSCIMClient(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No client was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return client, nil
+}
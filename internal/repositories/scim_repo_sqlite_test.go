@@ -0,0 +1,40 @@
+package repositories_test
+
+import (
+	"context"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteSCIMRepository(t *testing.T) {
+	database := newSQLiteDatabase(t)
+	users, _ := repositories.NewUserRepositoryForDriver("sqlite", database)
+	repo, err := repositories.NewSCIMRepositoryForDriver("sqlite", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	id, err := repo.CreateSCIMClient(ctx, model.SCIMClient{Name: "Okta", TokenHash: "hash", CreatedAt: time.Now()})
+	assert.NoError(t, err)
+	assert.NoError(t, users.CreateUser(ctx, model.User{Username: "jdoe", Email: "jdoe@example.com", Password: "hash", SCIMClientID: id}))
+
+	client, err := repo.GetSCIMClientByTokenHash(ctx, "hash")
+	assert.NoError(t, err)
+	if assert.NotNil(t, client) {
+		assert.Equal(t, "Okta", client.Name)
+	}
+
+	// Deleting the client leaves its users unmanaged.
+	assert.NoError(t, repo.DeleteSCIMClient(ctx, id))
+	client, err = repo.GetSCIMClientByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Nil(t, client)
+	user, err := users.GetUserByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Zero(t, user.SCIMClientID)
+}
@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+	"time"
+)
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository creates and returns a new instance of the session repository.
+func NewSessionRepository(db *sql.DB) *sessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// CreateSession stores a new session in the database.
+func (r *sessionRepository) CreateSession(ctx context.Context, session model.Session) error {
+	query := "EXEC CreateSession @ID = @p1, @UserID = @p2, @Device = @p3, @IPAddress = @p4, @UserAgent = @p5, @CreatedAt = @p6, @ExpiresAt = @p7"
+	_, err := r.db.ExecContext(ctx, query,
+		sql.Named("p1", session.ID),
+		sql.Named("p2", session.UserID),
+		sql.Named("p3", session.Device),
+		sql.Named("p4", session.IPAddress),
+		sql.Named("p5", session.UserAgent),
+		sql.Named("p6", session.CreatedAt),
+		sql.Named("p7", session.ExpiresAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// GetSessionByID retrieves a session by its identifier
+func (r *sessionRepository) GetSessionByID(ctx context.Context, id string) (*model.Session, error) {
+	query := "EXEC GetSessionByID @ID = @p1"
+	row := r.db.QueryRowContext(ctx, query, sql.Named("p1", id))
+
+	session, err := scanSession(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No session was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+
+	return session, nil
+}
+
+// GetSessionsByUserID retrieves the most recent sessions of a user, newest first
+func (r *sessionRepository) GetSessionsByUserID(ctx context.Context, userID int, limit int) ([]model.Session, error) {
+	query := "EXEC GetSessionsByUserID @UserID = @p1, @Limit = @p2"
+	rows, err := r.db.QueryContext(ctx, query, sql.Named("p1", userID), sql.Named("p2", limit))
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return sessions, nil
+}
+
+// UpdateSessionLastSeen records the last time a session was used.
+func (r *sessionRepository) UpdateSessionLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error {
+	query := "EXEC UpdateSessionLastSeen @ID = @p1, @LastSeenAt = @p2"
+	_, err := r.db.ExecContext(ctx, query, sql.Named("p1", id), sql.Named("p2", lastSeenAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// RevokeSession marks a session as revoked.
+func (r *sessionRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	query := "EXEC RevokeSession @ID = @p1, @RevokedAt = @p2"
+	_, err := r.db.ExecContext(ctx, query, sql.Named("p1", id), sql.Named("p2", revokedAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every active session of a user.
+func (r *sessionRepository) RevokeUserSessions(ctx context.Context, userID int, revokedAt time.Time) error {
+	query := "EXEC RevokeUserSessions @UserID = @p1, @RevokedAt = @p2"
+	_, err := r.db.ExecContext(ctx, query, sql.Named("p1", userID), sql.Named("p2", revokedAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// CreateRevokeLink stores the "this wasn't me" link of a login alert.
+func (r *sessionRepository) CreateRevokeLink(ctx context.Context, link model.RevokeLink) error {
+	query := "EXEC CreateRevokeLink @ID = @p1, @UserID = @p2, @CreatedAt = @p3, @ExpiresAt = @p4"
+	_, err := r.db.ExecContext(ctx, query,
+		sql.Named("p1", link.ID),
+		sql.Named("p2", link.UserID),
+		sql.Named("p3", link.CreatedAt),
+		sql.Named("p4", link.ExpiresAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// ConsumeRevokeLink marks a "this wasn't me" link as used and returns it. It
+// returns nil when the link does not exist or was already used, so a link can
+// only be used once.
+func (r *sessionRepository) ConsumeRevokeLink(ctx context.Context, id string, usedAt time.Time) (*model.RevokeLink, error) {
+	query := "EXEC ConsumeRevokeLink @ID = @p1, @UsedAt = @p2"
+	link, err := scanRevokeLink(r.db.QueryRowContext(ctx, query, sql.Named("p1", id), sql.Named("p2", usedAt)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No unused link was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return link, nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSession reads a session from a row with the columns returned by the session procedures
+func scanSession(row rowScanner) (*model.Session, error) {
+	var session model.Session
+	var revokedAt sql.NullTime
+	err := row.Scan(&session.ID, &session.UserID, &session.Device, &session.IPAddress, &session.UserAgent,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+	session.RevokedAt = timePtr(revokedAt)
+	return &session, nil
+}
+
+// scanRevokeLink reads a revoke link from a row with the columns returned by the revoke link procedures
+func scanRevokeLink(row rowScanner) (*model.RevokeLink, error) {
+	var link model.RevokeLink
+	var usedAt sql.NullTime
+	if err := row.Scan(&link.ID, &link.UserID, &link.CreatedAt, &link.ExpiresAt, &usedAt); err != nil {
+		return nil, err
+	}
+	link.UsedAt = timePtr(usedAt)
+	return &link, nil
+}
+
+// NewSessionRepositoryForDriver creates the session repository matching the
+// database driver.
+func NewSessionRepositoryForDriver(driver string, db *sql.DB) (model.SessionRepository, error) {
+	switch driver {
+	case "sqlserver", "mssql":
+		return NewSessionRepository(db), nil
+	case "postgres":
+		return NewPostgresSessionRepository(db), nil
+	case "sqlite":
+		return NewSQLiteSessionRepository(db), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"time"
+)
+
+// sessionColumns are the columns read by scanSession
+const sessionColumns = "id, user_id, device, ip_address, user_agent, created_at, last_seen_at, expires_at, revoked_at"
+
+// revokeLinkColumns are the columns read by scanRevokeLink
+const revokeLinkColumns = "id, user_id, created_at, expires_at, used_at"
+
+type postgresSessionRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSessionRepository creates a session repository for PostgreSQL.
+func NewPostgresSessionRepository(db *sql.DB) *postgresSessionRepository {
+	return &postgresSessionRepository{db: db}
+}
+
+// CreateSession stores a new session in the database.
+func (r *postgresSessionRepository) CreateSession(ctx context.Context, session model.Session) error {
+	query := "INSERT INTO sessions (id, user_id, device, ip_address, user_agent, created_at, last_seen_at, expires_at)" +
+		" VALUES ($1, $2, $3, LEFT($4, 45), LEFT($5, 512), $6, $6, $7)"
+	_, err := r.db.ExecContext(ctx, query, session.ID, session.UserID, session.Device, session.IPAddress,
+		session.UserAgent, session.CreatedAt, session.ExpiresAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// GetSessionByID retrieves a session by its identifier
+func (r *postgresSessionRepository) GetSessionByID(ctx context.Context, id string) (*model.Session, error) {
+	query := "SELECT " + sessionColumns + " FROM sessions WHERE id = $1"
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No session was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return session, nil
+}
+
+// GetSessionsByUserID retrieves the most recent sessions of a user, newest first
+func (r *postgresSessionRepository) GetSessionsByUserID(ctx context.Context, userID int, limit int) ([]model.Session, error) {
+	query := "SELECT " + sessionColumns + " FROM sessions WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2"
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return sessions, nil
+}
+
+// UpdateSessionLastSeen records the last time a session was used.
+func (r *postgresSessionRepository) UpdateSessionLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error {
+	query := "UPDATE sessions SET last_seen_at = $2 WHERE id = $1"
+	_, err := r.db.ExecContext(ctx, query, id, lastSeenAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// RevokeSession marks a session as revoked.
+func (r *postgresSessionRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	query := "UPDATE sessions SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, id, revokedAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every active session of a user.
+func (r *postgresSessionRepository) RevokeUserSessions(ctx context.Context, userID int, revokedAt time.Time) error {
+	query := "UPDATE sessions SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, userID, revokedAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// CreateRevokeLink stores the "this wasn't me" link of a login alert.
+func (r *postgresSessionRepository) CreateRevokeLink(ctx context.Context, link model.RevokeLink) error {
+	query := "INSERT INTO revoke_links (id, user_id, created_at, expires_at) VALUES ($1, $2, $3, $4)"
+	_, err := r.db.ExecContext(ctx, query, link.ID, link.UserID, link.CreatedAt, link.ExpiresAt)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// ConsumeRevokeLink marks a "this wasn't me" link as used and returns it. It
+// returns nil when the link does not exist or was already used, so a link can
+// only be used once.
+func (r *postgresSessionRepository) ConsumeRevokeLink(ctx context.Context, id string, usedAt time.Time) (*model.RevokeLink, error) {
+	query := "UPDATE revoke_links SET used_at = $2 WHERE id = $1 AND used_at IS NULL RETURNING " + revokeLinkColumns
+	link, err := scanRevokeLink(r.db.QueryRowContext(ctx, query, id, usedAt))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No unused link was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return link, nil
+}
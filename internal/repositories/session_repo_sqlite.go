@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+	"time"
+)
+
+type sqliteSessionRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteSessionRepository creates a session repository for an embedded
+// SQLite database.
+func NewSQLiteSessionRepository(db *sql.DB) *sqliteSessionRepository {
+	return &sqliteSessionRepository{db: db}
+}
+
+// CreateSession stores a new session in the database.
+func (r *sqliteSessionRepository) CreateSession(ctx context.Context, session model.Session) error {
+	query := "INSERT INTO sessions (id, user_id, device, ip_address, user_agent, created_at, last_seen_at, expires_at) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6, ?7)"
+	_, err := r.db.ExecContext(ctx, query, session.ID, session.UserID, session.Device, session.IPAddress,
+		session.UserAgent, sqliteTime(session.CreatedAt), sqliteTime(session.ExpiresAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// GetSessionByID retrieves a session by its identifier
+func (r *sqliteSessionRepository) GetSessionByID(ctx context.Context, id string) (*model.Session, error) {
+	query := "SELECT " + sessionColumns + " FROM sessions WHERE id = ?"
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No session was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return session, nil
+}
+
+// GetSessionsByUserID retrieves the most recent sessions of a user, newest first
+func (r *sqliteSessionRepository) GetSessionsByUserID(ctx context.Context, userID int, limit int) ([]model.Session, error) {
+	query := "SELECT " + sessionColumns + " FROM sessions WHERE user_id = ? ORDER BY created_at DESC LIMIT ?"
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, queryError(ctx, query, err)
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, query, err)
+	}
+
+	return sessions, nil
+}
+
+// UpdateSessionLastSeen records the last time a session was used.
+func (r *sqliteSessionRepository) UpdateSessionLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error {
+	query := "UPDATE sessions SET last_seen_at = ? WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, sqliteTime(lastSeenAt), id)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// RevokeSession marks a session as revoked.
+func (r *sqliteSessionRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	query := "UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, sqliteTime(revokedAt), id)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes every active session of a user.
+func (r *sqliteSessionRepository) RevokeUserSessions(ctx context.Context, userID int, revokedAt time.Time) error {
+	query := "UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL"
+	_, err := r.db.ExecContext(ctx, query, sqliteTime(revokedAt), userID)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// CreateRevokeLink stores the "this wasn't me" link of a login alert.
+func (r *sqliteSessionRepository) CreateRevokeLink(ctx context.Context, link model.RevokeLink) error {
+	query := "INSERT INTO revoke_links (id, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)"
+	_, err := r.db.ExecContext(ctx, query, link.ID, link.UserID, sqliteTime(link.CreatedAt), sqliteTime(link.ExpiresAt))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// ConsumeRevokeLink marks a "this wasn't me" link as used and returns it. It
+// returns nil when the link does not exist or was already used, so a link can
+// only be used once.
+func (r *sqliteSessionRepository) ConsumeRevokeLink(ctx context.Context, id string, usedAt time.Time) (*model.RevokeLink, error) {
+	query := "UPDATE revoke_links SET used_at = ? WHERE id = ? AND used_at IS NULL RETURNING " + revokeLinkColumns
+	link, err := scanRevokeLink(r.db.QueryRowContext(ctx, query, sqliteTime(usedAt), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No unused link was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return link, nil
+}
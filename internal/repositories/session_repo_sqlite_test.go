@@ -0,0 +1,81 @@
+package repositories_test
+
+import (
+	"context"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteSessionRepository(t *testing.T) {
+	database := newSQLiteDatabase(t)
+	users, _ := repositories.NewUserRepositoryForDriver("sqlite", database)
+	repo, err := repositories.NewSessionRepositoryForDriver("sqlite", database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	assert.NoError(t, users.CreateUser(ctx, model.User{Username: "jdoe", Email: "jdoe@example.com", Password: "hash"}))
+	assert.NoError(t, repo.CreateSession(ctx, model.Session{ID: "older", UserID: 1, Device: "Firefox", IPAddress: "192.0.2.1", UserAgent: "Mozilla/5.0", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}))
+	// Times in other zones are stored in UTC, so they still sort by instant.
+	newer := now.In(time.FixedZone("UTC-5", -5*60*60))
+	assert.NoError(t, repo.CreateSession(ctx, model.Session{ID: "newer", UserID: 1, Device: "Chrome", IPAddress: "192.0.2.2", UserAgent: "Mozilla/5.0", CreatedAt: newer, ExpiresAt: newer.Add(time.Hour)}))
+
+	t.Run("lookups", func(t *testing.T) {
+		session, err := repo.GetSessionByID(ctx, "older")
+		assert.NoError(t, err)
+		assert.Equal(t, "Firefox", session.Device)
+		assert.True(t, session.CreatedAt.Equal(now.Add(-time.Hour)))
+		assert.True(t, session.LastSeenAt.Equal(session.CreatedAt))
+		assert.Nil(t, session.RevokedAt)
+
+		sessions, err := repo.GetSessionsByUserID(ctx, 1, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, sessions, 2) {
+			assert.Equal(t, "newer", sessions[0].ID)
+			assert.Equal(t, "older", sessions[1].ID)
+		}
+
+		session, err = repo.GetSessionByID(ctx, "missing")
+		assert.NoError(t, err)
+		assert.Nil(t, session)
+	})
+
+	t.Run("revocation", func(t *testing.T) {
+		assert.NoError(t, repo.RevokeSession(ctx, "older", now))
+		assert.NoError(t, repo.RevokeUserSessions(ctx, 1, now.Add(time.Minute)))
+
+		older, _ := repo.GetSessionByID(ctx, "older")
+		newer, _ := repo.GetSessionByID(ctx, "newer")
+		assert.True(t, older.RevokedAt.Equal(now))
+		assert.True(t, newer.RevokedAt.Equal(now.Add(time.Minute)))
+	})
+
+	t.Run("revoke links are single use", func(t *testing.T) {
+		assert.NoError(t, repo.CreateRevokeLink(ctx, model.RevokeLink{ID: "link", UserID: 1, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+
+		link, err := repo.ConsumeRevokeLink(ctx, "link", now)
+		assert.NoError(t, err)
+		if assert.NotNil(t, link) {
+			assert.Equal(t, 1, link.UserID)
+			assert.True(t, link.UsedAt.Equal(now))
+		}
+
+		link, err = repo.ConsumeRevokeLink(ctx, "link", now)
+		assert.NoError(t, err)
+		assert.Nil(t, link)
+	})
+
+	t.Run("deleting the user removes its sessions", func(t *testing.T) {
+		assert.NoError(t, users.DeleteUser(ctx, 1))
+
+		sessions, err := repo.GetSessionsByUserID(ctx, 1, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// sqliteTime converts a time to UTC before it is stored. SQLite keeps times
+// as text, so they only compare and sort correctly in a single time zone.
+func sqliteTime(value time.Time) time.Time {
+	return value.UTC()
+}
+
+// sqliteTimePtr maps a nil time to a SQL NULL and converts the others to UTC.
+func sqliteTimePtr(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: sqliteTime(*value), Valid: true}
+}
+
+// execInTransaction runs the queries in order with the same arguments, in a
+// single transaction. SQLite does not enforce foreign keys unless they are
+// enabled on each connection, so the rows that reference a deleted row are
+// removed explicitly.
+func execInTransaction(ctx context.Context, db *sql.DB, queries []string, args ...interface{}) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return queryError(ctx, query, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -1,8 +1,10 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"exercise-login-back-go/internal/model"
+	"fmt"
 )
 
 type userRepository struct {
@@ -15,51 +17,180 @@ func NewUserRepository(db *sql.DB) *userRepository {
 }
 
 // CreateUser creates a new user in the database.
-func (r *userRepository) CreateUser(user model.User) error {
-	query := "EXEC CreateUser @Username = @p1, @Email = @p2, @Phone = @p3, @Password = @p4"
-	_, err := r.db.Exec(query,
+func (r *userRepository) CreateUser(ctx context.Context, user model.User) error {
+	query := "EXEC CreateUser @Username = @p1, @Email = @p2, @Phone = @p3, @Password = @p4, @Disabled = @p5, @Locale = @p6, @SCIMClientID = @p7"
+	_, err := r.db.ExecContext(ctx, query,
 		sql.Named("p1", user.Username),
 		sql.Named("p2", user.Email),
-		sql.Named("p3", user.Phone),
-		sql.Named("p4", user.Password))
+		sql.Named("p3", nullableString(user.Phone)),
+		sql.Named("p4", user.Password),
+		sql.Named("p5", user.Disabled),
+		sql.Named("p6", nullableString(user.Locale)),
+		sql.Named("p7", nullableInt(user.SCIMClientID)))
 	if err != nil {
-		return err
+		return userWriteError(ctx, query, err)
 	}
 	return nil
 }
 
+// GetUserByID retrieves a user by their identifier
+func (r *userRepository) GetUserByID(ctx context.Context, id int) (*model.User, error) {
+	query := "EXEC GetUserByID @ID = @p1"
+	row := r.db.QueryRowContext(ctx, query, sql.Named("p1", id))
+
+	user, err := scanUser(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No user was found, which is not necessarily an error
+		}
+		// Handle other possible errors
+		return nil, queryError(ctx, query, err)
+	}
+
+	return user, nil
+}
+
 // GetUserByEmailOrUsername retrieves a user by their email or username
-func (r *userRepository) GetUserByEmailOrUsername(emailOrUsername string) (*model.User, error) {
-	var user model.User
+func (r *userRepository) GetUserByEmailOrUsername(ctx context.Context, emailOrUsername string) (*model.User, error) {
 	query := "EXEC GetUserByEmailOrUsername @EmailOrUsername = @p1"
-	row := r.db.QueryRow(query, sql.Named("p1", emailOrUsername))
+	row := r.db.QueryRowContext(ctx, query, sql.Named("p1", emailOrUsername))
 
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Phone, &user.Password)
+	user, err := scanUser(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No user was found, which is not necessarily an error
 		}
 		// Handle other possible errors
-		return nil, err
+		return nil, queryError(ctx, query, err)
 	}
 
-	return &user, nil
+	return user, nil
+}
+
+// GetUserByEmail retrieves a user by their email only
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	query := "EXEC GetUserByEmail @Email = @p1"
+	row := r.db.QueryRowContext(ctx, query, sql.Named("p1", email))
+
+	user, err := scanUser(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No user was found, which is not necessarily an error
+		}
+		// Handle other possible errors
+		return nil, queryError(ctx, query, err)
+	}
+
+	return user, nil
 }
 
 // GetUserByEmailOrPhone retrieves a user by their email or phone number
-func (r *userRepository) GetUserByEmailOrPhone(email, phone string) (*model.User, error) {
-	var user model.User
+func (r *userRepository) GetUserByEmailOrPhone(ctx context.Context, email, phone string) (*model.User, error) {
 	query := "EXEC GetUserByEmailOrPhone @Email = @p1, @Phone = @p2"
-	row := r.db.QueryRow(query, sql.Named("p1", email), sql.Named("p2", phone))
+	row := r.db.QueryRowContext(ctx, query, sql.Named("p1", email), sql.Named("p2", phone))
 
-	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Phone, &user.Password)
+	user, err := scanUser(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No user was found, which is not necessarily an error
 		}
 		// Handle other possible errors
-		return nil, err
+		return nil, queryError(ctx, query, err)
 	}
 
+	return user, nil
+}
+
+// GetUsers retrieves a page of the users matching the filter, ordered by
+// identifier, together with the total number of matching users.
+func (r *userRepository) GetUsers(ctx context.Context, filter model.UserFilter) ([]model.User, int, error) {
+	query := "EXEC GetUsers @Username = @p1, @Email = @p2, @Offset = @p3, @Limit = @p4, @SCIMClientID = @p5"
+	rows, err := r.db.QueryContext(ctx, query,
+		sql.Named("p1", nullableString(filter.Username)),
+		sql.Named("p2", nullableString(filter.Email)),
+		sql.Named("p3", filter.Offset),
+		sql.Named("p4", nullableInt(filter.Limit)),
+		sql.Named("p5", nullableInt(filter.SCIMClientID)))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	total := 0
+	for rows.Next() {
+		var user model.User
+		var phone, locale sql.NullString
+		var scimClientID sql.NullInt64
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &phone, &user.Password, &user.Disabled, &locale, &scimClientID, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		user.Phone = phone.String
+		user.Locale = locale.String
+		user.SCIMClientID = int(scimClientID.Int64)
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// UpdateUser saves the username, email, phone, password, status and locale of a user.
+func (r *userRepository) UpdateUser(ctx context.Context, user model.User) error {
+	query := "EXEC UpdateUser @ID = @p1, @Username = @p2, @Email = @p3, @Phone = @p4, @Password = @p5, @Disabled = @p6, @Locale = @p7"
+	_, err := r.db.ExecContext(ctx, query,
+		sql.Named("p1", user.ID),
+		sql.Named("p2", user.Username),
+		sql.Named("p3", user.Email),
+		sql.Named("p4", nullableString(user.Phone)),
+		sql.Named("p5", user.Password),
+		sql.Named("p6", user.Disabled),
+		sql.Named("p7", nullableString(user.Locale)))
+	if err != nil {
+		return userWriteError(ctx, query, err)
+	}
+	return nil
+}
+
+// DeleteUser removes a user together with their sessions, keys, tokens and
+// linked identities.
+func (r *userRepository) DeleteUser(ctx context.Context, id int) error {
+	query := "EXEC DeleteUser @ID = @p1"
+	_, err := r.db.ExecContext(ctx, query, sql.Named("p1", id))
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// scanUser reads a user row
+func scanUser(row rowScanner) (*model.User, error) {
+	var user model.User
+	var phone, locale sql.NullString
+	var scimClientID sql.NullInt64
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &phone, &user.Password, &user.Disabled, &locale, &scimClientID)
+	if err != nil {
+		return nil, err
+	}
+	user.Phone = phone.String
+	user.Locale = locale.String
+	user.SCIMClientID = int(scimClientID.Int64)
 	return &user, nil
 }
+
+// NewUserRepositoryForDriver creates the user repository matching the
+// database driver.
+func NewUserRepositoryForDriver(driver string, db *sql.DB) (model.UserRepository, error) {
+	switch driver {
+	case "sqlserver", "mssql":
+		return NewUserRepository(db), nil
+	case "postgres":
+		return NewPostgresUserRepository(db), nil
+	case "sqlite":
+		return NewSQLiteUserRepository(db), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
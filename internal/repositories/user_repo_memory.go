@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrDuplicateEmail and ErrDuplicatePhone are returned by the in-memory
+	// repository when a write violates the unique constraints of the users
+	// table. Both match model.ErrDuplicateUser.
+	ErrDuplicateEmail = fmt.Errorf("duplicate email: %w", model.ErrDuplicateUser)
+	ErrDuplicatePhone = fmt.Errorf("duplicate phone: %w", model.ErrDuplicateUser)
+)
+
+type memoryUserRepository struct {
+	mu     sync.RWMutex
+	users  []model.User
+	nextID int
+}
+
+// NewMemoryUserRepository creates a user repository that keeps the users in
+// memory. It is safe for concurrent use and enforces the same unique email
+// and phone constraints as the database schema, which makes it a realistic
+// replacement for the database in tests.
+func NewMemoryUserRepository() *memoryUserRepository {
+	return &memoryUserRepository{nextID: 1}
+}
+
+// CreateUser creates a new user, assigning the next identifier.
+func (r *memoryUserRepository) CreateUser(ctx context.Context, user model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(user); err != nil {
+		return err
+	}
+	user.ID = r.nextID
+	r.nextID++
+	r.users = append(r.users, user)
+	return nil
+}
+
+// GetUserByID retrieves a user by their identifier
+func (r *memoryUserRepository) GetUserByID(ctx context.Context, id int) (*model.User, error) {
+	return r.find(func(u model.User) bool { return u.ID == id }), nil
+}
+
+// GetUserByEmailOrUsername retrieves a user by their email or username
+func (r *memoryUserRepository) GetUserByEmailOrUsername(ctx context.Context, emailOrUsername string) (*model.User, error) {
+	return r.find(func(u model.User) bool { return u.Email == emailOrUsername || u.Username == emailOrUsername }), nil
+}
+
+// GetUserByEmail retrieves a user by their email only
+func (r *memoryUserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.find(func(u model.User) bool { return u.Email == email }), nil
+}
+
+// GetUserByEmailOrPhone retrieves a user by their email or phone number
+func (r *memoryUserRepository) GetUserByEmailOrPhone(ctx context.Context, email, phone string) (*model.User, error) {
+	return r.find(func(u model.User) bool { return u.Email == email || (u.Phone != "" && u.Phone == phone) }), nil
+}
+
+// GetUsers retrieves a page of the users matching the filter, ordered by
+// identifier, together with the total number of matching users.
+func (r *memoryUserRepository) GetUsers(ctx context.Context, filter model.UserFilter) ([]model.User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matching := []model.User{}
+	for _, user := range r.users {
+		if (filter.Username == "" || user.Username == filter.Username) && (filter.Email == "" || user.Email == filter.Email) &&
+			(filter.SCIMClientID == 0 || user.SCIMClientID == filter.SCIMClientID) {
+			matching = append(matching, user)
+		}
+	}
+
+	total := len(matching)
+	if filter.Offset >= total {
+		return []model.User{}, total, nil
+	}
+	page := matching[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(page) {
+		page = page[:filter.Limit]
+	}
+	return page, total, nil
+}
+
+// UpdateUser saves the username, email, phone, password, status and locale of a user.
+// Updating a user that does not exist does nothing, as in SQL. The client that
+// provisioned the user is kept.
+func (r *memoryUserRepository) UpdateUser(ctx context.Context, user model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.users {
+		if r.users[i].ID == user.ID {
+			if err := r.checkUnique(user); err != nil {
+				return err
+			}
+			user.SCIMClientID = r.users[i].SCIMClientID
+			r.users[i] = user
+			return nil
+		}
+	}
+	return nil
+}
+
+// DeleteUser removes a user.
+func (r *memoryUserRepository) DeleteUser(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.users {
+		if r.users[i].ID == id {
+			r.users = append(r.users[:i], r.users[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// find returns a copy of the first user matching the condition, or nil
+func (r *memoryUserRepository) find(match func(model.User) bool) *model.User {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if match(user) {
+			found := user
+			return &found
+		}
+	}
+	return nil // No user was found, which is not necessarily an error
+}
+
+// checkUnique rejects an email or phone used by another user. The caller
+// must hold the write lock.
+func (r *memoryUserRepository) checkUnique(user model.User) error {
+	for _, existing := range r.users {
+		if existing.ID == user.ID {
+			continue
+		}
+		if existing.Email == user.Email {
+			return ErrDuplicateEmail
+		}
+		if user.Phone != "" && existing.Phone == user.Phone {
+			return ErrDuplicatePhone
+		}
+	}
+	return nil
+}
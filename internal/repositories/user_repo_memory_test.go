@@ -0,0 +1,87 @@
+package repositories_test
+
+import (
+	"context"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/repositories"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	repo := repositories.NewMemoryUserRepository()
+
+	assert.NoError(t, repo.CreateUser(context.Background(), model.User{Username: "jdoe", Email: "jdoe@example.com", Phone: "5512345678", Password: "hash"}))
+	assert.NoError(t, repo.CreateUser(context.Background(), model.User{Username: "asmith", Email: "asmith@example.com", Password: "hash"}))
+
+	t.Run("email and phone are unique", func(t *testing.T) {
+		err := repo.CreateUser(context.Background(), model.User{Username: "other", Email: "jdoe@example.com", Password: "hash"})
+		assert.ErrorIs(t, err, repositories.ErrDuplicateEmail)
+
+		err = repo.CreateUser(context.Background(), model.User{Username: "other", Email: "other@example.com", Phone: "5512345678", Password: "hash"})
+		assert.ErrorIs(t, err, repositories.ErrDuplicatePhone)
+
+		err = repo.UpdateUser(context.Background(), model.User{ID: 2, Username: "asmith", Email: "asmith@example.com", Phone: "5512345678"})
+		assert.ErrorIs(t, err, repositories.ErrDuplicatePhone)
+	})
+
+	t.Run("returned users are copies", func(t *testing.T) {
+		user, err := repo.GetUserByEmailOrUsername(context.Background(), "jdoe")
+		assert.NoError(t, err)
+		user.Email = "changed@example.com"
+
+		stored, _ := repo.GetUserByID(context.Background(), user.ID)
+		assert.Equal(t, "jdoe@example.com", stored.Email)
+	})
+
+	t.Run("email lookups ignore usernames", func(t *testing.T) {
+		user, err := repo.GetUserByEmail(context.Background(), "jdoe")
+		assert.NoError(t, err)
+		assert.Nil(t, user)
+
+		user, err = repo.GetUserByEmail(context.Background(), "jdoe@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, user.ID)
+	})
+
+	t.Run("users without phone do not match an empty phone", func(t *testing.T) {
+		user, err := repo.GetUserByEmailOrPhone(context.Background(), "nobody@example.com", "")
+		assert.NoError(t, err)
+		assert.Nil(t, user)
+	})
+
+	t.Run("paging", func(t *testing.T) {
+		users, total, err := repo.GetUsers(context.Background(), model.UserFilter{Offset: 1, Limit: 5})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, []model.User{{ID: 2, Username: "asmith", Email: "asmith@example.com", Password: "hash"}}, users)
+	})
+}
+
+func TestMemoryUserRepositoryConcurrentCreates(t *testing.T) {
+	repo := repositories.NewMemoryUserRepository()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := repo.CreateUser(context.Background(), model.User{Username: fmt.Sprintf("user%d", i), Email: "same@example.com", Password: "hash"})
+			if err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, created)
+	_, total, _ := repo.GetUsers(context.Background(), model.UserFilter{})
+	assert.Equal(t, 1, total)
+}
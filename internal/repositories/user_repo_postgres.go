@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+)
+
+// userColumns are the columns read by scanUser
+const userColumns = "id, username, email, phone, password, disabled, locale, scim_client_id"
+
+type postgresUserRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresUserRepository creates a user repository for PostgreSQL. It uses
+// plain parameterized queries instead of stored procedures.
+func NewPostgresUserRepository(db *sql.DB) *postgresUserRepository {
+	return &postgresUserRepository{db: db}
+}
+
+// CreateUser creates a new user in the database.
+func (r *postgresUserRepository) CreateUser(ctx context.Context, user model.User) error {
+	query := "INSERT INTO users (username, email, phone, password, disabled, locale, scim_client_id) VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	_, err := r.db.ExecContext(ctx, query, user.Username, user.Email, nullableString(user.Phone), user.Password, user.Disabled, nullableString(user.Locale), nullableInt(user.SCIMClientID))
+	if err != nil {
+		return userWriteError(ctx, query, err)
+	}
+	return nil
+}
+
+// GetUserByID retrieves a user by their identifier
+func (r *postgresUserRepository) GetUserByID(ctx context.Context, id int) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE id = $1"
+	return r.getUser(ctx, query, id)
+}
+
+// GetUserByEmailOrUsername retrieves a user by their email or username
+func (r *postgresUserRepository) GetUserByEmailOrUsername(ctx context.Context, emailOrUsername string) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE email = $1 OR username = $1 ORDER BY id LIMIT 1"
+	return r.getUser(ctx, query, emailOrUsername)
+}
+
+// GetUserByEmail retrieves a user by their email only
+func (r *postgresUserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE email = $1"
+	return r.getUser(ctx, query, email)
+}
+
+// GetUserByEmailOrPhone retrieves a user by their email or phone number
+func (r *postgresUserRepository) GetUserByEmailOrPhone(ctx context.Context, email, phone string) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE email = $1 OR phone = $2 ORDER BY id LIMIT 1"
+	return r.getUser(ctx, query, email, phone)
+}
+
+// GetUsers retrieves a page of the users matching the filter, ordered by
+// identifier, together with the total number of matching users.
+func (r *postgresUserRepository) GetUsers(ctx context.Context, filter model.UserFilter) ([]model.User, int, error) {
+	query := "SELECT " + userColumns + ", COUNT(*) OVER() FROM users" +
+		" WHERE ($1::text IS NULL OR username = $1) AND ($2::text IS NULL OR email = $2) AND ($5::integer IS NULL OR scim_client_id = $5)" +
+		" ORDER BY id OFFSET $3 LIMIT $4"
+	rows, err := r.db.QueryContext(ctx, query,
+		nullableString(filter.Username),
+		nullableString(filter.Email),
+		filter.Offset,
+		nullableInt(filter.Limit),
+		nullableInt(filter.SCIMClientID))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	total := 0
+	for rows.Next() {
+		var user model.User
+		var phone, locale sql.NullString
+		var scimClientID sql.NullInt64
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &phone, &user.Password, &user.Disabled, &locale, &scimClientID, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		user.Phone = phone.String
+		user.Locale = locale.String
+		user.SCIMClientID = int(scimClientID.Int64)
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// UpdateUser saves the username, email, phone, password, status and locale of a user.
+func (r *postgresUserRepository) UpdateUser(ctx context.Context, user model.User) error {
+	query := "UPDATE users SET username = $2, email = $3, phone = $4, password = $5, disabled = $6, locale = $7 WHERE id = $1"
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Username, user.Email, nullableString(user.Phone), user.Password, user.Disabled, nullableString(user.Locale))
+	if err != nil {
+		return userWriteError(ctx, query, err)
+	}
+	return nil
+}
+
+// DeleteUser removes a user. The rows that belong to the user are removed by
+// the ON DELETE CASCADE foreign keys created by the postgres migrations; its
+// audit events are kept.
+func (r *postgresUserRepository) DeleteUser(ctx context.Context, id int) error {
+	query := "DELETE FROM users WHERE id = $1"
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return queryError(ctx, query, err)
+	}
+	return nil
+}
+
+// getUser runs a query returning at most one user
+func (r *postgresUserRepository) getUser(ctx context.Context, query string, args ...interface{}) (*model.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No user was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return user, nil
+}
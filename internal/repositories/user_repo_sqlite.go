@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/model"
+)
+
+type sqliteUserRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteUserRepository creates a user repository for an embedded SQLite
+// database. The tables are created by the sqlite migrations.
+func NewSQLiteUserRepository(db *sql.DB) *sqliteUserRepository {
+	return &sqliteUserRepository{db: db}
+}
+
+// CreateUser creates a new user in the database.
+func (r *sqliteUserRepository) CreateUser(ctx context.Context, user model.User) error {
+	query := "INSERT INTO users (username, email, phone, password, disabled, locale, scim_client_id) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	_, err := r.db.ExecContext(ctx, query, user.Username, user.Email, nullableString(user.Phone), user.Password, user.Disabled, nullableString(user.Locale), nullableInt(user.SCIMClientID))
+	if err != nil {
+		return userWriteError(ctx, query, err)
+	}
+	return nil
+}
+
+// GetUserByID retrieves a user by their identifier
+func (r *sqliteUserRepository) GetUserByID(ctx context.Context, id int) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE id = ?"
+	return r.getUser(ctx, query, id)
+}
+
+// GetUserByEmailOrUsername retrieves a user by their email or username
+func (r *sqliteUserRepository) GetUserByEmailOrUsername(ctx context.Context, emailOrUsername string) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE email = ?1 OR username = ?1 ORDER BY id LIMIT 1"
+	return r.getUser(ctx, query, emailOrUsername)
+}
+
+// GetUserByEmail retrieves a user by their email only
+func (r *sqliteUserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE email = ?"
+	return r.getUser(ctx, query, email)
+}
+
+// GetUserByEmailOrPhone retrieves a user by their email or phone number
+func (r *sqliteUserRepository) GetUserByEmailOrPhone(ctx context.Context, email, phone string) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE email = ? OR phone = ? ORDER BY id LIMIT 1"
+	return r.getUser(ctx, query, email, phone)
+}
+
+// GetUsers retrieves a page of the users matching the filter, ordered by
+// identifier, together with the total number of matching users.
+func (r *sqliteUserRepository) GetUsers(ctx context.Context, filter model.UserFilter) ([]model.User, int, error) {
+	// A negative limit means no limit in SQLite.
+	limit := filter.Limit
+	if limit == 0 {
+		limit = -1
+	}
+	query := "SELECT " + userColumns + ", COUNT(*) OVER() FROM users" +
+		" WHERE (?1 IS NULL OR username = ?1) AND (?2 IS NULL OR email = ?2) AND (?5 IS NULL OR scim_client_id = ?5)" +
+		" ORDER BY id LIMIT ?3 OFFSET ?4"
+	rows, err := r.db.QueryContext(ctx, query, nullableString(filter.Username), nullableString(filter.Email), limit, filter.Offset, nullableInt(filter.SCIMClientID))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	total := 0
+	for rows.Next() {
+		var user model.User
+		var phone, locale sql.NullString
+		var scimClientID sql.NullInt64
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &phone, &user.Password, &user.Disabled, &locale, &scimClientID, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		user.Phone = phone.String
+		user.Locale = locale.String
+		user.SCIMClientID = int(scimClientID.Int64)
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// UpdateUser saves the username, email, phone, password, status and locale of a user.
+func (r *sqliteUserRepository) UpdateUser(ctx context.Context, user model.User) error {
+	query := "UPDATE users SET username = ?, email = ?, phone = ?, password = ?, disabled = ?, locale = ? WHERE id = ?"
+	_, err := r.db.ExecContext(ctx, query, user.Username, user.Email, nullableString(user.Phone), user.Password, user.Disabled, nullableString(user.Locale), user.ID)
+	if err != nil {
+		return userWriteError(ctx, query, err)
+	}
+	return nil
+}
+
+// DeleteUser removes a user together with the rows that belong to them; its
+// audit events are kept.
+func (r *sqliteUserRepository) DeleteUser(ctx context.Context, id int) error {
+	return execInTransaction(ctx, r.db, []string{
+		"DELETE FROM sessions WHERE user_id = ?",
+		"DELETE FROM revoke_links WHERE user_id = ?",
+		"DELETE FROM api_keys WHERE user_id = ?",
+		"DELETE FROM oauth_authorization_codes WHERE user_id = ?",
+		"DELETE FROM oauth_tokens WHERE user_id = ?",
+		"DELETE FROM user_identities WHERE user_id = ?",
+		"DELETE FROM social_login_states WHERE user_id = ?",
+		"DELETE FROM users WHERE id = ?",
+	}, id)
+}
+
+// getUser runs a query returning at most one user
+func (r *sqliteUserRepository) getUser(ctx context.Context, query string, args ...interface{}) (*model.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No user was found, which is not necessarily an error
+		}
+		return nil, queryError(ctx, query, err)
+	}
+	return user, nil
+}
@@ -0,0 +1,129 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"exercise-login-back-go/internal/migrations"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/repositories"
+	"exercise-login-back-go/pkg/db"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSQLiteDatabase opens a fresh in-memory database with the schema created
+// by the migrations
+func newSQLiteDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	database, err := db.InitializeDatabase("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	migrator, err := migrations.New(database, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+// newSQLiteUserRepository opens a fresh in-memory database with the user
+// repository on top of it
+func newSQLiteUserRepository(t *testing.T) model.UserRepository {
+	t.Helper()
+	repo, err := repositories.NewUserRepositoryForDriver("sqlite", newSQLiteDatabase(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestSQLiteUserRepository(t *testing.T) {
+	repo := newSQLiteUserRepository(t)
+
+	assert.NoError(t, repo.CreateUser(context.Background(), model.User{Username: "jdoe", Email: "jdoe@example.com", Phone: "5512345678", Password: "hash"}))
+	assert.NoError(t, repo.CreateUser(context.Background(), model.User{Username: "asmith", Email: "asmith@example.com", Password: "hash", Disabled: true}))
+
+	t.Run("lookups", func(t *testing.T) {
+		user, err := repo.GetUserByEmailOrUsername(context.Background(), "jdoe")
+		assert.NoError(t, err)
+		assert.Equal(t, &model.User{ID: 1, Username: "jdoe", Email: "jdoe@example.com", Phone: "5512345678", Password: "hash"}, user)
+
+		user, err = repo.GetUserByEmailOrPhone(context.Background(), "", "5512345678")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, user.ID)
+
+		user, err = repo.GetUserByID(context.Background(), 2)
+		assert.NoError(t, err)
+		assert.True(t, user.Disabled)
+		assert.Empty(t, user.Phone)
+
+		user, err = repo.GetUserByEmail(context.Background(), "asmith@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, user.ID)
+	})
+
+	t.Run("not found is not an error", func(t *testing.T) {
+		user, err := repo.GetUserByID(context.Background(), 99)
+		assert.NoError(t, err)
+		assert.Nil(t, user)
+
+		user, err = repo.GetUserByEmailOrPhone(context.Background(), "nobody@example.com", "")
+		assert.NoError(t, err)
+		assert.Nil(t, user)
+	})
+
+	t.Run("email and phone are unique", func(t *testing.T) {
+		err := repo.CreateUser(context.Background(), model.User{Username: "other", Email: "jdoe@example.com", Password: "hash"})
+		assert.ErrorIs(t, err, model.ErrDuplicateUser)
+		err = repo.CreateUser(context.Background(), model.User{Username: "other", Email: "other@example.com", Phone: "5512345678", Password: "hash"})
+		assert.ErrorIs(t, err, model.ErrDuplicateUser)
+	})
+
+	t.Run("paging and filters", func(t *testing.T) {
+		users, total, err := repo.GetUsers(context.Background(), model.UserFilter{Offset: 1, Limit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Len(t, users, 1)
+		assert.Equal(t, "asmith", users[0].Username)
+
+		users, total, err = repo.GetUsers(context.Background(), model.UserFilter{Email: "jdoe@example.com"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "jdoe", users[0].Username)
+
+		users, total, err = repo.GetUsers(context.Background(), model.UserFilter{SCIMClientID: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, total)
+		assert.Empty(t, users)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		user, _ := repo.GetUserByID(context.Background(), 1)
+		user.Phone = ""
+		user.Disabled = true
+		user.Locale = "en"
+		assert.NoError(t, repo.UpdateUser(context.Background(), *user))
+
+		updated, _ := repo.GetUserByID(context.Background(), 1)
+		assert.Equal(t, user, updated)
+
+		assert.NoError(t, repo.DeleteUser(context.Background(), 1))
+		deleted, err := repo.GetUserByID(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Nil(t, deleted)
+	})
+
+	t.Run("email lookups ignore usernames", func(t *testing.T) {
+		assert.NoError(t, repo.CreateUser(context.Background(), model.User{Username: "victim@example.com", Email: "mallory@example.com", Password: "hash"}))
+
+		user, err := repo.GetUserByEmail(context.Background(), "victim@example.com")
+		assert.NoError(t, err)
+		assert.Nil(t, user)
+	})
+}
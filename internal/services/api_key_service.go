@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/model"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeyPrefix marks the keys issued by this service.
+	apiKeyPrefix = "lgk_"
+	// apiKeyLastUsedResolution avoids writing to the database on every request.
+	apiKeyLastUsedResolution = time.Minute
+)
+
+var (
+	ErrAPIKeyNotFound = newError(KindNotFound, "api_key_not_found", nil)
+	ErrInvalidAPIKey  = newError(KindUnauthorized, "invalid_api_key", nil)
+	ErrAPIKeyExpired  = newError(KindUnauthorized, "invalid_api_key.expired", nil)
+)
+
+// validScopes lists the scopes that can be granted to an API key.
+var validScopes = map[string]bool{
+	model.ScopeSessionsRead:  true,
+	model.ScopeSessionsWrite: true,
+	model.ScopeAuditRead:     true,
+	model.ScopeOAuthClients:  true,
+	model.ScopeSCIMClients:   true,
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, userID int, username string, req model.APIKeyCreateRequest, meta model.RequestMetadata) (*model.APIKey, string, error)
+	GetAPIKeys(ctx context.Context, userID int) ([]model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID int, username string, keyID int, meta model.RequestMetadata) error
+	Authenticate(ctx context.Context, key string) (*model.Claims, *model.APIKey, error)
+}
+
+type apiKeyServiceImpl struct {
+	repo        model.APIKeyRepository
+	userRepo    model.UserRepository
+	auditLogger AuditLogger
+}
+
+func NewAPIKeyService(repo model.APIKeyRepository, userRepo model.UserRepository, auditLogger AuditLogger) *apiKeyServiceImpl {
+	return &apiKeyServiceImpl{
+		repo:        repo,
+		userRepo:    userRepo,
+		auditLogger: auditLogger,
+	}
+}
+
+// CreateAPIKey issues a new API key for the user. The returned secret is the
+// full key and cannot be recovered afterwards.
+func (s *apiKeyServiceImpl) CreateAPIKey(ctx context.Context, userID int, username string, req model.APIKeyCreateRequest, meta model.RequestMetadata) (*model.APIKey, string, error) {
+	// A key without scopes could not be used for anything.
+	if len(req.Scopes) == 0 {
+		return nil, "", invalidField("scopes", "invalid_scope.empty", nil)
+	}
+	for _, scope := range req.Scopes {
+		if !validScopes[scope] {
+			return nil, "", invalidField("scopes", "invalid_scope", i18n.Params{"scope": scope})
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", invalidField("expiresAt", "invalid_expiration", nil)
+	}
+
+	prefixPart, err := randomToken(4)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := model.APIKey{
+		UserID:     userID,
+		Name:       strings.TrimSpace(req.Name),
+		Prefix:     apiKeyPrefix + prefixPart,
+		SecretHash: hashSecret(secret),
+		Scopes:     req.Scopes,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	key.ID, err = s.repo.CreateAPIKey(ctx, key)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create the API key", "error", err)
+		return nil, "", errors.New("error al crear la clave de API")
+	}
+
+	s.auditLogger.LogEvent(ctx, model.AuditEvent{
+		EventType: model.AuditEventAPIKeyCreation,
+		UserID:    userID,
+		Actor:     username,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    "api key " + key.Prefix,
+	})
+	return &key, key.Prefix + "_" + secret, nil
+}
+
+// GetAPIKeys returns every API key of the user, without their secrets.
+func (s *apiKeyServiceImpl) GetAPIKeys(ctx context.Context, userID int) ([]model.APIKey, error) {
+	return s.repo.GetAPIKeysByUserID(ctx, userID)
+}
+
+// RevokeAPIKey revokes one of the user's API keys.
+func (s *apiKeyServiceImpl) RevokeAPIKey(ctx context.Context, userID int, username string, keyID int, meta model.RequestMetadata) error {
+	key, err := s.repo.GetAPIKeyByID(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if key == nil || key.UserID != userID {
+		return ErrAPIKeyNotFound
+	}
+
+	event := model.AuditEvent{
+		EventType: model.AuditEventTokenRevocation,
+		UserID:    userID,
+		Actor:     username,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    "api key " + key.Prefix,
+	}
+	if key.RevokedAt == nil {
+		if err := s.repo.RevokeAPIKey(ctx, key.ID, time.Now().UTC()); err != nil {
+			event.Outcome = model.AuditOutcomeFailure
+			event.Detail = err.Error()
+			s.auditLogger.LogEvent(ctx, event)
+			return err
+		}
+	}
+	s.auditLogger.LogEvent(ctx, event)
+	return nil
+}
+
+// Authenticate verifies a full API key and returns the claims of its owner.
+func (s *apiKeyServiceImpl) Authenticate(ctx context.Context, fullKey string) (*model.Claims, *model.APIKey, error) {
+	separator := strings.LastIndex(fullKey, "_")
+	if !strings.HasPrefix(fullKey, apiKeyPrefix) || separator <= len(apiKeyPrefix) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	prefix, secret := fullKey[:separator], fullKey[separator+1:]
+
+	key, err := s.repo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	// Expiry is only reported to callers that know the secret
+	now := time.Now().UTC()
+	if !key.IsActive(now) {
+		if key.RevokedAt == nil {
+			return nil, nil, ErrAPIKeyExpired
+		}
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || user.Disabled {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedResolution {
+		if err := s.repo.UpdateAPIKeyLastUsed(ctx, key.ID, now); err != nil {
+			slog.WarnContext(ctx, "Failed to update last used time of API key", "prefix", key.Prefix, "error", err)
+		}
+	}
+
+	claims := &model.Claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Locale:   user.Locale,
+	}
+	return claims, key, nil
+}
+
+// hashSecret hashes a generated secret such as the secret part of an API key.
+// Secrets are long random strings, so a fast hash is enough to protect them at rest.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,122 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateAndAuthenticateAPIKey(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	mockUserRepo := new(mocks.UserRepository)
+	mockAudit := new(mocks.AuditLogger)
+	service := services.NewAPIKeyService(mockRepo, mockUserRepo, mockAudit)
+
+	var stored model.APIKey
+	mockRepo.On("CreateAPIKey", mock.Anything, mock.AnythingOfType("model.APIKey")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(model.APIKey) }).
+		Return(3, nil)
+	mockAudit.On("LogEvent", mock.Anything, mock.AnythingOfType("model.AuditEvent")).Return()
+
+	req := model.APIKeyCreateRequest{Name: " deploy script ", Scopes: []string{model.ScopeSessionsRead}}
+	key, secret, err := service.CreateAPIKey(context.Background(), 7, "testuser", req, model.RequestMetadata{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, key.ID)
+	assert.Equal(t, "deploy script", key.Name)
+	assert.True(t, strings.HasPrefix(secret, key.Prefix+"_"))
+	assert.NotContains(t, stored.SecretHash, secret[len(key.Prefix)+1:])
+
+	stored.ID = 3
+	mockRepo.On("GetAPIKeyByPrefix", mock.Anything, key.Prefix).Return(&stored, nil)
+	mockRepo.On("UpdateAPIKeyLastUsed", mock.Anything, 3, mock.AnythingOfType("time.Time")).Return(nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, 7).Return(&model.User{ID: 7, Username: "testuser"}, nil)
+
+	t.Run("valid key", func(t *testing.T) {
+		claims, authenticated, err := service.Authenticate(context.Background(), secret)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, claims.UserID)
+		assert.Equal(t, "testuser", claims.Username)
+		assert.True(t, authenticated.HasScope(model.ScopeSessionsRead))
+		assert.False(t, authenticated.HasScope(model.ScopeAuditRead))
+		assert.False(t, model.APIKey{}.HasScope(model.ScopeAuditRead))
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, _, err := service.Authenticate(context.Background(), key.Prefix+"_"+strings.Repeat("0", 64))
+		assert.ErrorIs(t, err, services.ErrInvalidAPIKey)
+	})
+
+	t.Run("malformed key", func(t *testing.T) {
+		_, _, err := service.Authenticate(context.Background(), "not-a-key")
+		assert.ErrorIs(t, err, services.ErrInvalidAPIKey)
+	})
+}
+
+func TestAuthenticateInactiveAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	sum := sha256.Sum256([]byte("secret"))
+	secretHash := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name   string
+		key    model.APIKey
+		secret string
+		want   string
+	}{
+		{"expired", model.APIKey{ID: 1, UserID: 7, Prefix: "lgk_aaaaaaaa", SecretHash: secretHash, ExpiresAt: &past}, "secret", "invalid_api_key.expired"},
+		{"expired with wrong secret", model.APIKey{ID: 1, UserID: 7, Prefix: "lgk_aaaaaaaa", SecretHash: secretHash, ExpiresAt: &past}, "wrong", "invalid_api_key"},
+		{"revoked", model.APIKey{ID: 1, UserID: 7, Prefix: "lgk_aaaaaaaa", SecretHash: secretHash, RevokedAt: &past}, "secret", "invalid_api_key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.APIKeyRepository)
+			service := services.NewAPIKeyService(mockRepo, new(mocks.UserRepository), new(mocks.AuditLogger))
+			mockRepo.On("GetAPIKeyByPrefix", mock.Anything, "lgk_aaaaaaaa").Return(&tt.key, nil)
+
+			_, _, err := service.Authenticate(context.Background(), "lgk_aaaaaaaa_"+tt.secret)
+			assert.ErrorIs(t, err, services.ErrInvalidAPIKey)
+			var serviceErr *services.Error
+			if assert.ErrorAs(t, err, &serviceErr) {
+				assert.Equal(t, tt.want, serviceErr.Key)
+			}
+		})
+	}
+}
+
+func TestCreateAPIKeyValidation(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	service := services.NewAPIKeyService(mockRepo, new(mocks.UserRepository), new(mocks.AuditLogger))
+	past := time.Now().Add(-time.Hour)
+
+	_, _, err := service.CreateAPIKey(context.Background(), 7, "testuser", model.APIKeyCreateRequest{Name: "x", Scopes: []string{"everything"}}, model.RequestMetadata{})
+	assert.Error(t, err)
+
+	_, _, err = service.CreateAPIKey(context.Background(), 7, "testuser", model.APIKeyCreateRequest{Name: "x", Scopes: []string{model.ScopeSessionsRead}, ExpiresAt: &past}, model.RequestMetadata{})
+	assert.Error(t, err)
+
+	// A key without scopes would grant nothing.
+	_, _, err = service.CreateAPIKey(context.Background(), 7, "testuser", model.APIKeyCreateRequest{Name: "x"}, model.RequestMetadata{})
+	assert.Error(t, err)
+
+	mockRepo.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestRevokeAPIKeyOfAnotherUser(t *testing.T) {
+	mockRepo := new(mocks.APIKeyRepository)
+	service := services.NewAPIKeyService(mockRepo, new(mocks.UserRepository), new(mocks.AuditLogger))
+	mockRepo.On("GetAPIKeyByID", mock.Anything, 3).Return(&model.APIKey{ID: 3, UserID: 8}, nil)
+
+	err := service.RevokeAPIKey(context.Background(), 7, "testuser", 3, model.RequestMetadata{})
+	assert.ErrorIs(t, err, services.ErrAPIKeyNotFound)
+	mockRepo.AssertNotCalled(t, "RevokeAPIKey", mock.Anything, mock.Anything, mock.Anything)
+}
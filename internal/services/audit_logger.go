@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"exercise-login-back-go/internal/model"
+	"log/slog"
+	"time"
+)
+
+const (
+	defaultAuditEventsLimit = 100
+	maxAuditEventsLimit     = 1000
+)
+
+type AuditLogger interface {
+	LogEvent(ctx context.Context, event model.AuditEvent)
+	GetEvents(ctx context.Context, filter model.AuditEventFilter) ([]model.AuditEvent, error)
+}
+
+type auditLoggerImpl struct {
+	repo model.AuditRepository
+}
+
+func NewAuditLogger(repo model.AuditRepository) *auditLoggerImpl {
+	return &auditLoggerImpl{repo: repo}
+}
+
+// LogEvent persists an audit event. Failing to store the event must never
+// interrupt the operation being audited, so errors are only logged. The event
+// is stored even when the request was cancelled after the operation ran.
+func (l *auditLoggerImpl) LogEvent(ctx context.Context, event model.AuditEvent) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	if err := l.repo.CreateAuditEvent(context.WithoutCancel(ctx), event); err != nil {
+		slog.ErrorContext(ctx, "Failed to store audit event", "eventType", event.EventType, "error", err)
+	}
+}
+
+// GetEvents returns the audit events matching the filter, newest first.
+func (l *auditLoggerImpl) GetEvents(ctx context.Context, filter model.AuditEventFilter) ([]model.AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditEventsLimit
+	}
+	if filter.Limit > maxAuditEventsLimit {
+		filter.Limit = maxAuditEventsLimit
+	}
+	return l.repo.GetAuditEvents(ctx, filter)
+}
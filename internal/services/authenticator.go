@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"exercise-login-back-go/internal/metrics"
+	"exercise-login-back-go/internal/model"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials is returned when the user does not exist or the
+// password is wrong, without telling which.
+var ErrInvalidCredentials = newError(KindUnauthorized, "invalid_credentials", nil)
+
+// Authenticator verifies the credentials of a login. On failure it still
+// returns the user when it is known, so the attempt can be attributed.
+type Authenticator interface {
+	Authenticate(ctx context.Context, emailOrUsername, password string) (*model.User, error)
+}
+
+type passwordAuthenticator struct {
+	repo model.UserRepository
+}
+
+// NewPasswordAuthenticator creates an authenticator that checks the bcrypt
+// password stored in the local user record.
+func NewPasswordAuthenticator(repo model.UserRepository) *passwordAuthenticator {
+	return &passwordAuthenticator{repo: repo}
+}
+
+// Authenticate compares the password with the hash of the local user.
+func (a *passwordAuthenticator) Authenticate(ctx context.Context, emailOrUsername, password string) (*model.User, error) {
+	// Retrieve the user from the database based on the provided email or username.
+	user, err := a.repo.GetUserByEmailOrUsername(ctx, emailOrUsername)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials.Wrap(errors.New("user not found"))
+	}
+
+	// Compare the provided password with the hashed password in the database.
+	if err := checkPassword(ctx, user.Password, password); err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+type domainAuthenticator struct {
+	repo     model.UserRepository
+	fallback Authenticator
+	byDomain map[string]Authenticator
+}
+
+// NewDomainAuthenticator creates an authenticator that delegates each login
+// to the authenticator configured for the domain of the user's email, and to
+// fallback for the remaining domains.
+func NewDomainAuthenticator(repo model.UserRepository, fallback Authenticator, byDomain map[string]Authenticator) *domainAuthenticator {
+	domains := make(map[string]Authenticator, len(byDomain))
+	for domain, authenticator := range byDomain {
+		domains[strings.ToLower(domain)] = authenticator
+	}
+	return &domainAuthenticator{repo: repo, fallback: fallback, byDomain: domains}
+}
+
+// Authenticate routes the login to the authenticator of its email domain.
+func (a *domainAuthenticator) Authenticate(ctx context.Context, emailOrUsername, password string) (*model.User, error) {
+	if len(a.byDomain) == 0 {
+		return a.fallback.Authenticate(ctx, emailOrUsername, password)
+	}
+
+	login := emailOrUsername
+	if !strings.Contains(login, "@") {
+		// Usernames are resolved to the email of the local user to find its domain.
+		user, err := a.repo.GetUserByEmailOrUsername(ctx, emailOrUsername)
+		if err != nil {
+			return nil, err
+		}
+		if user != nil {
+			login = user.Email
+		}
+	}
+
+	if authenticator, ok := a.byDomain[emailDomain(login)]; ok {
+		return authenticator.Authenticate(ctx, login, password)
+	}
+	return a.fallback.Authenticate(ctx, emailOrUsername, password)
+}
+
+// emailDomain returns the lower-cased domain of an email, or "" for a username
+func emailDomain(email string) string {
+	_, domain, found := strings.Cut(email, "@")
+	if !found {
+		return ""
+	}
+	return strings.ToLower(domain)
+}
+
+// Verify the provided password
+func checkPassword(ctx context.Context, hashedPassword, providedPassword string) error {
+	_, span := tracer.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
+	start := time.Now()
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(providedPassword))
+	metrics.PasswordHashDuration.WithLabelValues(metrics.OperationCompare).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return ErrInvalidCredentials.Wrap(errors.New("contraseña incorrecta"))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/repositories"
+	"exercise-login-back-go/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDomainAuthenticator(t *testing.T) {
+	t.Run("Routes Email Domain To Directory", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		fallback := new(mocks.Authenticator)
+		corp := new(mocks.Authenticator)
+		authenticator := services.NewDomainAuthenticator(mockRepo, fallback, map[string]services.Authenticator{"Corp.example.com": corp})
+
+		corp.On("Authenticate", mock.Anything, "jane@CORP.example.com", "secret").Return(&model.User{ID: 3}, nil)
+
+		user, err := authenticator.Authenticate(context.Background(), "jane@CORP.example.com", "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, user.ID)
+		fallback.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Resolves Username To Email", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		fallback := new(mocks.Authenticator)
+		corp := new(mocks.Authenticator)
+		authenticator := services.NewDomainAuthenticator(mockRepo, fallback, map[string]services.Authenticator{"corp.example.com": corp})
+
+		mockRepo.On("GetUserByEmailOrUsername", mock.Anything, "jane").Return(&model.User{ID: 3, Email: "jane@corp.example.com"}, nil)
+		corp.On("Authenticate", mock.Anything, "jane@corp.example.com", "secret").Return(&model.User{ID: 3}, nil)
+
+		_, err := authenticator.Authenticate(context.Background(), "jane", "secret")
+		assert.NoError(t, err)
+		corp.AssertExpectations(t)
+	})
+
+	t.Run("Other Domains Use Fallback", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		fallback := new(mocks.Authenticator)
+		corp := new(mocks.Authenticator)
+		authenticator := services.NewDomainAuthenticator(mockRepo, fallback, map[string]services.Authenticator{"corp.example.com": corp})
+
+		fallback.On("Authenticate", mock.Anything, "bob@example.com", "Password@123").Return(&model.User{ID: 4}, nil)
+
+		user, err := authenticator.Authenticate(context.Background(), "bob@example.com", "Password@123")
+		assert.NoError(t, err)
+		assert.Equal(t, 4, user.ID)
+		corp.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDirectoryAuthenticator(t *testing.T) {
+	entry := &model.DirectoryEntry{DN: "uid=jane,ou=people,dc=corp", Username: "jane", Email: "jane@corp.example.com", Phone: "(55) 1234-5678"}
+
+	t.Run("Existing User", func(t *testing.T) {
+		directory := new(mocks.Directory)
+		mockRepo := new(mocks.UserRepository)
+		authenticator := services.NewDirectoryAuthenticator(directory, mockRepo, new(mocks.AuditLogger))
+
+		directory.On("Authenticate", "jane@corp.example.com", "secret").Return(entry, nil)
+		mockRepo.On("GetUserByEmail", mock.Anything, "jane@corp.example.com").Return(&model.User{ID: 3, Email: "jane@corp.example.com"}, nil)
+
+		user, err := authenticator.Authenticate(context.Background(), "jane@corp.example.com", "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, user.ID)
+		mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Provisions New User", func(t *testing.T) {
+		directory := new(mocks.Directory)
+		mockRepo := new(mocks.UserRepository)
+		mockAudit := new(mocks.AuditLogger)
+		authenticator := services.NewDirectoryAuthenticator(directory, mockRepo, mockAudit)
+
+		directory.On("Authenticate", "jane@corp.example.com", "secret").Return(entry, nil)
+		mockRepo.On("GetUserByEmail", mock.Anything, "jane@corp.example.com").Return(nil, nil).Once()
+		mockRepo.On("GetUserByEmailOrUsername", mock.Anything, "jane").Return(&model.User{ID: 1, Username: "jane"}, nil)
+		mockRepo.On("GetUserByEmailOrUsername", mock.Anything, "jane2").Return(nil, nil)
+		mockRepo.On("GetUserByEmailOrPhone", mock.Anything, "", "5512345678").Return(nil, nil)
+		mockRepo.On("CreateUser", mock.Anything, model.User{Username: "jane2", Email: "jane@corp.example.com", Phone: "5512345678"}).Return(nil)
+		mockRepo.On("GetUserByEmail", mock.Anything, "jane@corp.example.com").Return(&model.User{ID: 9, Username: "jane2"}, nil)
+		mockAudit.On("LogEvent", mock.Anything, mock.MatchedBy(func(event model.AuditEvent) bool {
+			return event.EventType == model.AuditEventRegistration && event.Outcome == model.AuditOutcomeSuccess && event.Actor == "jane2"
+		})).Return()
+
+		user, err := authenticator.Authenticate(context.Background(), "jane@corp.example.com", "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, 9, user.ID)
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("Username Matching The Email Is Not Used", func(t *testing.T) {
+		directory := new(mocks.Directory)
+		repo := repositories.NewMemoryUserRepository()
+		mockAudit := new(mocks.AuditLogger)
+		mockAudit.On("LogEvent", mock.Anything, mock.AnythingOfType("model.AuditEvent")).Return()
+		authenticator := services.NewDirectoryAuthenticator(directory, repo, mockAudit)
+		assert.NoError(t, repo.CreateUser(context.Background(), model.User{Username: "jane@corp.example.com", Email: "mallory@example.com"}))
+
+		directory.On("Authenticate", "jane@corp.example.com", "secret").Return(entry, nil)
+
+		user, err := authenticator.Authenticate(context.Background(), "jane@corp.example.com", "secret")
+		assert.NoError(t, err)
+		assert.Equal(t, "jane@corp.example.com", user.Email)
+		assert.Equal(t, "jane", user.Username)
+	})
+
+	t.Run("Invalid Credentials", func(t *testing.T) {
+		directory := new(mocks.Directory)
+		mockRepo := new(mocks.UserRepository)
+		authenticator := services.NewDirectoryAuthenticator(directory, mockRepo, new(mocks.AuditLogger))
+
+		directory.On("Authenticate", "jane@corp.example.com", "wrong").Return(nil, nil)
+		mockRepo.On("GetUserByEmailOrUsername", mock.Anything, "jane@corp.example.com").Return(&model.User{ID: 3}, nil)
+
+		user, err := authenticator.Authenticate(context.Background(), "jane@corp.example.com", "wrong")
+		assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+		assert.Equal(t, 3, user.ID)
+	})
+
+	t.Run("Directory Unavailable", func(t *testing.T) {
+		directory := new(mocks.Directory)
+		authenticator := services.NewDirectoryAuthenticator(directory, new(mocks.UserRepository), new(mocks.AuditLogger))
+
+		directory.On("Authenticate", "jane@corp.example.com", "secret").Return(nil, errors.New("connection refused"))
+
+		_, err := authenticator.Authenticate(context.Background(), "jane@corp.example.com", "secret")
+		assert.ErrorIs(t, err, services.ErrDirectoryUnavailable)
+	})
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/validation"
+	"fmt"
+	"log/slog"
+	"regexp"
+)
+
+// ErrDirectoryUnavailable is returned when the directory cannot be reached.
+var ErrDirectoryUnavailable = newError(KindUpstream, "directory_unavailable", nil)
+
+// phoneDisallowed matches the characters removed from directory phone numbers
+var phoneDisallowed = regexp.MustCompile(`\D`)
+
+type directoryAuthenticator struct {
+	directory   model.Directory
+	repo        model.UserRepository
+	auditLogger AuditLogger
+}
+
+// NewDirectoryAuthenticator creates an authenticator that verifies passwords
+// against a corporate directory. Users that authenticate for the first time
+// get a local record provisioned from their directory attributes.
+func NewDirectoryAuthenticator(directory model.Directory, repo model.UserRepository, auditLogger AuditLogger) *directoryAuthenticator {
+	return &directoryAuthenticator{directory: directory, repo: repo, auditLogger: auditLogger}
+}
+
+// Authenticate binds to the directory and returns the matching local user.
+func (a *directoryAuthenticator) Authenticate(ctx context.Context, emailOrUsername, password string) (*model.User, error) {
+	entry, err := a.directory.Authenticate(emailOrUsername, password)
+	if err != nil {
+		slog.WarnContext(ctx, "Directory authentication failed", "emailOrUsername", emailOrUsername, "error", err)
+		return nil, ErrDirectoryUnavailable
+	}
+	if entry == nil {
+		user, _ := a.repo.GetUserByEmailOrUsername(ctx, emailOrUsername)
+		return user, ErrInvalidCredentials.Wrap(errors.New("contraseña incorrecta"))
+	}
+	if entry.Email == "" {
+		return nil, errors.New("el directorio no tiene un correo electrónico para el usuario")
+	}
+
+	user, err := a.repo.GetUserByEmail(ctx, entry.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		return user, nil
+	}
+	return a.provisionUser(ctx, *entry)
+}
+
+// provisionUser creates the local record of a directory user. Directory users
+// have no local password, so they can only sign in through the directory.
+func (a *directoryAuthenticator) provisionUser(ctx context.Context, entry model.DirectoryEntry) (*model.User, error) {
+	username, err := availableUsername(ctx, a.repo, entry.Username, entry.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	phone, err := availablePhone(ctx, a.repo, entry.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	user := model.User{Username: username, Email: entry.Email, Phone: phone}
+	if err := a.repo.CreateUser(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "Failed to create the user", "error", err)
+		a.logRegistration(ctx, username, err)
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+	a.logRegistration(ctx, username, nil)
+
+	created, err := a.repo.GetUserByEmail(ctx, entry.Email)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, errCreatedUserMissing
+	}
+	return created, nil
+}
+
+// availablePhone keeps an upstream phone number when it is valid and not
+// already registered to another user.
+func availablePhone(ctx context.Context, repo model.UserRepository, phone string) (string, error) {
+	phone = phoneDisallowed.ReplaceAllString(phone, "")
+	if !validation.IsPhone(phone) {
+		return "", nil
+	}
+	existing, err := repo.GetUserByEmailOrPhone(ctx, "", phone)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return "", nil
+	}
+	return phone, nil
+}
+
+// logRegistration records the provisioning of a directory user
+func (a *directoryAuthenticator) logRegistration(ctx context.Context, username string, err error) {
+	event := model.AuditEvent{
+		EventType: model.AuditEventRegistration,
+		Actor:     username,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    "provisioned from directory",
+	}
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Detail = err.Error()
+	}
+	a.auditLogger.LogEvent(ctx, event)
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"exercise-login-back-go/internal/i18n"
+	"strings"
+)
+
+// ErrorKind classifies the errors of the services by what the caller did
+// wrong, so the API can answer each one with the right status code.
+type ErrorKind string
+
+const (
+	KindValidation   ErrorKind = "validation"
+	KindUnauthorized ErrorKind = "unauthorized"
+	KindForbidden    ErrorKind = "forbidden"
+	KindNotFound     ErrorKind = "not_found"
+	KindConflict     ErrorKind = "conflict"
+	KindLocked       ErrorKind = "locked"
+	KindUpstream     ErrorKind = "upstream"
+	KindUnavailable  ErrorKind = "unavailable"
+	KindInternal     ErrorKind = "internal"
+)
+
+// Error is an error of the services. Code is a stable, machine-readable
+// identifier; Key and Params identify the message shown to users in the
+// i18n catalog, and Message is that message in the default locale. Err is
+// the underlying cause, which is never shown to users. Validation errors
+// set Field to the request field that was rejected.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Key     string
+	Params  i18n.Params
+	Message string
+	Field   string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error has the code of target, or its kind when
+// target has no code, so errors.Is(err, ErrNotFound) matches every
+// not-found error.
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	if t.Code == "" {
+		return e.Kind == t.Kind
+	}
+	return e.Code == t.Code
+}
+
+// Wrap returns a copy of the error caused by err.
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// The kinds of error, to be matched with errors.Is.
+var (
+	ErrValidation   = &Error{Kind: KindValidation}
+	ErrUnauthorized = &Error{Kind: KindUnauthorized}
+	ErrForbidden    = &Error{Kind: KindForbidden}
+	ErrNotFound     = &Error{Kind: KindNotFound}
+	ErrConflict     = &Error{Kind: KindConflict}
+	ErrLocked       = &Error{Kind: KindLocked}
+	ErrUpstream     = &Error{Kind: KindUpstream}
+	ErrUnavailable  = &Error{Kind: KindUnavailable}
+	ErrInternal     = &Error{Kind: KindInternal}
+)
+
+// ErrTimeout is reported when a request runs out of time waiting for the
+// database or an upstream provider.
+var ErrTimeout = newError(KindUnavailable, "timeout", nil)
+
+// newError creates an error of the given kind with the message of key.
+// Its code is the key without the variant: "invalid_password.too_short"
+// has the code "invalid_password".
+func newError(kind ErrorKind, key string, params i18n.Params) *Error {
+	code, _, _ := strings.Cut(key, ".")
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Key:     key,
+		Params:  params,
+		Message: i18n.Message(i18n.DefaultLocale, key, params),
+	}
+}
+
+// invalidField creates a validation error for a field of the request
+func invalidField(field, key string, params i18n.Params) *Error {
+	err := newError(KindValidation, key, params)
+	err.Field = field
+	return err
+}
+
+// errorCode returns the code of a typed error, or "internal_error" for an
+// unexpected failure
+func errorCode(err error) string {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) && serviceErr.Code != "" {
+		return serviceErr.Code
+	}
+	return "internal_error"
+}
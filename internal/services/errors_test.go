@@ -0,0 +1,28 @@
+package services_test
+
+import (
+	"errors"
+	"exercise-login-back-go/internal/services"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorMatching(t *testing.T) {
+	cause := errors.New("contraseña incorrecta")
+	err := fmt.Errorf("login: %w", services.ErrInvalidCredentials.Wrap(cause))
+
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+	assert.ErrorIs(t, err, services.ErrUnauthorized)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, services.ErrNotFound)
+	assert.NotErrorIs(t, err, services.ErrInvalidAPIKey)
+
+	var serviceErr *services.Error
+	assert.True(t, errors.As(err, &serviceErr))
+	assert.Equal(t, "invalid_credentials", serviceErr.Code)
+	assert.Equal(t, "usuario / contraseña incorrectos", serviceErr.Message)
+	assert.Equal(t, "login: usuario / contraseña incorrectos: contraseña incorrecta", err.Error())
+	assert.Nil(t, services.ErrInvalidCredentials.Err)
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"exercise-login-back-go/internal/model"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type HealthService interface {
+	Readiness(ctx context.Context) model.HealthReport
+	Drain()
+}
+
+// Pinger is a dependency whose availability can be checked, such as a *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type healthServiceImpl struct {
+	dependencies map[string]Pinger
+	timeout      time.Duration
+	draining     atomic.Bool
+}
+
+// NewHealthService creates a health service that checks the dependencies,
+// by name, giving each one timeout to answer.
+func NewHealthService(dependencies map[string]Pinger, timeout time.Duration) *healthServiceImpl {
+	return &healthServiceImpl{dependencies: dependencies, timeout: timeout}
+}
+
+// Readiness checks every dependency concurrently. The server is ready when
+// all of them answer in time and it is not draining.
+func (s *healthServiceImpl) Readiness(ctx context.Context) model.HealthReport {
+	if s.draining.Load() {
+		return model.HealthReport{Status: model.HealthStatusDraining}
+	}
+
+	report := model.HealthReport{Status: model.HealthStatusOK, Checks: make(map[string]model.HealthCheck, len(s.dependencies))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, dependency := range s.dependencies {
+		wg.Add(1)
+		go func(name string, dependency Pinger) {
+			defer wg.Done()
+			check := s.check(ctx, name, dependency)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = check
+			if check.Status != model.HealthStatusOK {
+				report.Status = model.HealthStatusUnavailable
+			}
+		}(name, dependency)
+	}
+	wg.Wait()
+	return report
+}
+
+// check pings a dependency. The cause of a failure is only logged, since the
+// report is public.
+func (s *healthServiceImpl) check(ctx context.Context, name string, dependency Pinger) model.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := dependency.PingContext(ctx)
+	check := model.HealthCheck{Status: model.HealthStatusOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		slog.WarnContext(ctx, "Readiness check failed", "dependency", name, "error", err)
+		check.Status = model.HealthStatusUnavailable
+	}
+	return check
+}
+
+// Drain marks the server as shutting down, so it reports not ready and the
+// load balancer stops sending it traffic.
+func (s *healthServiceImpl) Drain() {
+	s.draining.Store(true)
+}
@@ -0,0 +1,64 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pingerFunc adapts a function to services.Pinger
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) PingContext(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestReadiness(t *testing.T) {
+	healthy := pingerFunc(func(ctx context.Context) error { return nil })
+	down := pingerFunc(func(ctx context.Context) error { return errors.New("dial tcp: connection refused") })
+	hanging := pingerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		service := services.NewHealthService(map[string]services.Pinger{"database": healthy}, time.Second)
+
+		report := service.Readiness(context.Background())
+		assert.True(t, report.Ready())
+		assert.Equal(t, model.HealthStatusOK, report.Checks["database"].Status)
+	})
+
+	t.Run("dependency down", func(t *testing.T) {
+		service := services.NewHealthService(map[string]services.Pinger{"database": down, "cache": healthy}, time.Second)
+
+		report := service.Readiness(context.Background())
+		assert.False(t, report.Ready())
+		assert.Equal(t, model.HealthStatusUnavailable, report.Status)
+		assert.Equal(t, model.HealthStatusUnavailable, report.Checks["database"].Status)
+		assert.Equal(t, model.HealthStatusOK, report.Checks["cache"].Status)
+	})
+
+	t.Run("dependency times out", func(t *testing.T) {
+		service := services.NewHealthService(map[string]services.Pinger{"database": hanging}, 20*time.Millisecond)
+
+		start := time.Now()
+		report := service.Readiness(context.Background())
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, model.HealthStatusUnavailable, report.Checks["database"].Status)
+	})
+
+	t.Run("draining", func(t *testing.T) {
+		service := services.NewHealthService(map[string]services.Pinger{"database": healthy}, time.Second)
+		service.Drain()
+
+		report := service.Readiness(context.Background())
+		assert.False(t, report.Ready())
+		assert.Equal(t, model.HealthReport{Status: model.HealthStatusDraining}, report)
+	})
+}
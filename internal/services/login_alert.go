@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	// revokeLinkAudience identifies the tokens embedded in "this wasn't me" links.
+	revokeLinkAudience = "revoke-sessions"
+	// revokeLinkLifetime is how long a "this wasn't me" link can be used.
+	revokeLinkLifetime = 7 * 24 * time.Hour
+)
+
+// ErrInvalidAlertLink is returned for "this wasn't me" links that are
+// malformed, expired or already used.
+var ErrInvalidAlertLink = newError(KindValidation, "invalid_link", nil)
+
+type LoginAlertService interface {
+	CheckLogin(ctx context.Context, user model.User, meta model.RequestMetadata)
+	RevokeSessionsFromAlert(ctx context.Context, token string, meta model.RequestMetadata) error
+}
+
+type loginAlertServiceImpl struct {
+	repo           model.SessionRepository
+	sessionService SessionService
+	notifier       model.Notifier
+	secretKey      string
+	baseURL        string
+}
+
+func NewLoginAlertService(repo model.SessionRepository, sessionService SessionService, notifier model.Notifier, secretKey, baseURL string) *loginAlertServiceImpl {
+	return &loginAlertServiceImpl{
+		repo:           repo,
+		sessionService: sessionService,
+		notifier:       notifier,
+		secretKey:      secretKey,
+		baseURL:        baseURL,
+	}
+}
+
+// CheckLogin notifies the user when a login comes from a device or network
+// that does not appear in their session history. It must be called before the
+// session of the new login is created. Errors are only logged so that a
+// failing notifier never blocks a login.
+func (s *loginAlertServiceImpl) CheckLogin(ctx context.Context, user model.User, meta model.RequestMetadata) {
+	sessions, err := s.sessionService.GetSessions(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load the session history", "userId", user.ID, "error", err)
+		return
+	}
+	// The first login of an account has nothing to compare against.
+	if len(sessions) == 0 {
+		return
+	}
+
+	fingerprint := loginFingerprint(meta.UserAgent, meta.IPAddress)
+	for _, session := range sessions {
+		if loginFingerprint(session.UserAgent, session.IPAddress) == fingerprint {
+			return
+		}
+	}
+
+	link, err := s.revokeLink(ctx, user)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create the revocation link", "userId", user.ID, "error", err)
+		return
+	}
+
+	// The alert is written in the language the user prefers, if any.
+	locale := i18n.DefaultLocale
+	if preferred, ok := i18n.ParseLocale(user.Locale); ok {
+		locale = preferred
+	}
+	notification := model.Notification{
+		To:      user.Email,
+		Subject: i18n.Message(locale, "login_alert.subject", nil),
+		Body: i18n.Message(locale, "login_alert.body", i18n.Params{
+			"username":  user.Username,
+			"device":    describeDevice(meta.UserAgent),
+			"ipAddress": meta.IPAddress,
+			"date":      time.Now().UTC().Format(time.RFC1123),
+			"link":      link,
+		}),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.notifier.Notify(notification); err != nil {
+		slog.ErrorContext(ctx, "Failed to notify the user about a new login", "userId", user.ID, "error", err)
+	}
+}
+
+// RevokeSessionsFromAlert revokes every session of the user identified by
+// the token of a "this wasn't me" link. The link is consumed first, so it
+// cannot be replayed to log the user out again.
+func (s *loginAlertServiceImpl) RevokeSessionsFromAlert(ctx context.Context, token string, meta model.RequestMetadata) error {
+	claims := &model.Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.secretKey), nil
+	})
+	if err != nil || !parsed.Valid || !claims.VerifyAudience(revokeLinkAudience, true) || claims.Id == "" {
+		return ErrInvalidAlertLink
+	}
+
+	link, err := s.repo.ConsumeRevokeLink(ctx, claims.Id, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if link == nil || link.UserID != claims.UserID {
+		return ErrInvalidAlertLink
+	}
+
+	return s.sessionService.RevokeAllSessions(ctx, claims.UserID, claims.Username, meta)
+}
+
+// revokeLink builds the "this wasn't me" link sent in login alerts and
+// records its jti, so the link can only be used once
+func (s *loginAlertServiceImpl) revokeLink(ctx context.Context, user model.User) (string, error) {
+	id, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	link := model.RevokeLink{
+		ID:        id,
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(revokeLinkLifetime),
+	}
+	if err := s.repo.CreateRevokeLink(ctx, link); err != nil {
+		return "", err
+	}
+
+	claims := &model.Claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		StandardClaims: jwt.StandardClaims{
+			Id:        link.ID,
+			Audience:  revokeLinkAudience,
+			ExpiresAt: link.ExpiresAt.Unix(),
+			Issuer:    "LOGIN-EXERCISE-TOKEN",
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.secretKey))
+	if err != nil {
+		return "", err
+	}
+	return s.baseURL + "/api/users/sessions/revoke?token=" + url.QueryEscape(token), nil
+}
+
+// loginFingerprint identifies the device and network of a login: the device
+// described by the user agent plus the /24 (IPv4) or /48 (IPv6) network.
+func loginFingerprint(userAgent, ipAddress string) string {
+	return describeDevice(userAgent) + "|" + ipPrefix(ipAddress)
+}
+
+// ipPrefix returns the network prefix of an IP address
+func ipPrefix(ipAddress string) string {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return ipAddress
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String() + "/48"
+}
@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"context"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const chromeOnWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36"
+
+func TestCheckLogin(t *testing.T) {
+	user := model.User{ID: 7, Username: "testuser", Email: "test@example.com"}
+	history := []model.Session{{ID: "s1", UserID: 7, IPAddress: "192.168.1.10", UserAgent: chromeOnWindows}}
+
+	t.Run("first login", func(t *testing.T) {
+		mockRepo := new(mocks.SessionRepository)
+		mockSessions := new(mocks.SessionService)
+		mockNotifier := new(mocks.Notifier)
+		service := services.NewLoginAlertService(mockRepo, mockSessions, mockNotifier, "dummySecret", "http://localhost")
+
+		mockSessions.On("GetSessions", mock.Anything, 7).Return([]model.Session{}, nil)
+
+		service.CheckLogin(context.Background(), user, model.RequestMetadata{IPAddress: "10.0.0.1", UserAgent: chromeOnWindows})
+		mockNotifier.AssertNotCalled(t, "Notify", mock.Anything)
+	})
+
+	t.Run("known device on the same network", func(t *testing.T) {
+		mockRepo := new(mocks.SessionRepository)
+		mockSessions := new(mocks.SessionService)
+		mockNotifier := new(mocks.Notifier)
+		service := services.NewLoginAlertService(mockRepo, mockSessions, mockNotifier, "dummySecret", "http://localhost")
+
+		mockSessions.On("GetSessions", mock.Anything, 7).Return(history, nil)
+
+		service.CheckLogin(context.Background(), user, model.RequestMetadata{IPAddress: "192.168.1.77", UserAgent: chromeOnWindows})
+		mockNotifier.AssertNotCalled(t, "Notify", mock.Anything)
+	})
+
+	t.Run("new network notifies and link revokes sessions", func(t *testing.T) {
+		mockRepo := new(mocks.SessionRepository)
+		mockSessions := new(mocks.SessionService)
+		mockNotifier := new(mocks.Notifier)
+		service := services.NewLoginAlertService(mockRepo, mockSessions, mockNotifier, "dummySecret", "http://localhost")
+
+		var sent model.Notification
+		var stored model.RevokeLink
+		mockSessions.On("GetSessions", mock.Anything, 7).Return(history, nil)
+		mockRepo.On("CreateRevokeLink", mock.Anything, mock.AnythingOfType("model.RevokeLink")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(model.RevokeLink) }).
+			Return(nil)
+		mockNotifier.On("Notify", mock.AnythingOfType("model.Notification")).
+			Run(func(args mock.Arguments) { sent = args.Get(0).(model.Notification) }).
+			Return(nil)
+
+		service.CheckLogin(context.Background(), user, model.RequestMetadata{IPAddress: "203.0.113.5", UserAgent: chromeOnWindows})
+		mockNotifier.AssertExpectations(t)
+		assert.NotEmpty(t, stored.ID)
+		assert.Equal(t, 7, stored.UserID)
+		assert.Equal(t, "test@example.com", sent.To)
+		assert.Contains(t, sent.Body, "203.0.113.5")
+
+		link := regexp.MustCompile(`http://localhost/api/users/sessions/revoke\?token=\S+`).FindString(sent.Body)
+		assert.NotEmpty(t, link)
+		parsed, err := url.Parse(link)
+		assert.NoError(t, err)
+
+		meta := model.RequestMetadata{IPAddress: "192.168.1.10"}
+		used := stored
+		mockRepo.On("ConsumeRevokeLink", mock.Anything, stored.ID, mock.AnythingOfType("time.Time")).Return(&used, nil).Once()
+		mockSessions.On("RevokeAllSessions", mock.Anything, 7, "testuser", meta).Return(nil).Once()
+		assert.NoError(t, service.RevokeSessionsFromAlert(context.Background(), parsed.Query().Get("token"), meta))
+		mockSessions.AssertExpectations(t)
+
+		// A used link cannot be replayed.
+		mockRepo.On("ConsumeRevokeLink", mock.Anything, stored.ID, mock.AnythingOfType("time.Time")).Return(nil, nil).Once()
+		assert.ErrorIs(t, service.RevokeSessionsFromAlert(context.Background(), parsed.Query().Get("token"), meta), services.ErrInvalidAlertLink)
+		mockSessions.AssertNumberOfCalls(t, "RevokeAllSessions", 1)
+	})
+
+	t.Run("written in the language of the user", func(t *testing.T) {
+		mockRepo := new(mocks.SessionRepository)
+		mockSessions := new(mocks.SessionService)
+		mockNotifier := new(mocks.Notifier)
+		service := services.NewLoginAlertService(mockRepo, mockSessions, mockNotifier, "dummySecret", "http://localhost")
+
+		var sent model.Notification
+		mockSessions.On("GetSessions", mock.Anything, 7).Return(history, nil)
+		mockNotifier.On("Notify", mock.AnythingOfType("model.Notification")).
+			Run(func(args mock.Arguments) { sent = args.Get(0).(model.Notification) }).
+			Return(nil)
+
+		mockRepo.On("CreateRevokeLink", mock.Anything, mock.AnythingOfType("model.RevokeLink")).Return(nil)
+
+		english := user
+		english.Locale = "en"
+		service.CheckLogin(context.Background(), english, model.RequestMetadata{IPAddress: "203.0.113.5", UserAgent: chromeOnWindows})
+		assert.Equal(t, "New sign in to your account", sent.Subject)
+		assert.Contains(t, sent.Body, "Hi testuser,")
+		assert.Contains(t, sent.Body, "IP address: 203.0.113.5")
+	})
+}
+
+func TestRevokeSessionsFromAlertInvalidToken(t *testing.T) {
+	mockSessions := new(mocks.SessionService)
+	service := services.NewLoginAlertService(new(mocks.SessionRepository), mockSessions, new(mocks.Notifier), "dummySecret", "http://localhost")
+
+	err := service.RevokeSessionsFromAlert(context.Background(), "not-a-token", model.RequestMetadata{})
+	assert.Error(t, err)
+	mockSessions.AssertNotCalled(t, "RevokeAllSessions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,671 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/model"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	authorizationCodeLifetime = 10 * time.Minute
+	oauthAccessTokenLifetime  = 1 * time.Hour
+	oauthRefreshTokenLifetime = 30 * 24 * time.Hour
+	// codeChallengeMethodS256 is the only PKCE method accepted (RFC 7636).
+	codeChallengeMethodS256 = "S256"
+)
+
+// OAuth 2.0 error codes (RFC 6749, sections 4.1.2.1 and 5.2).
+const (
+	OAuthErrInvalidRequest         = "invalid_request"
+	OAuthErrInvalidClient          = "invalid_client"
+	OAuthErrInvalidGrant           = "invalid_grant"
+	OAuthErrUnauthorizedClient     = "unauthorized_client"
+	OAuthErrUnsupportedGrantType   = "unsupported_grant_type"
+	OAuthErrUnsupportedRespType    = "unsupported_response_type"
+	OAuthErrInvalidScope           = "invalid_scope"
+	OAuthErrAccessDenied           = "access_denied"
+	OAuthErrServerError            = "server_error"
+	OAuthErrTemporarilyUnavailable = "temporarily_unavailable"
+)
+
+var ErrOAuthClientNotFound = newError(KindNotFound, "oauth_client_not_found", nil)
+
+// supportedGrantTypes lists the grant types a client can be registered with.
+var supportedGrantTypes = map[string]bool{
+	model.GrantTypeAuthorizationCode: true,
+	model.GrantTypeRefreshToken:      true,
+	model.GrantTypeClientCredentials: true,
+}
+
+// OAuthError is an error response defined by RFC 6749. When RedirectURI is
+// set the error can be safely reported to the client through a redirect.
+type OAuthError struct {
+	Code        string
+	Description string
+	RedirectURI string
+	State       string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// RedirectURL returns the client redirect URI carrying the error, or an empty
+// string when the error must not be redirected.
+func (e *OAuthError) RedirectURL() string {
+	if e.RedirectURI == "" {
+		return ""
+	}
+	params := url.Values{}
+	params.Set("error", e.Code)
+	params.Set("error_description", e.Description)
+	if e.State != "" {
+		params.Set("state", e.State)
+	}
+	return appendQuery(e.RedirectURI, params)
+}
+
+type OAuthService interface {
+	RegisterClient(ctx context.Context, req model.OAuthClientRegistrationRequest) (*model.OAuthClient, string, error)
+	GetClients(ctx context.Context) ([]model.OAuthClient, error)
+	DeleteClient(ctx context.Context, clientID string) error
+	ValidateAuthorizationRequest(ctx context.Context, req model.AuthorizationRequest) (*model.OAuthClient, []string, error)
+	Authorize(ctx context.Context, claims *model.Claims, req model.AuthorizationRequest) (string, error)
+	DenyAuthorization(ctx context.Context, req model.AuthorizationRequest) (string, error)
+	Token(ctx context.Context, req model.TokenRequest) (*model.TokenResponse, error)
+	Introspect(ctx context.Context, clientID, clientSecret, token string) (*model.IntrospectionResponse, error)
+	Revoke(ctx context.Context, clientID, clientSecret, token string, meta model.RequestMetadata) error
+}
+
+type oauthServiceImpl struct {
+	repo        model.OAuthRepository
+	userRepo    model.UserRepository
+	oidcService OIDCService
+	auditLogger AuditLogger
+	issuer      string
+}
+
+func NewOAuthService(repo model.OAuthRepository, userRepo model.UserRepository, oidcService OIDCService, auditLogger AuditLogger, issuer string) *oauthServiceImpl {
+	return &oauthServiceImpl{
+		repo:        repo,
+		userRepo:    userRepo,
+		oidcService: oidcService,
+		auditLogger: auditLogger,
+		issuer:      issuer,
+	}
+}
+
+// RegisterClient registers a new OAuth client. For confidential clients the
+// returned secret is the only copy of the client secret.
+func (s *oauthServiceImpl) RegisterClient(ctx context.Context, req model.OAuthClientRegistrationRequest) (*model.OAuthClient, string, error) {
+	if len(req.GrantTypes) == 0 {
+		req.GrantTypes = []string{model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken}
+	}
+	if err := validateClientRegistration(req); err != nil {
+		return nil, "", err
+	}
+
+	clientID, err := randomToken(16)
+	if err != nil {
+		return nil, "", err
+	}
+	client := model.OAuthClient{
+		ClientID:               clientID,
+		Name:                   strings.TrimSpace(req.Name),
+		RedirectURIs:           req.RedirectURIs,
+		PostLogoutRedirectURIs: req.PostLogoutRedirectURIs,
+		GrantTypes:             req.GrantTypes,
+		Scopes:                 req.Scopes,
+		Public:                 req.Public,
+		CreatedAt:              time.Now().UTC(),
+	}
+	if client.RedirectURIs == nil {
+		client.RedirectURIs = []string{}
+	}
+	if client.PostLogoutRedirectURIs == nil {
+		client.PostLogoutRedirectURIs = []string{}
+	}
+	if client.Scopes == nil {
+		client.Scopes = []string{}
+	}
+
+	var secret string
+	if !client.Public {
+		secret, err = randomToken(32)
+		if err != nil {
+			return nil, "", err
+		}
+		client.SecretHash = hashSecret(secret)
+	}
+
+	client.ID, err = s.repo.CreateOAuthClient(ctx, client)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create the OAuth client", "error", err)
+		return nil, "", errors.New("error al registrar el cliente")
+	}
+	return &client, secret, nil
+}
+
+// validateClientRegistration checks that a client registration request is consistent
+func validateClientRegistration(req model.OAuthClientRegistrationRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return newError(KindValidation, "invalid_client_metadata.name_required", nil)
+	}
+	for _, grantType := range req.GrantTypes {
+		if !supportedGrantTypes[grantType] {
+			return newError(KindValidation, "invalid_client_metadata.grant_type", i18n.Params{"grantType": grantType})
+		}
+	}
+	if req.Public && containsString(req.GrantTypes, model.GrantTypeClientCredentials) {
+		return newError(KindValidation, "invalid_client_metadata.public_client_credentials", nil)
+	}
+	if containsString(req.GrantTypes, model.GrantTypeAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		return newError(KindValidation, "invalid_client_metadata.redirect_uri_required", nil)
+	}
+	for _, redirectURI := range append(req.RedirectURIs, req.PostLogoutRedirectURIs...) {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+			return newError(KindValidation, "invalid_client_metadata.redirect_uri", i18n.Params{"redirectUri": redirectURI})
+		}
+	}
+	for _, scope := range req.Scopes {
+		if scope == "" || strings.ContainsAny(scope, " \"\\") {
+			return newError(KindValidation, "invalid_client_metadata.scope", i18n.Params{"scope": scope})
+		}
+	}
+	return nil
+}
+
+// GetClients returns every registered OAuth client.
+func (s *oauthServiceImpl) GetClients(ctx context.Context) ([]model.OAuthClient, error) {
+	return s.repo.GetOAuthClients(ctx)
+}
+
+// DeleteClient removes an OAuth client and invalidates its tokens.
+func (s *oauthServiceImpl) DeleteClient(ctx context.Context, clientID string) error {
+	client, err := s.repo.GetOAuthClientByClientID(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return ErrOAuthClientNotFound
+	}
+	return s.repo.DeleteOAuthClient(ctx, clientID)
+}
+
+// ValidateAuthorizationRequest checks an authorization request and returns
+// the client and the scopes that would be granted.
+func (s *oauthServiceImpl) ValidateAuthorizationRequest(ctx context.Context, req model.AuthorizationRequest) (*model.OAuthClient, []string, error) {
+	// Errors about the client or the redirect URI must not be redirected.
+	if req.ClientID == "" {
+		return nil, nil, &OAuthError{Code: OAuthErrInvalidRequest, Description: "client_id is required"}
+	}
+	client, err := s.repo.GetOAuthClientByClientID(ctx, req.ClientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if client == nil {
+		return nil, nil, &OAuthError{Code: OAuthErrInvalidRequest, Description: "unknown client_id"}
+	}
+	if req.RedirectURI == "" && len(client.RedirectURIs) == 1 {
+		req.RedirectURI = client.RedirectURIs[0]
+	}
+	if !client.AllowsRedirectURI(req.RedirectURI) {
+		return nil, nil, &OAuthError{Code: OAuthErrInvalidRequest, Description: "redirect_uri is not registered for the client"}
+	}
+
+	redirectable := func(code, description string) error {
+		return &OAuthError{Code: code, Description: description, RedirectURI: req.RedirectURI, State: req.State}
+	}
+	if req.ResponseType != "code" {
+		return nil, nil, redirectable(OAuthErrUnsupportedRespType, "response_type must be code")
+	}
+	if !client.AllowsGrant(model.GrantTypeAuthorizationCode) {
+		return nil, nil, redirectable(OAuthErrUnauthorizedClient, "the client may not use the authorization code grant")
+	}
+	if req.CodeChallenge == "" {
+		return nil, nil, redirectable(OAuthErrInvalidRequest, "code_challenge is required")
+	}
+	if req.CodeChallengeMethod != codeChallengeMethodS256 {
+		return nil, nil, redirectable(OAuthErrInvalidRequest, "code_challenge_method must be S256")
+	}
+	scopes, ok := grantedScopes(req.Scope, client.Scopes)
+	if !ok {
+		return nil, nil, redirectable(OAuthErrInvalidScope, "the requested scope is not allowed for the client")
+	}
+
+	return client, scopes, nil
+}
+
+// Authorize issues an authorization code after the user approved the request
+// and returns the client redirect URI carrying it. The claims of the user's
+// session are kept for the ID token of OpenID Connect requests.
+func (s *oauthServiceImpl) Authorize(ctx context.Context, claims *model.Claims, req model.AuthorizationRequest) (string, error) {
+	client, scopes, err := s.ValidateAuthorizationRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	explicitRedirectURI := req.RedirectURI != ""
+	if !explicitRedirectURI {
+		req.RedirectURI = client.RedirectURIs[0]
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	var authTime time.Time
+	if claims.IssuedAt != 0 {
+		authTime = time.Unix(claims.IssuedAt, 0).UTC()
+	}
+	err = s.repo.CreateAuthorizationCode(ctx, model.OAuthAuthorizationCode{
+		CodeHash:            hashSecret(code),
+		ClientID:            client.ClientID,
+		UserID:              claims.UserID,
+		RedirectURI:         req.RedirectURI,
+		RedirectURIExplicit: explicitRedirectURI,
+		Scopes:              scopes,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
+		SessionID:           claims.Id,
+		AuthTime:            authTime,
+		ExpiresAt:           now.Add(authorizationCodeLifetime),
+		CreatedAt:           now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return appendQuery(req.RedirectURI, params), nil
+}
+
+// DenyAuthorization returns the client redirect URI reporting that the user
+// denied the request.
+func (s *oauthServiceImpl) DenyAuthorization(ctx context.Context, req model.AuthorizationRequest) (string, error) {
+	client, _, err := s.ValidateAuthorizationRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if req.RedirectURI == "" {
+		req.RedirectURI = client.RedirectURIs[0]
+	}
+	denied := &OAuthError{
+		Code:        OAuthErrAccessDenied,
+		Description: "the user denied the request",
+		RedirectURI: req.RedirectURI,
+		State:       req.State,
+	}
+	return denied.RedirectURL(), nil
+}
+
+// Token implements the token endpoint for the authorization_code,
+// refresh_token and client_credentials grants.
+func (s *oauthServiceImpl) Token(ctx context.Context, req model.TokenRequest) (*model.TokenResponse, error) {
+	if req.GrantType == "" {
+		return nil, &OAuthError{Code: OAuthErrInvalidRequest, Description: "grant_type is required"}
+	}
+	if !supportedGrantTypes[req.GrantType] {
+		return nil, &OAuthError{Code: OAuthErrUnsupportedGrantType, Description: "grant_type " + req.GrantType + " is not supported"}
+	}
+
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrant(req.GrantType) {
+		return nil, &OAuthError{Code: OAuthErrUnauthorizedClient, Description: "the client may not use this grant type"}
+	}
+
+	switch req.GrantType {
+	case model.GrantTypeAuthorizationCode:
+		return s.exchangeAuthorizationCode(ctx, client, req)
+	case model.GrantTypeRefreshToken:
+		return s.refreshAccessToken(ctx, client, req)
+	default:
+		return s.clientCredentials(ctx, client, req)
+	}
+}
+
+// exchangeAuthorizationCode implements the authorization_code grant with PKCE
+func (s *oauthServiceImpl) exchangeAuthorizationCode(ctx context.Context, client *model.OAuthClient, req model.TokenRequest) (*model.TokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, &OAuthError{Code: OAuthErrInvalidRequest, Description: "code and code_verifier are required"}
+	}
+	if len(req.CodeVerifier) < 43 || len(req.CodeVerifier) > 128 {
+		return nil, &OAuthError{Code: OAuthErrInvalidRequest, Description: "code_verifier must be between 43 and 128 characters"}
+	}
+
+	now := time.Now().UTC()
+	code, err := s.repo.ConsumeAuthorizationCode(ctx, hashSecret(req.Code), now)
+	if err != nil {
+		return nil, err
+	}
+	invalidGrant := &OAuthError{Code: OAuthErrInvalidGrant, Description: "the authorization code is invalid, expired or was already used"}
+	if code == nil || code.ClientID != client.ClientID || !now.Before(code.ExpiresAt) {
+		return nil, invalidGrant
+	}
+	// redirect_uri is required when the authorization request included it
+	// (RFC 6749, section 4.1.3), and must match whenever it is sent.
+	if (code.RedirectURIExplicit || req.RedirectURI != "") && req.RedirectURI != code.RedirectURI {
+		return nil, &OAuthError{Code: OAuthErrInvalidGrant, Description: "redirect_uri does not match the authorization request"}
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return nil, &OAuthError{Code: OAuthErrInvalidGrant, Description: "code_verifier does not match the code_challenge"}
+	}
+	user, err := s.userRepo.GetUserByID(ctx, code.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Disabled {
+		return nil, invalidGrant
+	}
+
+	grantID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.issueTokens(ctx, client, grantID, code.UserID, code.Scopes, client.AllowsGrant(model.GrantTypeRefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if containsString(code.Scopes, model.OIDCScopeOpenID) {
+		response.IDToken, err = s.oidcService.IssueIDToken(ctx, client.ClientID, *code, response.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// refreshAccessToken implements the refresh_token grant, rotating the refresh token
+func (s *oauthServiceImpl) refreshAccessToken(ctx context.Context, client *model.OAuthClient, req model.TokenRequest) (*model.TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, &OAuthError{Code: OAuthErrInvalidRequest, Description: "refresh_token is required"}
+	}
+
+	token, err := s.repo.GetOAuthTokenByHash(ctx, hashSecret(req.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	invalidGrant := &OAuthError{Code: OAuthErrInvalidGrant, Description: "the refresh token is invalid or expired"}
+	if token == nil || token.TokenType != model.OAuthTokenTypeRefresh || token.ClientID != client.ClientID {
+		return nil, invalidGrant
+	}
+	now := time.Now().UTC()
+	if token.RevokedAt != nil {
+		s.revokeReusedGrant(ctx, token.GrantID, now)
+		return nil, invalidGrant
+	}
+	if !token.IsActive(now) {
+		return nil, invalidGrant
+	}
+	user, err := s.userRepo.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Disabled {
+		return nil, invalidGrant
+	}
+
+	scopes, ok := grantedScopes(req.Scope, token.Scopes)
+	if !ok {
+		return nil, &OAuthError{Code: OAuthErrInvalidScope, Description: "the requested scope exceeds the original grant"}
+	}
+
+	revoked, err := s.repo.RevokeOAuthToken(ctx, token.TokenHash, now)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		// A concurrent request rotated the same token first.
+		s.revokeReusedGrant(ctx, token.GrantID, now)
+		return nil, invalidGrant
+	}
+	return s.issueTokens(ctx, client, token.GrantID, token.UserID, scopes, true)
+}
+
+// revokeReusedGrant revokes every token of a grant whose refresh token was
+// used twice. A rotated refresh token being replayed means it leaked.
+func (s *oauthServiceImpl) revokeReusedGrant(ctx context.Context, grantID string, now time.Time) {
+	if err := s.repo.RevokeOAuthGrant(ctx, grantID, now); err != nil {
+		slog.ErrorContext(ctx, "Failed to revoke OAuth grant after refresh token reuse", "grantId", grantID, "error", err)
+	}
+}
+
+// clientCredentials implements the client_credentials grant
+func (s *oauthServiceImpl) clientCredentials(ctx context.Context, client *model.OAuthClient, req model.TokenRequest) (*model.TokenResponse, error) {
+	if client.Public {
+		return nil, &OAuthError{Code: OAuthErrUnauthorizedClient, Description: "public clients may not use client_credentials"}
+	}
+	scopes, ok := grantedScopes(req.Scope, client.Scopes)
+	if !ok {
+		return nil, &OAuthError{Code: OAuthErrInvalidScope, Description: "the requested scope is not allowed for the client"}
+	}
+
+	grantID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, client, grantID, 0, scopes, false)
+}
+
+// issueTokens creates an access token, and optionally a refresh token, for a grant
+func (s *oauthServiceImpl) issueTokens(ctx context.Context, client *model.OAuthClient, grantID string, userID int, scopes []string, withRefreshToken bool) (*model.TokenResponse, error) {
+	now := time.Now().UTC()
+	accessToken, err := s.createToken(ctx, model.OAuthTokenTypeAccess, client, grantID, userID, scopes, now.Add(oauthAccessTokenLifetime))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(oauthAccessTokenLifetime.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}
+	if withRefreshToken {
+		response.RefreshToken, err = s.createToken(ctx, model.OAuthTokenTypeRefresh, client, grantID, userID, scopes, now.Add(oauthRefreshTokenLifetime))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// createToken stores a new opaque token and returns it
+func (s *oauthServiceImpl) createToken(ctx context.Context, tokenType string, client *model.OAuthClient, grantID string, userID int, scopes []string, expiresAt time.Time) (string, error) {
+	value, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	err = s.repo.CreateOAuthToken(ctx, model.OAuthToken{
+		TokenHash: hashSecret(value),
+		TokenType: tokenType,
+		GrantID:   grantID,
+		ClientID:  client.ClientID,
+		UserID:    userID,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// Introspect reports whether a token is active and describes it (RFC 7662).
+// Only confidential clients may introspect tokens.
+func (s *oauthServiceImpl) Introspect(ctx context.Context, clientID, clientSecret, tokenValue string) (*model.IntrospectionResponse, error) {
+	client, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if client.Public {
+		return nil, &OAuthError{Code: OAuthErrUnauthorizedClient, Description: "public clients may not introspect tokens"}
+	}
+
+	token, err := s.repo.GetOAuthTokenByHash(ctx, hashSecret(tokenValue))
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || !token.IsActive(time.Now().UTC()) {
+		return &model.IntrospectionResponse{Active: false}, nil
+	}
+
+	response := &model.IntrospectionResponse{
+		Active:    true,
+		Scope:     strings.Join(token.Scopes, " "),
+		ClientID:  token.ClientID,
+		TokenType: token.TokenType,
+		ExpiresAt: token.ExpiresAt.Unix(),
+		IssuedAt:  token.CreatedAt.Unix(),
+		Issuer:    s.issuer,
+	}
+	if token.UserID != 0 {
+		response.Subject = strconv.Itoa(token.UserID)
+		user, err := s.userRepo.GetUserByID(ctx, token.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil || user.Disabled {
+			return &model.IntrospectionResponse{Active: false}, nil
+		}
+		response.Username = user.Username
+	} else {
+		response.Subject = token.ClientID
+	}
+	return response, nil
+}
+
+// Revoke invalidates a token of the client (RFC 7009). Revoking a refresh
+// token also revokes the access tokens issued from the same grant. Unknown
+// tokens are ignored, as required by the RFC.
+func (s *oauthServiceImpl) Revoke(ctx context.Context, clientID, clientSecret, tokenValue string, meta model.RequestMetadata) error {
+	client, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return err
+	}
+
+	token, err := s.repo.GetOAuthTokenByHash(ctx, hashSecret(tokenValue))
+	if err != nil {
+		return err
+	}
+	if token == nil || token.ClientID != client.ClientID || token.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	if token.TokenType == model.OAuthTokenTypeRefresh {
+		err = s.repo.RevokeOAuthGrant(ctx, token.GrantID, now)
+	} else {
+		_, err = s.repo.RevokeOAuthToken(ctx, token.TokenHash, now)
+	}
+
+	event := model.AuditEvent{
+		EventType: model.AuditEventTokenRevocation,
+		UserID:    token.UserID,
+		Actor:     client.ClientID,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    "oauth " + token.TokenType,
+	}
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Detail = err.Error()
+	}
+	s.auditLogger.LogEvent(ctx, event)
+	return err
+}
+
+// authenticateClient verifies the client credentials. Public clients only
+// identify themselves with their client_id.
+func (s *oauthServiceImpl) authenticateClient(ctx context.Context, clientID, clientSecret string) (*model.OAuthClient, error) {
+	invalidClient := &OAuthError{Code: OAuthErrInvalidClient, Description: "client authentication failed"}
+	if clientID == "" {
+		return nil, invalidClient
+	}
+
+	client, err := s.repo.GetOAuthClientByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, invalidClient
+	}
+	if client.Public {
+		if clientSecret != "" {
+			return nil, invalidClient
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(hashSecret(clientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, invalidClient
+	}
+	return client, nil
+}
+
+// grantedScopes resolves the space separated scopes requested against the
+// allowed ones. An empty request grants every allowed scope.
+func grantedScopes(requested string, allowed []string) ([]string, bool) {
+	if strings.TrimSpace(requested) == "" {
+		return allowed, true
+	}
+	scopes := strings.Fields(requested)
+	for _, scope := range scopes {
+		if !containsString(allowed, scope) {
+			return nil, false
+		}
+	}
+	return scopes, true
+}
+
+// verifyCodeChallenge checks a PKCE code verifier against an S256 code challenge
+func verifyCodeChallenge(verifier, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// appendQuery adds query parameters to a URI that may already have some
+func appendQuery(uri string, params url.Values) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	query := parsed.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,345 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var publicClient = &model.OAuthClient{
+	ClientID:     "spa",
+	Name:         "Single page app",
+	RedirectURIs: []string{"https://app.example.com/callback"},
+	GrantTypes:   []string{model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken},
+	Scopes:       []string{"profile", "email"},
+	Public:       true,
+}
+
+// pkcePair returns a code verifier and its S256 code challenge
+func pkcePair() (string, string) {
+	verifier := strings.Repeat("v", 43)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// oauthErrorCode returns the OAuth error code of err, if any
+func oauthErrorCode(err error) string {
+	var oauthErr *services.OAuthError
+	if errors.As(err, &oauthErr) {
+		return oauthErr.Code
+	}
+	return ""
+}
+
+func TestRegisterClient(t *testing.T) {
+	mockRepo := new(mocks.OAuthRepository)
+	service := services.NewOAuthService(mockRepo, new(mocks.UserRepository), new(mocks.OIDCService), new(mocks.AuditLogger), "http://localhost")
+
+	t.Run("confidential client gets a secret", func(t *testing.T) {
+		mockRepo.On("CreateOAuthClient", mock.Anything, mock.AnythingOfType("model.OAuthClient")).Return(1, nil).Once()
+
+		client, secret, err := service.RegisterClient(context.Background(), model.OAuthClientRegistrationRequest{
+			Name:       "Reports",
+			GrantTypes: []string{model.GrantTypeClientCredentials},
+			Scopes:     []string{"reports"},
+		})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, client.ClientID)
+		assert.NotEmpty(t, secret)
+		assert.NotEqual(t, secret, client.SecretHash)
+	})
+
+	t.Run("public clients cannot use client credentials", func(t *testing.T) {
+		_, _, err := service.RegisterClient(context.Background(), model.OAuthClientRegistrationRequest{
+			Name:       "SPA",
+			GrantTypes: []string{model.GrantTypeClientCredentials},
+			Public:     true,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("authorization code requires redirect uris", func(t *testing.T) {
+		_, _, err := service.RegisterClient(context.Background(), model.OAuthClientRegistrationRequest{Name: "SPA", Public: true})
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateAuthorizationRequest(t *testing.T) {
+	mockRepo := new(mocks.OAuthRepository)
+	service := services.NewOAuthService(mockRepo, new(mocks.UserRepository), new(mocks.OIDCService), new(mocks.AuditLogger), "http://localhost")
+	mockRepo.On("GetOAuthClientByClientID", mock.Anything, "spa").Return(publicClient, nil)
+	_, challenge := pkcePair()
+
+	t.Run("unregistered redirect uri is not redirected", func(t *testing.T) {
+		_, _, err := service.ValidateAuthorizationRequest(context.Background(), model.AuthorizationRequest{
+			ResponseType: "code", ClientID: "spa", RedirectURI: "https://evil.example.com/callback",
+			CodeChallenge: challenge, CodeChallengeMethod: "S256",
+		})
+		var oauthErr *services.OAuthError
+		assert.True(t, errors.As(err, &oauthErr))
+		assert.Empty(t, oauthErr.RedirectURL())
+	})
+
+	t.Run("missing PKCE is redirected", func(t *testing.T) {
+		_, _, err := service.ValidateAuthorizationRequest(context.Background(), model.AuthorizationRequest{
+			ResponseType: "code", ClientID: "spa", State: "xyz",
+		})
+		var oauthErr *services.OAuthError
+		assert.True(t, errors.As(err, &oauthErr))
+		assert.Contains(t, oauthErr.RedirectURL(), "https://app.example.com/callback?")
+		assert.Contains(t, oauthErr.RedirectURL(), "state=xyz")
+	})
+
+	t.Run("scope outside the client scopes", func(t *testing.T) {
+		_, _, err := service.ValidateAuthorizationRequest(context.Background(), model.AuthorizationRequest{
+			ResponseType: "code", ClientID: "spa", Scope: "admin",
+			CodeChallenge: challenge, CodeChallengeMethod: "S256",
+		})
+		assert.Equal(t, services.OAuthErrInvalidScope, oauthErrorCode(err))
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		client, scopes, err := service.ValidateAuthorizationRequest(context.Background(), model.AuthorizationRequest{
+			ResponseType: "code", ClientID: "spa", Scope: "email",
+			CodeChallenge: challenge, CodeChallengeMethod: "S256",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "spa", client.ClientID)
+		assert.Equal(t, []string{"email"}, scopes)
+	})
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	mockRepo := new(mocks.OAuthRepository)
+	mockUsers := new(mocks.UserRepository)
+	service := services.NewOAuthService(mockRepo, mockUsers, new(mocks.OIDCService), new(mocks.AuditLogger), "http://localhost")
+	verifier, challenge := pkcePair()
+
+	var storedCode model.OAuthAuthorizationCode
+	mockRepo.On("GetOAuthClientByClientID", mock.Anything, "spa").Return(publicClient, nil)
+	mockRepo.On("CreateAuthorizationCode", mock.Anything, mock.AnythingOfType("model.OAuthAuthorizationCode")).
+		Run(func(args mock.Arguments) { storedCode = args.Get(1).(model.OAuthAuthorizationCode) }).
+		Return(nil)
+
+	redirectTo, err := service.Authorize(context.Background(), &model.Claims{UserID: 7}, model.AuthorizationRequest{
+		ResponseType: "code", ClientID: "spa", State: "xyz",
+		CodeChallenge: challenge, CodeChallengeMethod: "S256",
+	})
+	assert.NoError(t, err)
+	parsed, _ := url.Parse(redirectTo)
+	code := parsed.Query().Get("code")
+	assert.NotEmpty(t, code)
+	assert.Equal(t, "xyz", parsed.Query().Get("state"))
+	assert.Equal(t, 7, storedCode.UserID)
+	assert.NotEqual(t, code, storedCode.CodeHash)
+
+	t.Run("wrong code verifier", func(t *testing.T) {
+		mockRepo.On("ConsumeAuthorizationCode", mock.Anything, storedCode.CodeHash, mock.AnythingOfType("time.Time")).Return(&storedCode, nil).Once()
+
+		_, err := service.Token(context.Background(), model.TokenRequest{
+			GrantType: model.GrantTypeAuthorizationCode, ClientID: "spa",
+			Code: code, CodeVerifier: strings.Repeat("w", 43),
+		})
+		assert.Equal(t, services.OAuthErrInvalidGrant, oauthErrorCode(err))
+	})
+
+	t.Run("code already used", func(t *testing.T) {
+		mockRepo.On("ConsumeAuthorizationCode", mock.Anything, storedCode.CodeHash, mock.AnythingOfType("time.Time")).Return(nil, nil).Once()
+
+		_, err := service.Token(context.Background(), model.TokenRequest{
+			GrantType: model.GrantTypeAuthorizationCode, ClientID: "spa",
+			Code: code, CodeVerifier: verifier,
+		})
+		assert.Equal(t, services.OAuthErrInvalidGrant, oauthErrorCode(err))
+	})
+
+	t.Run("disabled user", func(t *testing.T) {
+		mockRepo.On("ConsumeAuthorizationCode", mock.Anything, storedCode.CodeHash, mock.AnythingOfType("time.Time")).Return(&storedCode, nil).Once()
+		mockUsers.On("GetUserByID", mock.Anything, 7).Return(&model.User{ID: 7, Username: "testuser", Disabled: true}, nil).Once()
+
+		_, err := service.Token(context.Background(), model.TokenRequest{
+			GrantType: model.GrantTypeAuthorizationCode, ClientID: "spa",
+			Code: code, CodeVerifier: verifier,
+		})
+		assert.Equal(t, services.OAuthErrInvalidGrant, oauthErrorCode(err))
+		mockRepo.AssertNotCalled(t, "CreateOAuthToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("exchange issues access and refresh tokens", func(t *testing.T) {
+		var tokens []model.OAuthToken
+		mockRepo.On("ConsumeAuthorizationCode", mock.Anything, storedCode.CodeHash, mock.AnythingOfType("time.Time")).Return(&storedCode, nil).Once()
+		mockUsers.On("GetUserByID", mock.Anything, 7).Return(&model.User{ID: 7, Username: "testuser"}, nil).Once()
+		mockRepo.On("CreateOAuthToken", mock.Anything, mock.AnythingOfType("model.OAuthToken")).
+			Run(func(args mock.Arguments) { tokens = append(tokens, args.Get(1).(model.OAuthToken)) }).
+			Return(nil).Twice()
+
+		response, err := service.Token(context.Background(), model.TokenRequest{
+			GrantType: model.GrantTypeAuthorizationCode, ClientID: "spa",
+			Code: code, CodeVerifier: verifier, RedirectURI: "https://app.example.com/callback",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer", response.TokenType)
+		assert.NotEmpty(t, response.AccessToken)
+		assert.NotEmpty(t, response.RefreshToken)
+		assert.Equal(t, "profile email", response.Scope)
+		assert.Len(t, tokens, 2)
+		assert.Equal(t, tokens[0].GrantID, tokens[1].GrantID)
+		assert.Equal(t, 7, tokens[0].UserID)
+	})
+}
+
+func TestAuthorizationCodeExplicitRedirectURI(t *testing.T) {
+	mockRepo := new(mocks.OAuthRepository)
+	service := services.NewOAuthService(mockRepo, new(mocks.UserRepository), new(mocks.OIDCService), new(mocks.AuditLogger), "http://localhost")
+	verifier, challenge := pkcePair()
+
+	var storedCode model.OAuthAuthorizationCode
+	mockRepo.On("GetOAuthClientByClientID", mock.Anything, "spa").Return(publicClient, nil)
+	mockRepo.On("CreateAuthorizationCode", mock.Anything, mock.AnythingOfType("model.OAuthAuthorizationCode")).
+		Run(func(args mock.Arguments) { storedCode = args.Get(1).(model.OAuthAuthorizationCode) }).
+		Return(nil)
+
+	redirectTo, err := service.Authorize(context.Background(), &model.Claims{UserID: 7}, model.AuthorizationRequest{
+		ResponseType: "code", ClientID: "spa", RedirectURI: "https://app.example.com/callback",
+		CodeChallenge: challenge, CodeChallengeMethod: "S256",
+	})
+	assert.NoError(t, err)
+	assert.True(t, storedCode.RedirectURIExplicit)
+	parsed, _ := url.Parse(redirectTo)
+	code := parsed.Query().Get("code")
+
+	t.Run("missing redirect_uri", func(t *testing.T) {
+		mockRepo.On("ConsumeAuthorizationCode", mock.Anything, storedCode.CodeHash, mock.AnythingOfType("time.Time")).Return(&storedCode, nil).Once()
+
+		_, err := service.Token(context.Background(), model.TokenRequest{
+			GrantType: model.GrantTypeAuthorizationCode, ClientID: "spa",
+			Code: code, CodeVerifier: verifier,
+		})
+		assert.Equal(t, services.OAuthErrInvalidGrant, oauthErrorCode(err))
+	})
+
+	t.Run("different redirect_uri", func(t *testing.T) {
+		mockRepo.On("ConsumeAuthorizationCode", mock.Anything, storedCode.CodeHash, mock.AnythingOfType("time.Time")).Return(&storedCode, nil).Once()
+
+		_, err := service.Token(context.Background(), model.TokenRequest{
+			GrantType: model.GrantTypeAuthorizationCode, ClientID: "spa",
+			Code: code, CodeVerifier: verifier, RedirectURI: "https://evil.example.com/callback",
+		})
+		assert.Equal(t, services.OAuthErrInvalidGrant, oauthErrorCode(err))
+	})
+}
+
+func TestRefreshTokenReuseRevokesGrant(t *testing.T) {
+	mockRepo := new(mocks.OAuthRepository)
+	service := services.NewOAuthService(mockRepo, new(mocks.UserRepository), new(mocks.OIDCService), new(mocks.AuditLogger), "http://localhost")
+	revokedAt := time.Now().Add(-time.Minute)
+
+	mockRepo.On("GetOAuthClientByClientID", mock.Anything, "spa").Return(publicClient, nil)
+	mockRepo.On("GetOAuthTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(&model.OAuthToken{
+		TokenHash: "hash", TokenType: model.OAuthTokenTypeRefresh, GrantID: "grant-1", ClientID: "spa",
+		UserID: 7, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt,
+	}, nil)
+	mockRepo.On("RevokeOAuthGrant", mock.Anything, "grant-1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	_, err := service.Token(context.Background(), model.TokenRequest{GrantType: model.GrantTypeRefreshToken, ClientID: "spa", RefreshToken: "old"})
+	assert.Equal(t, services.OAuthErrInvalidGrant, oauthErrorCode(err))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestConcurrentRefreshTokenReuseRevokesGrant(t *testing.T) {
+	mockRepo := new(mocks.OAuthRepository)
+	mockUsers := new(mocks.UserRepository)
+	service := services.NewOAuthService(mockRepo, mockUsers, new(mocks.OIDCService), new(mocks.AuditLogger), "http://localhost")
+
+	// Both requests read the token while it is still active
+	mockRepo.On("GetOAuthClientByClientID", mock.Anything, "spa").Return(publicClient, nil)
+	mockRepo.On("GetOAuthTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(&model.OAuthToken{
+		TokenHash: "hash", TokenType: model.OAuthTokenTypeRefresh, GrantID: "grant-1", ClientID: "spa",
+		UserID: 7, Scopes: []string{"profile"}, ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	mockUsers.On("GetUserByID", mock.Anything, 7).Return(&model.User{ID: 7, Username: "testuser"}, nil)
+	mockRepo.On("RevokeOAuthToken", mock.Anything, "hash", mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	mockRepo.On("RevokeOAuthToken", mock.Anything, "hash", mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+	mockRepo.On("CreateOAuthToken", mock.Anything, mock.AnythingOfType("model.OAuthToken")).Return(nil).Twice()
+	mockRepo.On("RevokeOAuthGrant", mock.Anything, "grant-1", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	request := model.TokenRequest{GrantType: model.GrantTypeRefreshToken, ClientID: "spa", RefreshToken: "current"}
+	response, err := service.Token(context.Background(), request)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.RefreshToken)
+
+	_, err = service.Token(context.Background(), request)
+	assert.Equal(t, services.OAuthErrInvalidGrant, oauthErrorCode(err))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefreshTokenOfDisabledUser(t *testing.T) {
+	mockRepo := new(mocks.OAuthRepository)
+	mockUsers := new(mocks.UserRepository)
+	service := services.NewOAuthService(mockRepo, mockUsers, new(mocks.OIDCService), new(mocks.AuditLogger), "http://localhost")
+
+	mockRepo.On("GetOAuthClientByClientID", mock.Anything, "spa").Return(publicClient, nil)
+	mockRepo.On("GetOAuthTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(&model.OAuthToken{
+		TokenHash: "hash", TokenType: model.OAuthTokenTypeRefresh, GrantID: "grant-1", ClientID: "spa",
+		UserID: 7, Scopes: []string{"profile"}, ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	mockUsers.On("GetUserByID", mock.Anything, 7).Return(&model.User{ID: 7, Username: "testuser", Disabled: true}, nil)
+
+	_, err := service.Token(context.Background(), model.TokenRequest{GrantType: model.GrantTypeRefreshToken, ClientID: "spa", RefreshToken: "current"})
+	assert.Equal(t, services.OAuthErrInvalidGrant, oauthErrorCode(err))
+	mockRepo.AssertNotCalled(t, "RevokeOAuthToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClientAuthentication(t *testing.T) {
+	mockRepo := new(mocks.OAuthRepository)
+	service := services.NewOAuthService(mockRepo, new(mocks.UserRepository), new(mocks.OIDCService), new(mocks.AuditLogger), "http://localhost")
+
+	mockRepo.On("CreateOAuthClient", mock.Anything, mock.AnythingOfType("model.OAuthClient")).Return(1, nil)
+	client, secret, err := service.RegisterClient(context.Background(), model.OAuthClientRegistrationRequest{
+		Name:       "Reports",
+		GrantTypes: []string{model.GrantTypeClientCredentials},
+		Scopes:     []string{"reports"},
+	})
+	assert.NoError(t, err)
+	mockRepo.On("GetOAuthClientByClientID", mock.Anything, client.ClientID).Return(client, nil)
+
+	t.Run("wrong secret", func(t *testing.T) {
+		_, err := service.Token(context.Background(), model.TokenRequest{GrantType: model.GrantTypeClientCredentials, ClientID: client.ClientID, ClientSecret: "wrong"})
+		assert.Equal(t, services.OAuthErrInvalidClient, oauthErrorCode(err))
+	})
+
+	t.Run("grant not allowed", func(t *testing.T) {
+		_, err := service.Token(context.Background(), model.TokenRequest{GrantType: model.GrantTypeRefreshToken, ClientID: client.ClientID, ClientSecret: secret, RefreshToken: "x"})
+		assert.Equal(t, services.OAuthErrUnauthorizedClient, oauthErrorCode(err))
+	})
+
+	t.Run("client credentials", func(t *testing.T) {
+		mockRepo.On("CreateOAuthToken", mock.Anything, mock.MatchedBy(func(token model.OAuthToken) bool {
+			return token.TokenType == model.OAuthTokenTypeAccess && token.UserID == 0
+		})).Return(nil).Once()
+
+		response, err := service.Token(context.Background(), model.TokenRequest{GrantType: model.GrantTypeClientCredentials, ClientID: client.ClientID, ClientSecret: secret})
+		assert.NoError(t, err)
+		assert.Empty(t, response.RefreshToken)
+		assert.Equal(t, "reports", response.Scope)
+	})
+
+	t.Run("introspect unknown token", func(t *testing.T) {
+		mockRepo.On("GetOAuthTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(nil, nil).Once()
+
+		response, err := service.Introspect(context.Background(), client.ClientID, secret, "unknown")
+		assert.NoError(t, err)
+		assert.False(t, response.Active)
+	})
+}
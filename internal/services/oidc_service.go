@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"exercise-login-back-go/internal/model"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	idTokenLifetime = 1 * time.Hour
+	// signingKeyBits is the size of the key generated when none is configured.
+	signingKeyBits = 2048
+)
+
+var (
+	ErrInvalidAccessToken = newError(KindUnauthorized, "invalid_access_token", nil)
+	ErrInsufficientScope  = newError(KindForbidden, "insufficient_scope", nil)
+)
+
+type OIDCService interface {
+	Discovery() model.OpenIDConfiguration
+	JWKS() model.JSONWebKeySet
+	IssueIDToken(ctx context.Context, clientID string, code model.OAuthAuthorizationCode, accessToken string) (string, error)
+	UserInfo(ctx context.Context, accessToken string) (*model.UserInfo, error)
+	Logout(ctx context.Context, req model.LogoutRequest, meta model.RequestMetadata) (string, error)
+}
+
+type oidcServiceImpl struct {
+	repo           model.OAuthRepository
+	userRepo       model.UserRepository
+	sessionService SessionService
+	signingKey     *rsa.PrivateKey
+	keyID          string
+	issuer         string
+}
+
+func NewOIDCService(repo model.OAuthRepository, userRepo model.UserRepository, sessionService SessionService, signingKey *rsa.PrivateKey, issuer string) *oidcServiceImpl {
+	return &oidcServiceImpl{
+		repo:           repo,
+		userRepo:       userRepo,
+		sessionService: sessionService,
+		signingKey:     signingKey,
+		keyID:          keyThumbprint(&signingKey.PublicKey),
+		issuer:         issuer,
+	}
+}
+
+// LoadSigningKey reads the RSA key used to sign ID tokens from a PEM file.
+// When no file is configured an ephemeral key is generated, which invalidates
+// every issued ID token on restart.
+func LoadSigningKey(path string) (*rsa.PrivateKey, error) {
+	if path == "" {
+		slog.Warn("No OIDC signing key configured, generating an ephemeral key")
+		return rsa.GenerateKey(rand.Reader, signingKeyBits)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the OIDC signing key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the OIDC signing key is not an RSA key")
+	}
+	return key, nil
+}
+
+// Discovery returns the provider metadata.
+func (s *oidcServiceImpl) Discovery() model.OpenIDConfiguration {
+	return model.OpenIDConfiguration{
+		Issuer:                            s.issuer,
+		AuthorizationEndpoint:             s.issuer + "/oauth/authorize",
+		TokenEndpoint:                     s.issuer + "/oauth/token",
+		UserInfoEndpoint:                  s.issuer + "/userinfo",
+		JWKSURI:                           s.issuer + "/.well-known/jwks.json",
+		EndSessionEndpoint:                s.issuer + "/oauth/logout",
+		IntrospectionEndpoint:             s.issuer + "/oauth/introspect",
+		RevocationEndpoint:                s.issuer + "/oauth/revoke",
+		ScopesSupported:                   []string{model.OIDCScopeOpenID, model.OIDCScopeProfile, model.OIDCScopeEmail, model.OIDCScopePhone},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken, model.GrantTypeClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{jwt.SigningMethodRS256.Alg()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{codeChallengeMethodS256},
+		ClaimsSupported: []string{"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce", "sid", "at_hash",
+			"preferred_username", "email", "email_verified", "phone_number", "phone_number_verified"},
+	}
+}
+
+// JWKS returns the public keys that verify the ID tokens.
+func (s *oidcServiceImpl) JWKS() model.JSONWebKeySet {
+	publicKey := s.signingKey.PublicKey
+	return model.JSONWebKeySet{Keys: []model.JSONWebKey{{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: jwt.SigningMethodRS256.Alg(),
+		KeyID:     s.keyID,
+		Modulus:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+	}}}
+}
+
+// IssueIDToken signs the ID token returned together with the access token
+// obtained by exchanging an authorization code.
+func (s *oidcServiceImpl) IssueIDToken(ctx context.Context, clientID string, code model.OAuthAuthorizationCode, accessToken string) (string, error) {
+	user, err := s.userRepo.GetUserByID(ctx, code.UserID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", errors.New("the user of the authorization code no longer exists")
+	}
+
+	info := userInfoFor(*user, code.Scopes)
+	now := time.Now().UTC()
+	claims := model.IDTokenClaims{
+		Nonce:               code.Nonce,
+		SessionID:           code.SessionID,
+		AccessTokenHash:     accessTokenHash(accessToken),
+		PreferredUsername:   info.PreferredUsername,
+		Email:               info.Email,
+		EmailVerified:       info.EmailVerified,
+		PhoneNumber:         info.PhoneNumber,
+		PhoneNumberVerified: info.PhoneNumberVerified,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    s.issuer,
+			Subject:   info.Subject,
+			Audience:  clientID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(idTokenLifetime).Unix(),
+		},
+	}
+	if !code.AuthTime.IsZero() {
+		claims.AuthTime = code.AuthTime.Unix()
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyID
+	return token.SignedString(s.signingKey)
+}
+
+// UserInfo returns the claims of the user an access token was issued to,
+// limited to the scopes granted to it.
+func (s *oidcServiceImpl) UserInfo(ctx context.Context, accessToken string) (*model.UserInfo, error) {
+	token, err := s.repo.GetOAuthTokenByHash(ctx, hashSecret(accessToken))
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || token.TokenType != model.OAuthTokenTypeAccess || token.UserID == 0 || !token.IsActive(time.Now().UTC()) {
+		return nil, ErrInvalidAccessToken
+	}
+	if !containsString(token.Scopes, model.OIDCScopeOpenID) {
+		return nil, ErrInsufficientScope
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Disabled {
+		return nil, ErrInvalidAccessToken
+	}
+
+	info := userInfoFor(*user, token.Scopes)
+	return &info, nil
+}
+
+// Logout ends the login session an ID token was issued for and returns the
+// URI the user must be sent to, which is empty when the client did not ask
+// for a redirect.
+func (s *oidcServiceImpl) Logout(ctx context.Context, req model.LogoutRequest, meta model.RequestMetadata) (string, error) {
+	if req.IDTokenHint == "" {
+		return "", &OAuthError{Code: OAuthErrInvalidRequest, Description: "id_token_hint is required"}
+	}
+	claims, err := s.parseIDTokenHint(req.IDTokenHint)
+	if err != nil {
+		return "", &OAuthError{Code: OAuthErrInvalidRequest, Description: "id_token_hint is invalid"}
+	}
+	if req.ClientID != "" && req.ClientID != claims.Audience {
+		return "", &OAuthError{Code: OAuthErrInvalidRequest, Description: "client_id does not match id_token_hint"}
+	}
+
+	// Check the redirect before logging out so a rejected request has no effect.
+	if req.PostLogoutRedirectURI != "" {
+		client, err := s.repo.GetOAuthClientByClientID(ctx, claims.Audience)
+		if err != nil {
+			return "", err
+		}
+		if client == nil || !client.AllowsPostLogoutRedirectURI(req.PostLogoutRedirectURI) {
+			return "", &OAuthError{Code: OAuthErrInvalidRequest, Description: "post_logout_redirect_uri is not registered for the client"}
+		}
+	}
+
+	if claims.SessionID != "" {
+		userID, _ := strconv.Atoi(claims.Subject)
+		user, err := s.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		if user != nil {
+			err := s.sessionService.RevokeSession(ctx, user.ID, user.Username, claims.SessionID, meta)
+			if err != nil && !errors.Is(err, ErrSessionNotFound) {
+				return "", err
+			}
+		}
+	}
+
+	if req.PostLogoutRedirectURI == "" {
+		return "", nil
+	}
+	params := url.Values{}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	return appendQuery(req.PostLogoutRedirectURI, params), nil
+}
+
+// parseIDTokenHint verifies an ID token issued by this provider. Expired
+// tokens are accepted because logout usually happens after they expire.
+func (s *oidcServiceImpl) parseIDTokenHint(tokenString string) (*model.IDTokenClaims, error) {
+	claims := &model.IDTokenClaims{}
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return &s.signingKey.PublicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.Issuer != s.issuer {
+		return nil, errors.New("the ID token was not issued by this provider")
+	}
+	return claims, nil
+}
+
+// userInfoFor builds the claims about the user released for the granted
+// scopes. Users cannot verify their email or phone yet, so both are
+// reported as unverified.
+func userInfoFor(user model.User, scopes []string) model.UserInfo {
+	info := model.UserInfo{Subject: strconv.Itoa(user.ID)}
+	unverified := false
+	if containsString(scopes, model.OIDCScopeProfile) {
+		info.PreferredUsername = user.Username
+	}
+	if containsString(scopes, model.OIDCScopeEmail) && user.Email != "" {
+		info.Email = user.Email
+		info.EmailVerified = &unverified
+	}
+	if containsString(scopes, model.OIDCScopePhone) && user.Phone != "" {
+		info.PhoneNumber = user.Phone
+		info.PhoneNumberVerified = &unverified
+	}
+	return info
+}
+
+// accessTokenHash computes the at_hash claim: the left half of the SHA-256
+// hash of the access token.
+func accessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+// keyThumbprint computes the RFC 7638 thumbprint of a public key, used as its key ID.
+func keyThumbprint(publicKey *rsa.PublicKey) string {
+	canonical, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+	})
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
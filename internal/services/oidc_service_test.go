@@ -0,0 +1,165 @@
+package services_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testIssuer = "https://login.example.com"
+
+var oidcUser = &model.User{ID: 7, Username: "ana", Email: "ana@example.com", Phone: "+34600000000"}
+
+func newTestSigningKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return key
+}
+
+func TestIssueIDToken(t *testing.T) {
+	key := newTestSigningKey(t)
+	mockUserRepo := new(mocks.UserRepository)
+	service := services.NewOIDCService(new(mocks.OAuthRepository), mockUserRepo, new(mocks.SessionService), key, testIssuer)
+	mockUserRepo.On("GetUserByID", mock.Anything, 7).Return(oidcUser, nil)
+
+	authTime := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	idToken, err := service.IssueIDToken(context.Background(), "spa", model.OAuthAuthorizationCode{
+		UserID:    7,
+		Scopes:    []string{"openid", "email"},
+		Nonce:     "n-0S6_WzA2Mj",
+		SessionID: "session-1",
+		AuthTime:  authTime,
+	}, "access-token")
+	assert.NoError(t, err)
+
+	claims := &model.IDTokenClaims{}
+	token, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, service.JWKS().Keys[0].KeyID, token.Header["kid"])
+		return &key.PublicKey, nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, token.Valid)
+	assert.Equal(t, "RS256", token.Method.Alg())
+	assert.Equal(t, testIssuer, claims.Issuer)
+	assert.Equal(t, "7", claims.Subject)
+	assert.Equal(t, "spa", claims.Audience)
+	assert.Equal(t, "n-0S6_WzA2Mj", claims.Nonce)
+	assert.Equal(t, "session-1", claims.SessionID)
+	assert.Equal(t, authTime.Unix(), claims.AuthTime)
+	assert.NotEmpty(t, claims.AccessTokenHash)
+	assert.Equal(t, "ana@example.com", claims.Email)
+	assert.NotNil(t, claims.EmailVerified)
+	assert.Empty(t, claims.PhoneNumber, "phone is only released with the phone scope")
+	assert.Empty(t, claims.PreferredUsername, "username is only released with the profile scope")
+}
+
+func TestUserInfo(t *testing.T) {
+	mockRepo := new(mocks.OAuthRepository)
+	mockUserRepo := new(mocks.UserRepository)
+	service := services.NewOIDCService(mockRepo, mockUserRepo, new(mocks.SessionService), newTestSigningKey(t), testIssuer)
+	mockUserRepo.On("GetUserByID", mock.Anything, 7).Return(oidcUser, nil)
+
+	accessToken := func(scopes ...string) *model.OAuthToken {
+		return &model.OAuthToken{TokenType: model.OAuthTokenTypeAccess, ClientID: "spa", UserID: 7, Scopes: scopes, ExpiresAt: time.Now().Add(time.Hour)}
+	}
+
+	t.Run("unknown token", func(t *testing.T) {
+		mockRepo.On("GetOAuthTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(nil, nil).Once()
+		_, err := service.UserInfo(context.Background(), "unknown")
+		assert.ErrorIs(t, err, services.ErrInvalidAccessToken)
+	})
+
+	t.Run("token without openid scope", func(t *testing.T) {
+		mockRepo.On("GetOAuthTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(accessToken("email"), nil).Once()
+		_, err := service.UserInfo(context.Background(), "token")
+		assert.ErrorIs(t, err, services.ErrInsufficientScope)
+	})
+
+	t.Run("claims follow the granted scopes", func(t *testing.T) {
+		mockRepo.On("GetOAuthTokenByHash", mock.Anything, mock.AnythingOfType("string")).Return(accessToken("openid", "profile", "phone"), nil).Once()
+		info, err := service.UserInfo(context.Background(), "token")
+		assert.NoError(t, err)
+		assert.Equal(t, "7", info.Subject)
+		assert.Equal(t, "ana", info.PreferredUsername)
+		assert.Equal(t, "+34600000000", info.PhoneNumber)
+		assert.Empty(t, info.Email)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	key := newTestSigningKey(t)
+	mockRepo := new(mocks.OAuthRepository)
+	mockUserRepo := new(mocks.UserRepository)
+	mockSessionService := new(mocks.SessionService)
+	service := services.NewOIDCService(mockRepo, mockUserRepo, mockSessionService, key, testIssuer)
+
+	client := &model.OAuthClient{ClientID: "spa", PostLogoutRedirectURIs: []string{"https://app.example.com/bye"}}
+	mockRepo.On("GetOAuthClientByClientID", mock.Anything, "spa").Return(client, nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, 7).Return(oidcUser, nil)
+
+	idToken, err := service.IssueIDToken(context.Background(), "spa", model.OAuthAuthorizationCode{UserID: 7, Scopes: []string{"openid"}, SessionID: "session-1"}, "access-token")
+	assert.NoError(t, err)
+
+	t.Run("missing id_token_hint", func(t *testing.T) {
+		_, err := service.Logout(context.Background(), model.LogoutRequest{}, model.RequestMetadata{})
+		assert.Equal(t, services.OAuthErrInvalidRequest, oauthErrorCode(err))
+	})
+
+	t.Run("token signed by another key", func(t *testing.T) {
+		other := services.NewOIDCService(mockRepo, mockUserRepo, mockSessionService, newTestSigningKey(t), testIssuer)
+		forged, _ := other.IssueIDToken(context.Background(), "spa", model.OAuthAuthorizationCode{UserID: 7, SessionID: "session-1"}, "access-token")
+		_, err := service.Logout(context.Background(), model.LogoutRequest{IDTokenHint: forged}, model.RequestMetadata{})
+		assert.Equal(t, services.OAuthErrInvalidRequest, oauthErrorCode(err))
+	})
+
+	t.Run("unregistered redirect does not log out", func(t *testing.T) {
+		_, err := service.Logout(context.Background(), model.LogoutRequest{IDTokenHint: idToken, PostLogoutRedirectURI: "https://evil.example.com"}, model.RequestMetadata{})
+		assert.Equal(t, services.OAuthErrInvalidRequest, oauthErrorCode(err))
+		mockSessionService.AssertNotCalled(t, "RevokeSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("revokes the session and redirects", func(t *testing.T) {
+		mockSessionService.On("RevokeSession", mock.Anything, 7, "ana", "session-1", mock.AnythingOfType("model.RequestMetadata")).Return(nil).Once()
+
+		redirectTo, err := service.Logout(context.Background(), model.LogoutRequest{
+			IDTokenHint:           idToken,
+			PostLogoutRedirectURI: "https://app.example.com/bye",
+			State:                 "xyz",
+		}, model.RequestMetadata{})
+		assert.NoError(t, err)
+		assert.Equal(t, "https://app.example.com/bye?state=xyz", redirectTo)
+		mockSessionService.AssertExpectations(t)
+	})
+}
+
+func TestAuthorizationCodeExchangeIssuesIDToken(t *testing.T) {
+	mockRepo := new(mocks.OAuthRepository)
+	mockUsers := new(mocks.UserRepository)
+	mockOIDCService := new(mocks.OIDCService)
+	service := services.NewOAuthService(mockRepo, mockUsers, mockOIDCService, new(mocks.AuditLogger), testIssuer)
+	verifier, challenge := pkcePair()
+
+	code := &model.OAuthAuthorizationCode{
+		ClientID: "spa", UserID: 7, RedirectURI: "https://app.example.com/callback",
+		Scopes: []string{"openid", "email"}, CodeChallenge: challenge, CodeChallengeMethod: "S256",
+		Nonce: "abc", ExpiresAt: time.Now().Add(time.Minute),
+	}
+	mockRepo.On("GetOAuthClientByClientID", mock.Anything, "spa").Return(publicClient, nil)
+	mockRepo.On("ConsumeAuthorizationCode", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(code, nil)
+	mockRepo.On("CreateOAuthToken", mock.Anything, mock.AnythingOfType("model.OAuthToken")).Return(nil)
+	mockUsers.On("GetUserByID", mock.Anything, 7).Return(&model.User{ID: 7, Username: "ana"}, nil)
+	mockOIDCService.On("IssueIDToken", mock.Anything, "spa", *code, mock.AnythingOfType("string")).Return("id-token", nil)
+
+	response, err := service.Token(context.Background(), model.TokenRequest{GrantType: model.GrantTypeAuthorizationCode, ClientID: "spa", Code: "code", CodeVerifier: verifier})
+	assert.NoError(t, err)
+	assert.Equal(t, "id-token", response.IDToken)
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// samlRequestLifetime is how long the user has to complete the sign in with
+// the identity provider of their organization.
+const samlRequestLifetime = 10 * time.Minute
+
+// ErrSAMLDomainNotAllowed is returned when an identity provider asserts an
+// email outside the domains of its tenant.
+var ErrSAMLDomainNotAllowed = newError(KindForbidden, "domain_not_allowed", nil)
+
+type SAMLService interface {
+	Metadata(connection string) ([]byte, error)
+	StartLogin(ctx context.Context, connection string) (*model.SAMLAuthnRequest, error)
+	ConsumeAssertion(ctx context.Context, connection, samlResponse, relayState, browserRelayState string, meta model.RequestMetadata) (string, error)
+}
+
+type samlServiceImpl struct {
+	repo        model.IdentityRepository
+	userRepo    model.UserRepository
+	userService UserService
+	auditLogger AuditLogger
+	providers   map[string]model.SAMLIdentityProvider
+}
+
+func NewSAMLService(repo model.IdentityRepository, userRepo model.UserRepository, userService UserService, auditLogger AuditLogger, providers []model.SAMLIdentityProvider) *samlServiceImpl {
+	service := &samlServiceImpl{
+		repo:        repo,
+		userRepo:    userRepo,
+		userService: userService,
+		auditLogger: auditLogger,
+		providers:   map[string]model.SAMLIdentityProvider{},
+	}
+	for _, provider := range providers {
+		service.providers[provider.Name()] = provider
+	}
+	return service
+}
+
+// Metadata returns the service provider metadata of a connection, which the
+// tenant imports into their identity provider.
+func (s *samlServiceImpl) Metadata(connection string) ([]byte, error) {
+	provider, ok := s.providers[connection]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider.Metadata()
+}
+
+// StartLogin stores a pending sign in and builds the authentication request
+// the browser must deliver to the identity provider.
+func (s *samlServiceImpl) StartLogin(ctx context.Context, connection string) (*model.SAMLAuthnRequest, error) {
+	provider, ok := s.providers[connection]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	relayState, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	req, err := provider.MakeAuthnRequest(relayState)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to build the SAML request", "connection", connection, "error", err)
+		return nil, ErrSocialLoginFailed
+	}
+
+	now := time.Now().UTC()
+	err = s.repo.CreateSocialLoginState(ctx, model.SocialLoginState{
+		StateHash: hashSecret(relayState),
+		Provider:  connection,
+		Nonce:     req.ID,
+		ExpiresAt: now.Add(samlRequestLifetime),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.RelayState = relayState
+	req.ExpiresAt = now.Add(samlRequestLifetime)
+	return req, nil
+}
+
+// ConsumeAssertion validates the response the identity provider posted to the
+// assertion consumer service and returns a token for the asserted user.
+// browserRelayState is the relay state the browser kept when the sign in
+// started; like the state of social sign ins, it must match.
+func (s *samlServiceImpl) ConsumeAssertion(ctx context.Context, connection, samlResponse, relayState, browserRelayState string, meta model.RequestMetadata) (string, error) {
+	provider, ok := s.providers[connection]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+	if samlResponse == "" || relayState == "" || subtle.ConstantTimeCompare([]byte(relayState), []byte(browserRelayState)) != 1 {
+		return "", ErrInvalidSocialState
+	}
+
+	pending, err := s.repo.ConsumeSocialLoginState(ctx, hashSecret(relayState))
+	if err != nil {
+		return "", err
+	}
+	if pending == nil || pending.Provider != connection || !time.Now().UTC().Before(pending.ExpiresAt) {
+		return "", ErrInvalidSocialState
+	}
+
+	identity, err := provider.ParseResponse(samlResponse, pending.Nonce)
+	if err != nil {
+		slog.WarnContext(ctx, "SAML sign in failed", "connection", connection, "error", err)
+		s.logEvent(ctx, model.AuditEventLogin, 0, "saml:"+connection, "saml "+connection, meta, ErrSocialLoginFailed)
+		return "", ErrSocialLoginFailed
+	}
+	return s.login(ctx, provider, *identity, meta)
+}
+
+// login signs in the user linked to the asserted identity. The first time an
+// identity is seen it is linked to the user with the same email when the
+// tenant owns the email domain, or a new user is provisioned otherwise.
+func (s *samlServiceImpl) login(ctx context.Context, provider model.SAMLIdentityProvider, identity model.ExternalIdentity, meta model.RequestMetadata) (string, error) {
+	actor := identity.Provider + ":" + identity.Subject
+	detail := "saml " + identity.Provider
+
+	link, err := s.repo.GetUserIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return "", err
+	}
+	if link != nil {
+		user, err := s.userRepo.GetUserByID(ctx, link.UserID)
+		if err != nil {
+			return "", err
+		}
+		if user == nil {
+			return "", ErrSocialLoginFailed
+		}
+		return s.userService.LoginExternalUser(ctx, *user, meta)
+	}
+
+	if identity.Email == "" {
+		s.logEvent(ctx, model.AuditEventLogin, 0, actor, detail, meta, ErrSocialEmailRequired)
+		return "", ErrSocialEmailRequired
+	}
+	ownsDomain := containsString(provider.Domains(), emailDomain(identity.Email))
+	if len(provider.Domains()) > 0 && !ownsDomain {
+		s.logEvent(ctx, model.AuditEventLogin, 0, actor, detail, meta, ErrSAMLDomainNotAllowed)
+		return "", ErrSAMLDomainNotAllowed
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, identity.Email)
+	if err != nil {
+		return "", err
+	}
+	if user != nil {
+		if !ownsDomain {
+			s.logEvent(ctx, model.AuditEventLogin, user.ID, actor, detail, meta, ErrSocialEmailConflict)
+			return "", ErrSocialEmailConflict
+		}
+	} else {
+		if user, err = s.createUser(ctx, identity); err != nil {
+			s.logEvent(ctx, model.AuditEventRegistration, 0, actor, detail, meta, err)
+			return "", err
+		}
+		s.logEvent(ctx, model.AuditEventRegistration, user.ID, user.Username, detail, meta, nil)
+	}
+
+	err = s.repo.CreateUserIdentity(ctx, model.UserIdentity{
+		UserID:    user.ID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: time.Now().UTC(),
+	})
+	s.logEvent(ctx, model.AuditEventIdentityLink, user.ID, user.Username, detail, meta, err)
+	if err != nil {
+		return "", err
+	}
+
+	return s.userService.LoginExternalUser(ctx, *user, meta)
+}
+
+// createUser provisions a password-less user from the assertion attributes
+func (s *samlServiceImpl) createUser(ctx context.Context, identity model.ExternalIdentity) (*model.User, error) {
+	username, err := availableUsername(ctx, s.userRepo, identity.PreferredUsername, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	phone, err := availablePhone(ctx, s.userRepo, identity.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.CreateUser(ctx, model.User{Username: username, Email: identity.Email, Phone: phone}); err != nil {
+		slog.ErrorContext(ctx, "Failed to create the user", "error", err)
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+	user, err := s.userRepo.GetUserByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errCreatedUserMissing
+	}
+	return user, nil
+}
+
+// logEvent records the outcome of a SAML sign in in the audit log.
+// A nil err means the action succeeded.
+func (s *samlServiceImpl) logEvent(ctx context.Context, eventType string, userID int, actor, detail string, meta model.RequestMetadata, err error) {
+	event := model.AuditEvent{
+		EventType: eventType,
+		UserID:    userID,
+		Actor:     actor,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    detail,
+	}
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Detail = err.Error()
+	}
+	s.auditLogger.LogEvent(ctx, event)
+}
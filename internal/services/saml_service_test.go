@@ -0,0 +1,235 @@
+package services_test
+
+import (
+	"context"
+	"exercise-login-back-go/internal/config"
+	"exercise-login-back-go/internal/connectors"
+	"exercise-login-back-go/internal/connectors/samltest"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/repositories"
+	"exercise-login-back-go/internal/services"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// samlFixture wires the SAML service to a connector trusting a stub identity provider
+type samlFixture struct {
+	idp         *samltest.IdP
+	connector   model.SAMLIdentityProvider
+	repo        *mocks.IdentityRepository
+	userRepo    *mocks.UserRepository
+	userService *mocks.UserService
+	service     services.SAMLService
+}
+
+func newSAMLFixture(t *testing.T, domains []string) *samlFixture {
+	idp := samltest.NewIdP(t)
+	key, certificate, err := connectors.LoadSAMLKeyPair("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	connector, err := connectors.NewSAMLConnector(config.SAMLConnection{
+		Name:              "acme",
+		IDPMetadataFile:   idp.MetadataFile(),
+		UsernameAttribute: "uid",
+		EmailAttribute:    "email",
+		PhoneAttribute:    "phone",
+		Domains:           domains,
+	}, "https://login.example.com", key, certificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &samlFixture{
+		idp:         idp,
+		connector:   connector,
+		repo:        new(mocks.IdentityRepository),
+		userRepo:    new(mocks.UserRepository),
+		userService: new(mocks.UserService),
+	}
+	auditLogger := new(mocks.AuditLogger)
+	auditLogger.On("LogEvent", mock.Anything, mock.AnythingOfType("model.AuditEvent")).Return()
+	f.service = services.NewSAMLService(f.repo, f.userRepo, f.userService, auditLogger, []model.SAMLIdentityProvider{connector})
+	return f
+}
+
+// start begins a sign in and returns the relay state and the signed response
+// of the stub provider asserting the given attributes.
+func (f *samlFixture) start(t *testing.T, nameID string, attributes map[string]string) (string, string) {
+	relayState, requestID := f.startRequest(t)
+	return relayState, f.respond(requestID, nameID, attributes)
+}
+
+// startRequest begins a sign in and returns the relay state and request ID
+func (f *samlFixture) startRequest(t *testing.T) (string, string) {
+	var pending model.SocialLoginState
+	f.repo.On("CreateSocialLoginState", mock.Anything, mock.AnythingOfType("model.SocialLoginState")).
+		Run(func(args mock.Arguments) { pending = args.Get(1).(model.SocialLoginState) }).
+		Return(nil).Once()
+
+	req, err := f.service.StartLogin(context.Background(), "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, req.ID, pending.Nonce)
+	redirectURL, _ := url.Parse(req.RedirectURL)
+	relayState := redirectURL.Query().Get("RelayState")
+	assert.Equal(t, relayState, req.RelayState)
+
+	f.repo.On("ConsumeSocialLoginState", mock.Anything, mock.AnythingOfType("string")).Return(&pending, nil).Once()
+	return relayState, req.ID
+}
+
+// respond returns the response of the stub provider to the request
+func (f *samlFixture) respond(requestID, nameID string, attributes map[string]string) string {
+	return f.idp.Response(samltest.Assertion{
+		RequestID:   requestID,
+		Destination: "https://login.example.com/saml/acme/acs",
+		Audience:    "https://login.example.com/saml/acme/metadata",
+		NameID:      nameID,
+		Attributes:  attributes,
+	})
+}
+
+func TestSAMLLoginProvisionsUser(t *testing.T) {
+	f := newSAMLFixture(t, nil)
+	relayState, response := f.start(t, "jane.doe", map[string]string{"uid": "jane", "email": "jane@acme.example.com", "phone": "5512345678"})
+	created := &model.User{ID: 9, Username: "jane", Email: "jane@acme.example.com", Phone: "5512345678"}
+
+	f.repo.On("GetUserIdentity", mock.Anything, "acme", "jane.doe").Return(nil, nil)
+	f.userRepo.On("GetUserByEmail", mock.Anything, "jane@acme.example.com").Return(nil, nil).Once()
+	f.userRepo.On("GetUserByEmailOrUsername", mock.Anything, "jane").Return(nil, nil)
+	f.userRepo.On("GetUserByEmailOrPhone", mock.Anything, "", "5512345678").Return(nil, nil)
+	f.userRepo.On("CreateUser", mock.Anything, model.User{Username: "jane", Email: "jane@acme.example.com", Phone: "5512345678"}).Return(nil)
+	f.userRepo.On("GetUserByEmail", mock.Anything, "jane@acme.example.com").Return(created, nil).Once()
+	f.repo.On("CreateUserIdentity", mock.Anything, mock.MatchedBy(func(identity model.UserIdentity) bool {
+		return identity.UserID == 9 && identity.Provider == "acme" && identity.Subject == "jane.doe"
+	})).Return(nil)
+	f.userService.On("LoginExternalUser", mock.Anything, *created, mock.AnythingOfType("model.RequestMetadata")).Return("jwt", nil)
+
+	token, err := f.service.ConsumeAssertion(context.Background(), "acme", response, relayState, relayState, model.RequestMetadata{})
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt", token)
+	f.userRepo.AssertExpectations(t)
+	f.repo.AssertExpectations(t)
+}
+
+func TestSAMLLoginLinkedIdentity(t *testing.T) {
+	f := newSAMLFixture(t, nil)
+	relayState, response := f.start(t, "jane.doe", map[string]string{"email": "jane@acme.example.com"})
+	user := &model.User{ID: 9, Username: "jane", Email: "jane@acme.example.com"}
+
+	f.repo.On("GetUserIdentity", mock.Anything, "acme", "jane.doe").Return(&model.UserIdentity{UserID: 9, Provider: "acme", Subject: "jane.doe"}, nil)
+	f.userRepo.On("GetUserByID", mock.Anything, 9).Return(user, nil)
+	f.userService.On("LoginExternalUser", mock.Anything, *user, mock.AnythingOfType("model.RequestMetadata")).Return("jwt", nil)
+
+	token, err := f.service.ConsumeAssertion(context.Background(), "acme", response, relayState, relayState, model.RequestMetadata{})
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt", token)
+	f.repo.AssertNotCalled(t, "CreateUserIdentity", mock.Anything, mock.Anything)
+}
+
+func TestSAMLLoginExistingEmail(t *testing.T) {
+	existing := &model.User{ID: 4, Username: "jane", Email: "jane@acme.example.com"}
+
+	t.Run("Owned Domain Links User", func(t *testing.T) {
+		f := newSAMLFixture(t, []string{"acme.example.com"})
+		relayState, response := f.start(t, "jane.doe", map[string]string{"email": "jane@acme.example.com"})
+
+		f.repo.On("GetUserIdentity", mock.Anything, "acme", "jane.doe").Return(nil, nil)
+		f.userRepo.On("GetUserByEmail", mock.Anything, "jane@acme.example.com").Return(existing, nil)
+		f.repo.On("CreateUserIdentity", mock.Anything, mock.MatchedBy(func(identity model.UserIdentity) bool {
+			return identity.UserID == 4 && identity.Subject == "jane.doe"
+		})).Return(nil)
+		f.userService.On("LoginExternalUser", mock.Anything, *existing, mock.AnythingOfType("model.RequestMetadata")).Return("jwt", nil)
+
+		token, err := f.service.ConsumeAssertion(context.Background(), "acme", response, relayState, relayState, model.RequestMetadata{})
+		assert.NoError(t, err)
+		assert.Equal(t, "jwt", token)
+		f.userRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Username Matching The Email Is Not Linked", func(t *testing.T) {
+		f := newSAMLFixture(t, []string{"acme.example.com"})
+		users := repositories.NewMemoryUserRepository()
+		assert.NoError(t, users.CreateUser(context.Background(), model.User{Username: "jane@acme.example.com", Email: "mallory@example.com"}))
+		auditLogger := new(mocks.AuditLogger)
+		auditLogger.On("LogEvent", mock.Anything, mock.AnythingOfType("model.AuditEvent")).Return()
+		f.service = services.NewSAMLService(f.repo, users, f.userService, auditLogger, []model.SAMLIdentityProvider{f.connector})
+		relayState, response := f.start(t, "jane.doe", map[string]string{"uid": "jane", "email": "jane@acme.example.com"})
+
+		var linked model.UserIdentity
+		f.repo.On("GetUserIdentity", mock.Anything, "acme", "jane.doe").Return(nil, nil)
+		f.repo.On("CreateUserIdentity", mock.Anything, mock.AnythingOfType("model.UserIdentity")).
+			Run(func(args mock.Arguments) { linked = args.Get(1).(model.UserIdentity) }).
+			Return(nil)
+		f.userService.On("LoginExternalUser", mock.Anything, mock.AnythingOfType("model.User"), mock.AnythingOfType("model.RequestMetadata")).Return("jwt", nil)
+
+		_, err := f.service.ConsumeAssertion(context.Background(), "acme", response, relayState, relayState, model.RequestMetadata{})
+		assert.NoError(t, err)
+		created, _ := users.GetUserByEmail(context.Background(), "jane@acme.example.com")
+		if assert.NotNil(t, created) {
+			assert.Equal(t, "jane", created.Username)
+			assert.Equal(t, created.ID, linked.UserID)
+		}
+	})
+
+	t.Run("Unowned Domain Conflicts", func(t *testing.T) {
+		f := newSAMLFixture(t, nil)
+		relayState, response := f.start(t, "jane.doe", map[string]string{"email": "jane@acme.example.com"})
+
+		f.repo.On("GetUserIdentity", mock.Anything, "acme", "jane.doe").Return(nil, nil)
+		f.userRepo.On("GetUserByEmail", mock.Anything, "jane@acme.example.com").Return(existing, nil)
+
+		_, err := f.service.ConsumeAssertion(context.Background(), "acme", response, relayState, relayState, model.RequestMetadata{})
+		assert.ErrorIs(t, err, services.ErrSocialEmailConflict)
+	})
+}
+
+func TestSAMLLoginRejections(t *testing.T) {
+	t.Run("Domain Not Allowed", func(t *testing.T) {
+		f := newSAMLFixture(t, []string{"acme.example.com"})
+		relayState, response := f.start(t, "mallory", map[string]string{"email": "mallory@other.example.com"})
+
+		f.repo.On("GetUserIdentity", mock.Anything, "acme", "mallory").Return(nil, nil)
+
+		_, err := f.service.ConsumeAssertion(context.Background(), "acme", response, relayState, relayState, model.RequestMetadata{})
+		assert.ErrorIs(t, err, services.ErrSAMLDomainNotAllowed)
+	})
+
+	t.Run("Forged Response", func(t *testing.T) {
+		f := newSAMLFixture(t, nil)
+		relayState, requestID := f.startRequest(t)
+		f.idp.Key = samltest.NewIdP(t).Key
+
+		response := f.respond(requestID, "jane.doe", map[string]string{"email": "jane@acme.example.com"})
+		_, err := f.service.ConsumeAssertion(context.Background(), "acme", response, relayState, relayState, model.RequestMetadata{})
+		assert.ErrorIs(t, err, services.ErrSocialLoginFailed)
+	})
+
+	t.Run("Unknown Relay State", func(t *testing.T) {
+		f := newSAMLFixture(t, nil)
+		f.repo.On("ConsumeSocialLoginState", mock.Anything, mock.AnythingOfType("string")).Return(nil, nil)
+
+		_, err := f.service.ConsumeAssertion(context.Background(), "acme", "response", "unknown", "unknown", model.RequestMetadata{})
+		assert.ErrorIs(t, err, services.ErrInvalidSocialState)
+	})
+
+	t.Run("Relay State From Another Browser", func(t *testing.T) {
+		f := newSAMLFixture(t, nil)
+		relayState, _ := f.startRequest(t)
+
+		_, err := f.service.ConsumeAssertion(context.Background(), "acme", "response", relayState, "", model.RequestMetadata{})
+		assert.ErrorIs(t, err, services.ErrInvalidSocialState)
+		f.repo.AssertNotCalled(t, "ConsumeSocialLoginState", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown Connection", func(t *testing.T) {
+		f := newSAMLFixture(t, nil)
+
+		_, err := f.service.StartLogin(context.Background(), "globex")
+		assert.ErrorIs(t, err, services.ErrUnknownProvider)
+	})
+}
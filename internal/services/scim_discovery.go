@@ -0,0 +1,63 @@
+package services
+
+import "exercise-login-back-go/internal/model"
+
+// ServiceProviderConfig describes the SCIM features the service supports.
+func (s *scimServiceImpl) ServiceProviderConfig() model.SCIMServiceProviderConfig {
+	return model.SCIMServiceProviderConfig{
+		Schemas:        []string{model.SCIMSchemaServiceProviderConfig},
+		Patch:          model.SCIMSupported{Supported: true},
+		Bulk:           model.SCIMBulkSupport{Supported: false},
+		Filter:         model.SCIMFilterSupport{Supported: true, MaxResults: scimMaxCount},
+		ChangePassword: model.SCIMSupported{Supported: true},
+		Sort:           model.SCIMSupported{Supported: false},
+		ETag:           model.SCIMSupported{Supported: false},
+		AuthenticationSchemes: []model.SCIMAuthenticationType{{
+			Type:        "oauthbearertoken",
+			Name:        "Bearer Token",
+			Description: "Token de aprovisionamiento emitido por un administrador",
+			Primary:     true,
+		}},
+		Meta: model.SCIMMeta{ResourceType: "ServiceProviderConfig", Location: s.baseURL + "/ServiceProviderConfig"},
+	}
+}
+
+// ResourceTypes lists the resource types that can be provisioned.
+func (s *scimServiceImpl) ResourceTypes() []model.SCIMResourceType {
+	return []model.SCIMResourceType{{
+		Schemas:     []string{model.SCIMSchemaResourceType},
+		ID:          "User",
+		Name:        "User",
+		Endpoint:    "/Users",
+		Description: "Cuenta de usuario",
+		Schema:      model.SCIMSchemaUser,
+		Meta:        model.SCIMMeta{ResourceType: "ResourceType", Location: s.baseURL + "/ResourceTypes/User"},
+	}}
+}
+
+// Schemas describes the attributes of the resources, limited to the ones the
+// service stores.
+func (s *scimServiceImpl) Schemas() []model.SCIMSchema {
+	multiValue := func(description string) []model.SCIMSchemaAttribute {
+		return []model.SCIMSchemaAttribute{
+			{Name: "value", Type: "string", Description: description, Mutability: "readWrite", Returned: "default", Uniqueness: "server"},
+			{Name: "type", Type: "string", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+			{Name: "primary", Type: "boolean", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+		}
+	}
+
+	return []model.SCIMSchema{{
+		Schemas:     []string{model.SCIMSchemaSchema},
+		ID:          model.SCIMSchemaUser,
+		Name:        "User",
+		Description: "Cuenta de usuario",
+		Attributes: []model.SCIMSchemaAttribute{
+			{Name: "userName", Type: "string", Description: "Nombre de usuario", Required: true, Mutability: "readWrite", Returned: "default", Uniqueness: "server"},
+			{Name: "active", Type: "boolean", Description: "Indica si el usuario puede iniciar sesión", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+			{Name: "password", Type: "string", Description: "Contraseña; nunca se devuelve", Mutability: "writeOnly", Returned: "never", Uniqueness: "none"},
+			{Name: "emails", Type: "complex", MultiValued: true, Description: "Se guarda únicamente el correo principal", Required: true, Mutability: "readWrite", Returned: "default", Uniqueness: "none", SubAttributes: multiValue("Correo electrónico")},
+			{Name: "phoneNumbers", Type: "complex", MultiValued: true, Description: "Se guarda únicamente el teléfono principal, de 10 dígitos", Mutability: "readWrite", Returned: "default", Uniqueness: "none", SubAttributes: multiValue("Teléfono")},
+		},
+		Meta: model.SCIMMeta{ResourceType: "Schema", Location: s.baseURL + "/Schemas/" + model.SCIMSchemaUser},
+	}}
+}
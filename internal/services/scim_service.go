@@ -0,0 +1,566 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/validation"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// scimDefaultCount and scimMaxCount bound the users returned per page.
+	scimDefaultCount = 100
+	scimMaxCount     = 200
+	// scimUserSchemaPrefix may qualify attribute paths in filters and patches.
+	scimUserSchemaPrefix = "urn:ietf:params:scim:schemas:core:2.0:user:"
+)
+
+// SCIM error types (RFC 7644, section 3.12).
+const (
+	SCIMErrInvalidFilter = "invalidFilter"
+	SCIMErrInvalidSyntax = "invalidSyntax"
+	SCIMErrInvalidValue  = "invalidValue"
+	SCIMErrUniqueness    = "uniqueness"
+	SCIMErrMutability    = "mutability"
+)
+
+var (
+	ErrSCIMClientNotFound = newError(KindNotFound, "scim_client_not_found", nil)
+	ErrInvalidSCIMToken   = newError(KindUnauthorized, "invalid_scim_token", nil)
+)
+
+// scimFilterExpression matches the `attribute eq "value"` filters supported
+var scimFilterExpression = regexp.MustCompile(`(?i)^\s*([a-z0-9:.]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// SCIMError is a SCIM protocol error reported with its HTTP status and type.
+// Key and Params identify the detail message in the i18n catalog, which is
+// shown in the locale of the request.
+type SCIMError struct {
+	Status   int
+	ScimType string
+	Key      string
+	Params   i18n.Params
+}
+
+func (e *SCIMError) Error() string {
+	return i18n.Message(i18n.DefaultLocale, e.Key, e.Params)
+}
+
+type SCIMService interface {
+	CreateClient(ctx context.Context, req model.SCIMClientCreateRequest) (*model.SCIMClient, string, error)
+	GetClients(ctx context.Context) ([]model.SCIMClient, error)
+	DeleteClient(ctx context.Context, id int) error
+	AuthenticateClient(ctx context.Context, token string) (*model.SCIMClient, error)
+	CreateUser(ctx context.Context, client model.SCIMClient, req model.SCIMUser, meta model.RequestMetadata) (*model.SCIMUser, error)
+	GetUser(ctx context.Context, client model.SCIMClient, id string) (*model.SCIMUser, error)
+	ListUsers(ctx context.Context, client model.SCIMClient, filter string, startIndex, count int) (*model.SCIMListResponse, error)
+	ReplaceUser(ctx context.Context, client model.SCIMClient, id string, req model.SCIMUser, meta model.RequestMetadata) (*model.SCIMUser, error)
+	PatchUser(ctx context.Context, client model.SCIMClient, id string, req model.SCIMPatchRequest, meta model.RequestMetadata) (*model.SCIMUser, error)
+	DeleteUser(ctx context.Context, client model.SCIMClient, id string, meta model.RequestMetadata) error
+	ServiceProviderConfig() model.SCIMServiceProviderConfig
+	ResourceTypes() []model.SCIMResourceType
+	Schemas() []model.SCIMSchema
+}
+
+type scimServiceImpl struct {
+	repo           model.SCIMRepository
+	userRepo       model.UserRepository
+	sessionService SessionService
+	auditLogger    AuditLogger
+	baseURL        string
+}
+
+// NewSCIMService creates the SCIM 2.0 provisioning service. Resource
+// locations are published under baseURL/scim/v2.
+func NewSCIMService(repo model.SCIMRepository, userRepo model.UserRepository, sessionService SessionService, auditLogger AuditLogger, baseURL string) *scimServiceImpl {
+	return &scimServiceImpl{
+		repo:           repo,
+		userRepo:       userRepo,
+		sessionService: sessionService,
+		auditLogger:    auditLogger,
+		baseURL:        baseURL + "/scim/v2",
+	}
+}
+
+// CreateClient registers a provisioning client and returns its bearer token,
+// which is only shown once.
+func (s *scimServiceImpl) CreateClient(ctx context.Context, req model.SCIMClientCreateRequest) (*model.SCIMClient, string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, "", newError(KindValidation, "invalid_client_metadata.client_name_required", nil)
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	client := model.SCIMClient{
+		Name:      name,
+		TokenHash: hashSecret(token),
+		CreatedAt: time.Now().UTC(),
+	}
+	client.ID, err = s.repo.CreateSCIMClient(ctx, client)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create the SCIM client", "error", err)
+		return nil, "", errors.New("error al registrar el cliente")
+	}
+	return &client, token, nil
+}
+
+// GetClients lists the provisioning clients.
+func (s *scimServiceImpl) GetClients(ctx context.Context) ([]model.SCIMClient, error) {
+	return s.repo.GetSCIMClients(ctx)
+}
+
+// DeleteClient removes a provisioning client, invalidating its token.
+func (s *scimServiceImpl) DeleteClient(ctx context.Context, id int) error {
+	client, err := s.repo.GetSCIMClientByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return ErrSCIMClientNotFound
+	}
+	return s.repo.DeleteSCIMClient(ctx, id)
+}
+
+// AuthenticateClient returns the provisioning client owning a bearer token.
+func (s *scimServiceImpl) AuthenticateClient(ctx context.Context, token string) (*model.SCIMClient, error) {
+	if token == "" {
+		return nil, ErrInvalidSCIMToken
+	}
+	client, err := s.repo.GetSCIMClientByTokenHash(ctx, hashSecret(token))
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, ErrInvalidSCIMToken
+	}
+	return client, nil
+}
+
+// CreateUser provisions a new user owned by the client. Users without a
+// password can only sign in through an upstream identity provider.
+func (s *scimServiceImpl) CreateUser(ctx context.Context, client model.SCIMClient, req model.SCIMUser, meta model.RequestMetadata) (*model.SCIMUser, error) {
+	user, err := userFromSCIM(ctx, model.User{SCIMClientID: client.ID}, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUniqueness(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, model.ErrDuplicateUser) {
+			return nil, &SCIMError{Status: http.StatusConflict, ScimType: SCIMErrUniqueness, Key: "scim.uniqueness"}
+		}
+		slog.ErrorContext(ctx, "Failed to create the user", "error", err)
+		s.logEvent(ctx, model.AuditEventRegistration, 0, user.Username, client, meta, err)
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+	created, err := s.userRepo.GetUserByEmailOrUsername(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, errCreatedUserMissing
+	}
+	s.logEvent(ctx, model.AuditEventRegistration, created.ID, created.Username, client, meta, nil)
+
+	result := s.toSCIMUser(*created)
+	return &result, nil
+}
+
+// GetUser returns a user of the client by its SCIM identifier.
+func (s *scimServiceImpl) GetUser(ctx context.Context, client model.SCIMClient, id string) (*model.SCIMUser, error) {
+	user, err := s.findUser(ctx, client, id)
+	if err != nil {
+		return nil, err
+	}
+	result := s.toSCIMUser(*user)
+	return &result, nil
+}
+
+// ListUsers returns a page of the users of the client matching the filter.
+// Pages start at startIndex, which is 1-based as in SCIM.
+func (s *scimServiceImpl) ListUsers(ctx context.Context, client model.SCIMClient, filter string, startIndex, count int) (*model.SCIMListResponse, error) {
+	userFilter, err := parseSCIMFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	userFilter.SCIMClientID = client.ID
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > scimMaxCount {
+		count = scimMaxCount
+	}
+	userFilter.Offset = startIndex - 1
+	// A count of zero only asks for the total; the repository treats a zero limit as no limit.
+	userFilter.Limit = count
+	if count == 0 {
+		userFilter.Limit = 1
+	}
+
+	users, total, err := s.userRepo.GetUsers(ctx, userFilter)
+	if err != nil {
+		return nil, err
+	}
+	resources := []model.SCIMUser{}
+	for i := 0; i < len(users) && i < count; i++ {
+		resources = append(resources, s.toSCIMUser(users[i]))
+	}
+
+	return &model.SCIMListResponse{
+		Schemas:      []string{model.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+// ReplaceUser replaces the attributes of a user. The password is kept when
+// the request does not include one.
+func (s *scimServiceImpl) ReplaceUser(ctx context.Context, client model.SCIMClient, id string, req model.SCIMUser, meta model.RequestMetadata) (*model.SCIMUser, error) {
+	current, err := s.findUser(ctx, client, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.update(ctx, client, *current, req, meta)
+}
+
+// PatchUser applies add, replace and remove operations to a user.
+// Attributes the service does not store are ignored.
+func (s *scimServiceImpl) PatchUser(ctx context.Context, client model.SCIMClient, id string, req model.SCIMPatchRequest, meta model.RequestMetadata) (*model.SCIMUser, error) {
+	if len(req.Operations) == 0 {
+		return nil, &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMErrInvalidSyntax, Key: "scim.no_operations"}
+	}
+	current, err := s.findUser(ctx, client, id)
+	if err != nil {
+		return nil, err
+	}
+
+	patched := s.toSCIMUser(*current)
+	for _, operation := range req.Operations {
+		switch strings.ToLower(operation.Op) {
+		case "add", "replace":
+			err = applySCIMValue(&patched, operation.Path, operation.Value)
+		case "remove":
+			err = removeSCIMValue(&patched, operation.Path)
+		default:
+			err = &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMErrInvalidSyntax, Key: "scim.unsupported_operation", Params: i18n.Params{"op": operation.Op}}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.update(ctx, client, *current, patched, meta)
+}
+
+// DeleteUser removes a user and everything that belongs to them.
+func (s *scimServiceImpl) DeleteUser(ctx context.Context, client model.SCIMClient, id string, meta model.RequestMetadata) error {
+	user, err := s.findUser(ctx, client, id)
+	if err != nil {
+		return err
+	}
+	err = s.userRepo.DeleteUser(ctx, user.ID)
+	s.logEvent(ctx, model.AuditEventUserDeletion, user.ID, user.Username, client, meta, err)
+	return err
+}
+
+// update saves the attributes of a SCIM user over the current user. Disabling
+// a user ends all of their sessions. A new password is audited on its own, so
+// password changes can be told apart from other updates.
+func (s *scimServiceImpl) update(ctx context.Context, client model.SCIMClient, current model.User, req model.SCIMUser, meta model.RequestMetadata) (*model.SCIMUser, error) {
+	user, err := userFromSCIM(ctx, current, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUniqueness(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "Failed to update the user", "error", err)
+		s.logEvent(ctx, model.AuditEventUserUpdate, user.ID, user.Username, client, meta, err)
+		return nil, errors.New("error al actualizar el usuario")
+	}
+	s.logEvent(ctx, model.AuditEventUserUpdate, user.ID, user.Username, client, meta, nil)
+	if user.Password != current.Password {
+		s.logEvent(ctx, model.AuditEventPasswordChange, user.ID, user.Username, client, meta, nil)
+	}
+
+	if user.Disabled && !current.Disabled {
+		if err := s.sessionService.RevokeAllSessions(ctx, user.ID, user.Username, meta); err != nil {
+			slog.ErrorContext(ctx, "Failed to revoke the sessions of disabled user", "userId", user.ID, "error", err)
+		}
+	}
+
+	result := s.toSCIMUser(user)
+	return &result, nil
+}
+
+// findUser returns the user with the SCIM identifier or a not found error.
+// Users provisioned by another client, or not provisioned at all, are
+// reported as not found.
+func (s *scimServiceImpl) findUser(ctx context.Context, client model.SCIMClient, id string) (*model.User, error) {
+	notFound := &SCIMError{Status: http.StatusNotFound, Key: "scim.user_not_found", Params: i18n.Params{"id": id}}
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, notFound
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.SCIMClientID != client.ID {
+		return nil, notFound
+	}
+	return user, nil
+}
+
+// checkUniqueness rejects a username, email or phone used by another user
+func (s *scimServiceImpl) checkUniqueness(ctx context.Context, user model.User) error {
+	conflict := &SCIMError{Status: http.StatusConflict, ScimType: SCIMErrUniqueness, Key: "scim.uniqueness"}
+	for _, value := range []string{user.Username, user.Email} {
+		existing, err := s.userRepo.GetUserByEmailOrUsername(ctx, value)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ID != user.ID {
+			return conflict
+		}
+	}
+	if user.Phone != "" {
+		existing, err := s.userRepo.GetUserByEmailOrPhone(ctx, "", user.Phone)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.ID != user.ID {
+			return conflict
+		}
+	}
+	return nil
+}
+
+// toSCIMUser maps a user onto its SCIM representation
+func (s *scimServiceImpl) toSCIMUser(user model.User) model.SCIMUser {
+	active := !user.Disabled
+	id := strconv.Itoa(user.ID)
+	result := model.SCIMUser{
+		Schemas:  []string{model.SCIMSchemaUser},
+		ID:       id,
+		UserName: user.Username,
+		Active:   &active,
+		Emails:   []model.SCIMMultiValue{{Value: user.Email, Type: "work", Primary: true}},
+		Meta:     &model.SCIMMeta{ResourceType: "User", Location: s.baseURL + "/Users/" + id},
+	}
+	if user.Phone != "" {
+		result.PhoneNumbers = []model.SCIMMultiValue{{Value: user.Phone, Type: "work", Primary: true}}
+	}
+	return result
+}
+
+// userFromSCIM applies the attributes of a SCIM user to a user, validating
+// them like a registration does.
+func userFromSCIM(ctx context.Context, current model.User, req model.SCIMUser) (model.User, error) {
+	user := current
+	user.Username = strings.TrimSpace(req.UserName)
+	if user.Username == "" {
+		return user, &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMErrInvalidValue, Key: "scim.username_required"}
+	}
+
+	user.Email = strings.TrimSpace(primarySCIMValue(req.Emails))
+	if !validation.IsEmail(user.Email) {
+		return user, &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMErrInvalidValue, Key: "invalid_email"}
+	}
+
+	user.Phone = phoneDisallowed.ReplaceAllString(primarySCIMValue(req.PhoneNumbers), "")
+	if user.Phone != "" && !validation.IsPhone(user.Phone) {
+		return user, &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMErrInvalidValue, Key: "invalid_phone"}
+	}
+
+	if req.Active != nil {
+		user.Disabled = !*req.Active
+	}
+
+	if req.Password != "" {
+		if key := validation.CheckPassword(req.Password); key != "" {
+			return user, &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMErrInvalidValue, Key: key}
+		}
+		hash, err := hashPassword(ctx, req.Password)
+		if err != nil {
+			return user, err
+		}
+		user.Password = hash
+	}
+	return user, nil
+}
+
+// primarySCIMValue returns the primary value of a multi-valued attribute, or
+// its first value when none is marked as primary
+func primarySCIMValue(values []model.SCIMMultiValue) string {
+	for _, value := range values {
+		if value.Primary {
+			return value.Value
+		}
+	}
+	if len(values) > 0 {
+		return values[0].Value
+	}
+	return ""
+}
+
+// parseSCIMFilter converts a `userName eq "..."` or `emails eq "..."` filter
+// into a user filter
+func parseSCIMFilter(filter string) (model.UserFilter, error) {
+	if strings.TrimSpace(filter) == "" {
+		return model.UserFilter{}, nil
+	}
+	invalid := &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMErrInvalidFilter, Key: "scim.invalid_filter"}
+
+	match := scimFilterExpression.FindStringSubmatch(filter)
+	if match == nil {
+		return model.UserFilter{}, invalid
+	}
+	value, err := strconv.Unquote(`"` + match[2] + `"`)
+	if err != nil {
+		return model.UserFilter{}, invalid
+	}
+
+	switch normalizeSCIMPath(match[1]) {
+	case "username":
+		return model.UserFilter{Username: value}, nil
+	case "emails", "emails.value":
+		return model.UserFilter{Email: value}, nil
+	}
+	return model.UserFilter{}, invalid
+}
+
+// normalizeSCIMPath lower-cases an attribute path, removes the schema prefix
+// and drops value filters, so `emails[type eq "work"].value` becomes
+// `emails.value`
+func normalizeSCIMPath(path string) string {
+	path = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(path)), scimUserSchemaPrefix)
+	if start := strings.Index(path, "["); start >= 0 {
+		if end := strings.Index(path[start:], "]"); end >= 0 {
+			path = path[:start] + path[start+end+1:]
+		}
+	}
+	return path
+}
+
+// applySCIMValue sets the attribute at path. Without a path the value is an
+// object whose members are applied one by one.
+func applySCIMValue(user *model.SCIMUser, path string, value json.RawMessage) error {
+	invalid := &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMErrInvalidValue, Key: "scim.invalid_value", Params: i18n.Params{"path": path}}
+
+	if path == "" {
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(value, &attributes); err != nil {
+			return &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMErrInvalidSyntax, Key: "scim.object_required"}
+		}
+		for name, attribute := range attributes {
+			if err := applySCIMValue(user, name, attribute); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch normalizeSCIMPath(path) {
+	case "username":
+		if err := json.Unmarshal(value, &user.UserName); err != nil {
+			return invalid
+		}
+	case "password":
+		if err := json.Unmarshal(value, &user.Password); err != nil {
+			return invalid
+		}
+	case "active":
+		active, err := parseSCIMBool(value)
+		if err != nil {
+			return invalid
+		}
+		user.Active = &active
+	case "emails":
+		if err := json.Unmarshal(value, &user.Emails); err != nil {
+			return invalid
+		}
+	case "emails.value":
+		var email string
+		if err := json.Unmarshal(value, &email); err != nil {
+			return invalid
+		}
+		user.Emails = []model.SCIMMultiValue{{Value: email, Type: "work", Primary: true}}
+	case "phonenumbers":
+		if err := json.Unmarshal(value, &user.PhoneNumbers); err != nil {
+			return invalid
+		}
+	case "phonenumbers.value":
+		var phone string
+		if err := json.Unmarshal(value, &phone); err != nil {
+			return invalid
+		}
+		user.PhoneNumbers = []model.SCIMMultiValue{{Value: phone, Type: "work", Primary: true}}
+	}
+	return nil
+}
+
+// removeSCIMValue clears the attribute at path. Required attributes cannot
+// be removed.
+func removeSCIMValue(user *model.SCIMUser, path string) error {
+	switch normalizeSCIMPath(path) {
+	case "phonenumbers", "phonenumbers.value":
+		user.PhoneNumbers = nil
+	case "", "username", "emails", "emails.value", "active", "password":
+		return &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMErrMutability, Key: "scim.immutable", Params: i18n.Params{"path": path}}
+	}
+	return nil
+}
+
+// parseSCIMBool reads a boolean sent either as JSON or as a string, as some
+// provisioning systems do
+func parseSCIMBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(strings.ToLower(s))
+}
+
+// logEvent records a provisioning action in the audit log. A nil err means
+// the action succeeded.
+func (s *scimServiceImpl) logEvent(ctx context.Context, eventType string, userID int, actor string, client model.SCIMClient, meta model.RequestMetadata, err error) {
+	event := model.AuditEvent{
+		EventType: eventType,
+		UserID:    userID,
+		Actor:     actor,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    "scim " + client.Name,
+	}
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Detail = err.Error()
+	}
+	s.auditLogger.LogEvent(ctx, event)
+}
@@ -0,0 +1,258 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var scimClient = model.SCIMClient{ID: 1, Name: "okta"}
+
+type scimFixture struct {
+	repo     *mocks.SCIMRepository
+	users    *mocks.UserRepository
+	sessions *mocks.SessionService
+	audit    *mocks.AuditLogger
+	service  services.SCIMService
+}
+
+func newSCIMFixture() *scimFixture {
+	f := &scimFixture{
+		repo:     new(mocks.SCIMRepository),
+		users:    new(mocks.UserRepository),
+		sessions: new(mocks.SessionService),
+		audit:    new(mocks.AuditLogger),
+	}
+	f.audit.On("LogEvent", mock.Anything, mock.AnythingOfType("model.AuditEvent")).Return()
+	f.service = services.NewSCIMService(f.repo, f.users, f.sessions, f.audit, "http://localhost")
+	return f
+}
+
+// scimErrorOf returns the SCIM error wrapped in err, or fails the test
+func scimErrorOf(t *testing.T, err error) *services.SCIMError {
+	t.Helper()
+	var scimErr *services.SCIMError
+	if !errors.As(err, &scimErr) {
+		t.Fatalf("expected a SCIM error, got %v", err)
+	}
+	return scimErr
+}
+
+func TestSCIMClientToken(t *testing.T) {
+	f := newSCIMFixture()
+	var stored model.SCIMClient
+	f.repo.On("CreateSCIMClient", mock.Anything, mock.AnythingOfType("model.SCIMClient")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(model.SCIMClient) }).
+		Return(4, nil)
+
+	client, token, err := f.service.CreateClient(context.Background(), model.SCIMClientCreateRequest{Name: " okta "})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, client.ID)
+	assert.Equal(t, "okta", client.Name)
+	assert.NotEmpty(t, token)
+	assert.NotEqual(t, token, stored.TokenHash)
+
+	stored.ID = 4
+	f.repo.On("GetSCIMClientByTokenHash", mock.Anything, stored.TokenHash).Return(&stored, nil)
+	f.repo.On("GetSCIMClientByTokenHash", mock.Anything, mock.AnythingOfType("string")).Return(nil, nil)
+
+	authenticated, err := f.service.AuthenticateClient(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, authenticated.ID)
+
+	_, err = f.service.AuthenticateClient(context.Background(), "wrong")
+	assert.ErrorIs(t, err, services.ErrInvalidSCIMToken)
+}
+
+func TestSCIMCreateUser(t *testing.T) {
+	req := model.SCIMUser{
+		Schemas:      []string{model.SCIMSchemaUser},
+		UserName:     "jdoe",
+		Emails:       []model.SCIMMultiValue{{Value: "personal@example.com"}, {Value: "jdoe@acme.com", Primary: true}},
+		PhoneNumbers: []model.SCIMMultiValue{{Value: "(55) 1234-5678"}},
+	}
+
+	t.Run("user is created from the primary values", func(t *testing.T) {
+		f := newSCIMFixture()
+		f.users.On("GetUserByEmailOrUsername", mock.Anything, "jdoe").Return(nil, nil).Once()
+		f.users.On("GetUserByEmailOrUsername", mock.Anything, "jdoe@acme.com").Return(nil, nil).Once()
+		f.users.On("GetUserByEmailOrPhone", mock.Anything, "", "5512345678").Return(nil, nil)
+		f.users.On("CreateUser", mock.Anything, model.User{Username: "jdoe", Email: "jdoe@acme.com", Phone: "5512345678", SCIMClientID: 1}).Return(nil)
+		f.users.On("GetUserByEmailOrUsername", mock.Anything, "jdoe@acme.com").Return(&model.User{ID: 9, Username: "jdoe", Email: "jdoe@acme.com", Phone: "5512345678"}, nil)
+
+		user, err := f.service.CreateUser(context.Background(), scimClient, req, model.RequestMetadata{})
+		assert.NoError(t, err)
+		assert.Equal(t, "9", user.ID)
+		assert.True(t, *user.Active)
+		assert.Equal(t, "http://localhost/scim/v2/Users/9", user.Meta.Location)
+		assert.Empty(t, user.Password)
+		f.audit.AssertCalled(t, "LogEvent", mock.Anything, mock.MatchedBy(func(e model.AuditEvent) bool {
+			return e.EventType == model.AuditEventRegistration && e.Outcome == model.AuditOutcomeSuccess && e.Detail == "scim okta"
+		}))
+	})
+
+	t.Run("taken email is a uniqueness error", func(t *testing.T) {
+		f := newSCIMFixture()
+		f.users.On("GetUserByEmailOrUsername", mock.Anything, "jdoe").Return(nil, nil)
+		f.users.On("GetUserByEmailOrUsername", mock.Anything, "jdoe@acme.com").Return(&model.User{ID: 3}, nil)
+
+		_, err := f.service.CreateUser(context.Background(), scimClient, req, model.RequestMetadata{})
+		scimErr := scimErrorOf(t, err)
+		assert.Equal(t, http.StatusConflict, scimErr.Status)
+		assert.Equal(t, services.SCIMErrUniqueness, scimErr.ScimType)
+		f.users.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		f := newSCIMFixture()
+		invalid := req
+		invalid.Emails = []model.SCIMMultiValue{{Value: "not-an-email"}}
+
+		_, err := f.service.CreateUser(context.Background(), scimClient, invalid, model.RequestMetadata{})
+		scimErr := scimErrorOf(t, err)
+		assert.Equal(t, http.StatusBadRequest, scimErr.Status)
+		assert.Equal(t, services.SCIMErrInvalidValue, scimErr.ScimType)
+	})
+}
+
+func TestSCIMListUsers(t *testing.T) {
+	users := []model.User{{ID: 1, Username: "a", Email: "a@acme.com"}, {ID: 2, Username: "b", Email: "b@acme.com"}}
+
+	t.Run("filter and paging are passed to the repository", func(t *testing.T) {
+		f := newSCIMFixture()
+		f.users.On("GetUsers", mock.Anything, model.UserFilter{Email: "a@acme.com", SCIMClientID: 1, Offset: 10, Limit: 200}).Return(users[:1], 11, nil)
+
+		list, err := f.service.ListUsers(context.Background(), scimClient, `emails.value eq "a@acme.com"`, 11, 500)
+		assert.NoError(t, err)
+		assert.Equal(t, 11, list.TotalResults)
+		assert.Equal(t, 11, list.StartIndex)
+		assert.Equal(t, 1, list.ItemsPerPage)
+		assert.Len(t, list.Resources, 1)
+	})
+
+	t.Run("zero count only returns the total", func(t *testing.T) {
+		f := newSCIMFixture()
+		f.users.On("GetUsers", mock.Anything, model.UserFilter{Username: "b", SCIMClientID: 1, Limit: 1}).Return(users[1:], 1, nil)
+
+		list, err := f.service.ListUsers(context.Background(), scimClient, `userName eq "b"`, 0, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, list.TotalResults)
+		assert.Equal(t, 1, list.StartIndex)
+		assert.Equal(t, []model.SCIMUser{}, list.Resources)
+	})
+
+	t.Run("unsupported filter", func(t *testing.T) {
+		f := newSCIMFixture()
+
+		_, err := f.service.ListUsers(context.Background(), scimClient, `name.givenName sw "J"`, 1, 100)
+		scimErr := scimErrorOf(t, err)
+		assert.Equal(t, services.SCIMErrInvalidFilter, scimErr.ScimType)
+	})
+}
+
+func TestSCIMPatchUser(t *testing.T) {
+	current := model.User{ID: 9, Username: "jdoe", Email: "jdoe@acme.com", Phone: "5512345678", Password: "hash", SCIMClientID: 1}
+	patch := func(operations ...model.SCIMPatchOperation) model.SCIMPatchRequest {
+		return model.SCIMPatchRequest{Schemas: []string{model.SCIMSchemaPatchOp}, Operations: operations}
+	}
+	expectUniqueness := func(f *scimFixture) {
+		f.users.On("GetUserByEmailOrUsername", mock.Anything, mock.AnythingOfType("string")).Return(&current, nil)
+		f.users.On("GetUserByEmailOrPhone", mock.Anything, "", mock.AnythingOfType("string")).Return(&current, nil)
+	}
+
+	t.Run("deactivation revokes the sessions", func(t *testing.T) {
+		f := newSCIMFixture()
+		f.users.On("GetUserByID", mock.Anything, 9).Return(&current, nil)
+		expectUniqueness(f)
+		disabled := current
+		disabled.Disabled = true
+		f.users.On("UpdateUser", mock.Anything, disabled).Return(nil)
+		f.sessions.On("RevokeAllSessions", mock.Anything, 9, "jdoe", model.RequestMetadata{}).Return(nil)
+
+		user, err := f.service.PatchUser(context.Background(), scimClient, "9", patch(model.SCIMPatchOperation{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)}), model.RequestMetadata{})
+		assert.NoError(t, err)
+		assert.False(t, *user.Active)
+		f.sessions.AssertExpectations(t)
+	})
+
+	t.Run("operation without path and removal of the phone", func(t *testing.T) {
+		f := newSCIMFixture()
+		f.users.On("GetUserByID", mock.Anything, 9).Return(&current, nil)
+		expectUniqueness(f)
+		f.users.On("UpdateUser", mock.Anything, model.User{ID: 9, Username: "john", Email: "jdoe@acme.com", Password: "hash", SCIMClientID: 1}).Return(nil)
+
+		user, err := f.service.PatchUser(context.Background(), scimClient, "9", patch(
+			model.SCIMPatchOperation{Op: "replace", Value: json.RawMessage(`{"userName":"john","name.givenName":"John"}`)},
+			model.SCIMPatchOperation{Op: "remove", Path: "phoneNumbers"},
+		), model.RequestMetadata{})
+		assert.NoError(t, err)
+		assert.Equal(t, "john", user.UserName)
+		assert.Empty(t, user.PhoneNumbers)
+		f.sessions.AssertNotCalled(t, "RevokeAllSessions", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		f.audit.AssertNotCalled(t, "LogEvent", mock.Anything, mock.MatchedBy(func(e model.AuditEvent) bool {
+			return e.EventType == model.AuditEventPasswordChange
+		}))
+	})
+
+	t.Run("new password is audited", func(t *testing.T) {
+		f := newSCIMFixture()
+		f.users.On("GetUserByID", mock.Anything, 9).Return(&current, nil)
+		expectUniqueness(f)
+		f.users.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u model.User) bool {
+			return u.ID == 9 && u.Password != "hash" && u.Password != "Secreta123$"
+		})).Return(nil)
+
+		_, err := f.service.PatchUser(context.Background(), scimClient, "9", patch(model.SCIMPatchOperation{Op: "replace", Path: "password", Value: json.RawMessage(`"Secreta123$"`)}), model.RequestMetadata{})
+		assert.NoError(t, err)
+		f.audit.AssertCalled(t, "LogEvent", mock.Anything, mock.MatchedBy(func(e model.AuditEvent) bool {
+			return e.EventType == model.AuditEventPasswordChange && e.UserID == 9 && e.Outcome == model.AuditOutcomeSuccess
+		}))
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		f := newSCIMFixture()
+		f.users.On("GetUserByID", mock.Anything, 10).Return(nil, nil)
+
+		_, err := f.service.PatchUser(context.Background(), scimClient, "10", patch(model.SCIMPatchOperation{Op: "replace", Path: "active", Value: json.RawMessage(`false`)}), model.RequestMetadata{})
+		assert.Equal(t, http.StatusNotFound, scimErrorOf(t, err).Status)
+	})
+
+	t.Run("users of other clients are not found", func(t *testing.T) {
+		f := newSCIMFixture()
+		admin := model.User{ID: 1, Username: "admin", Email: "admin@acme.com", Password: "hash"}
+		other := current
+		other.SCIMClientID = 2
+		f.users.On("GetUserByID", mock.Anything, 1).Return(&admin, nil)
+		f.users.On("GetUserByID", mock.Anything, 9).Return(&other, nil)
+
+		_, err := f.service.PatchUser(context.Background(), scimClient, "1", patch(model.SCIMPatchOperation{Op: "replace", Path: "password", Value: json.RawMessage(`"Secreta123$"`)}), model.RequestMetadata{})
+		assert.Equal(t, http.StatusNotFound, scimErrorOf(t, err).Status)
+
+		_, err = f.service.GetUser(context.Background(), scimClient, "9")
+		assert.Equal(t, http.StatusNotFound, scimErrorOf(t, err).Status)
+		f.users.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSCIMDeleteUser(t *testing.T) {
+	f := newSCIMFixture()
+	f.users.On("GetUserByID", mock.Anything, 9).Return(&model.User{ID: 9, Username: "jdoe", SCIMClientID: 1}, nil)
+	f.users.On("DeleteUser", mock.Anything, 9).Return(nil)
+
+	err := f.service.DeleteUser(context.Background(), scimClient, "9", model.RequestMetadata{})
+	assert.NoError(t, err)
+	f.audit.AssertCalled(t, "LogEvent", mock.Anything, mock.MatchedBy(func(e model.AuditEvent) bool {
+		return e.EventType == model.AuditEventUserDeletion && e.UserID == 9
+	}))
+
+	err = f.service.DeleteUser(context.Background(), scimClient, "not-a-number", model.RequestMetadata{})
+	assert.Equal(t, http.StatusNotFound, scimErrorOf(t, err).Status)
+}
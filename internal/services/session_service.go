@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"exercise-login-back-go/internal/model"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// sessionHistoryLimit is the number of sessions returned to a user.
+	sessionHistoryLimit = 50
+	// lastSeenResolution avoids writing to the database on every request.
+	lastSeenResolution = time.Minute
+)
+
+var ErrSessionNotFound = newError(KindNotFound, "session_not_found", nil)
+
+type SessionService interface {
+	CreateSession(ctx context.Context, user model.User, meta model.RequestMetadata, expiresAt time.Time) (*model.Session, error)
+	ValidateSession(ctx context.Context, sessionID string, userID int) error
+	GetSessions(ctx context.Context, userID int) ([]model.Session, error)
+	RevokeSession(ctx context.Context, userID int, username, sessionID string, meta model.RequestMetadata) error
+	RevokeAllSessions(ctx context.Context, userID int, username string, meta model.RequestMetadata) error
+}
+
+type sessionServiceImpl struct {
+	repo        model.SessionRepository
+	auditLogger AuditLogger
+}
+
+func NewSessionService(repo model.SessionRepository, auditLogger AuditLogger) *sessionServiceImpl {
+	return &sessionServiceImpl{
+		repo:        repo,
+		auditLogger: auditLogger,
+	}
+}
+
+// CreateSession records a new session for a token issued to the user.
+func (s *sessionServiceImpl) CreateSession(ctx context.Context, user model.User, meta model.RequestMetadata, expiresAt time.Time) (*model.Session, error) {
+	id, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := model.Session{
+		ID:         id,
+		UserID:     user.ID,
+		Device:     describeDevice(meta.UserAgent),
+		IPAddress:  meta.IPAddress,
+		UserAgent:  meta.UserAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt.UTC(),
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ValidateSession verifies that the session belongs to the user and has not
+// been revoked or expired, and refreshes its last-seen time.
+func (s *sessionServiceImpl) ValidateSession(ctx context.Context, sessionID string, userID int) error {
+	session, err := s.repo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if session == nil || session.UserID != userID || !session.IsActive(now) {
+		return ErrSessionNotFound
+	}
+
+	if now.Sub(session.LastSeenAt) >= lastSeenResolution {
+		if err := s.repo.UpdateSessionLastSeen(ctx, session.ID, now); err != nil {
+			slog.WarnContext(ctx, "Failed to update last seen time of session", "sessionId", session.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// GetSessions returns the most recent sessions of the user, newest first.
+func (s *sessionServiceImpl) GetSessions(ctx context.Context, userID int) ([]model.Session, error) {
+	return s.repo.GetSessionsByUserID(ctx, userID, sessionHistoryLimit)
+}
+
+// RevokeSession revokes one of the user's sessions, invalidating its token.
+func (s *sessionServiceImpl) RevokeSession(ctx context.Context, userID int, username, sessionID string, meta model.RequestMetadata) error {
+	session, err := s.repo.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	event := model.AuditEvent{
+		EventType: model.AuditEventTokenRevocation,
+		UserID:    userID,
+		Actor:     username,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    "session " + session.ID,
+	}
+	if session.RevokedAt == nil {
+		if err := s.repo.RevokeSession(ctx, session.ID, time.Now().UTC()); err != nil {
+			event.Outcome = model.AuditOutcomeFailure
+			event.Detail = err.Error()
+			s.auditLogger.LogEvent(ctx, event)
+			return err
+		}
+	}
+	s.auditLogger.LogEvent(ctx, event)
+	return nil
+}
+
+// RevokeAllSessions revokes every active session of the user.
+func (s *sessionServiceImpl) RevokeAllSessions(ctx context.Context, userID int, username string, meta model.RequestMetadata) error {
+	event := model.AuditEvent{
+		EventType: model.AuditEventTokenRevocation,
+		UserID:    userID,
+		Actor:     username,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    "all sessions",
+	}
+	if err := s.repo.RevokeUserSessions(ctx, userID, time.Now().UTC()); err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Detail = err.Error()
+		s.auditLogger.LogEvent(ctx, event)
+		return err
+	}
+	s.auditLogger.LogEvent(ctx, event)
+	return nil
+}
+
+// randomToken returns a hex encoded random string built from n random bytes
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// describeDevice builds a short, human readable description of the device
+// from its user agent, e.g. "Chrome (Windows)".
+func describeDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Unknown"
+	}
+
+	browser := "Other"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	}
+
+	platform := "Other"
+	switch {
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		platform = "iOS"
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "mac os x") || strings.Contains(ua, "macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	}
+
+	return browser + " (" + platform + ")"
+}
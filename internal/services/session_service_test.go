@@ -0,0 +1,115 @@
+package services_test
+
+import (
+	"context"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateSession(t *testing.T) {
+	mockRepo := new(mocks.SessionRepository)
+	service := services.NewSessionService(mockRepo, new(mocks.AuditLogger))
+
+	mockRepo.On("CreateSession", mock.Anything, mock.MatchedBy(func(session model.Session) bool {
+		return session.UserID == 7 && session.ID != "" && session.Device == "Firefox (Linux)"
+	})).Return(nil)
+
+	meta := model.RequestMetadata{IPAddress: "10.0.0.1", UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0"}
+	session, err := service.CreateSession(context.Background(), model.User{ID: 7}, meta, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, session.ID, 32)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateSessionWithoutUserAgent(t *testing.T) {
+	mockRepo := new(mocks.SessionRepository)
+	service := services.NewSessionService(mockRepo, new(mocks.AuditLogger))
+
+	mockRepo.On("CreateSession", mock.Anything, mock.MatchedBy(func(session model.Session) bool {
+		return session.Device == "Unknown"
+	})).Return(nil)
+
+	_, err := service.CreateSession(context.Background(), model.User{ID: 7}, model.RequestMetadata{IPAddress: "10.0.0.1"}, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestValidateSession(t *testing.T) {
+	now := time.Now().UTC()
+	revokedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		session *model.Session
+		wantErr bool
+	}{
+		{"active", &model.Session{ID: "s1", UserID: 7, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)}, false},
+		{"missing", nil, true},
+		{"other user", &model.Session{ID: "s1", UserID: 8, LastSeenAt: now, ExpiresAt: now.Add(time.Hour)}, true},
+		{"expired", &model.Session{ID: "s1", UserID: 7, LastSeenAt: now, ExpiresAt: now.Add(-time.Second)}, true},
+		{"revoked", &model.Session{ID: "s1", UserID: 7, LastSeenAt: now, ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.SessionRepository)
+			service := services.NewSessionService(mockRepo, new(mocks.AuditLogger))
+			mockRepo.On("GetSessionByID", mock.Anything, "s1").Return(tt.session, nil)
+
+			err := service.ValidateSession(context.Background(), "s1", 7)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, services.ErrSessionNotFound)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertNotCalled(t, "UpdateSessionLastSeen", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("refreshes last seen", func(t *testing.T) {
+		mockRepo := new(mocks.SessionRepository)
+		service := services.NewSessionService(mockRepo, new(mocks.AuditLogger))
+		mockRepo.On("GetSessionByID", mock.Anything, "s1").Return(&model.Session{ID: "s1", UserID: 7, LastSeenAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}, nil)
+		mockRepo.On("UpdateSessionLastSeen", mock.Anything, "s1", mock.AnythingOfType("time.Time")).Return(nil)
+
+		assert.NoError(t, service.ValidateSession(context.Background(), "s1", 7))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	meta := model.RequestMetadata{IPAddress: "10.0.0.1"}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.SessionRepository)
+		mockAudit := new(mocks.AuditLogger)
+		service := services.NewSessionService(mockRepo, mockAudit)
+
+		mockRepo.On("GetSessionByID", mock.Anything, "s1").Return(&model.Session{ID: "s1", UserID: 7}, nil)
+		mockRepo.On("RevokeSession", mock.Anything, "s1", mock.AnythingOfType("time.Time")).Return(nil)
+		mockAudit.On("LogEvent", mock.Anything, mock.MatchedBy(func(event model.AuditEvent) bool {
+			return event.EventType == model.AuditEventTokenRevocation && event.Outcome == model.AuditOutcomeSuccess
+		})).Return()
+
+		assert.NoError(t, service.RevokeSession(context.Background(), 7, "testuser", "s1", meta))
+		mockRepo.AssertExpectations(t)
+		mockAudit.AssertExpectations(t)
+	})
+
+	t.Run("session of another user", func(t *testing.T) {
+		mockRepo := new(mocks.SessionRepository)
+		service := services.NewSessionService(mockRepo, new(mocks.AuditLogger))
+
+		mockRepo.On("GetSessionByID", mock.Anything, "s1").Return(&model.Session{ID: "s1", UserID: 8}, nil)
+
+		err := service.RevokeSession(context.Background(), 7, "testuser", "s1", meta)
+		assert.ErrorIs(t, err, services.ErrSessionNotFound)
+		mockRepo.AssertNotCalled(t, "RevokeSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
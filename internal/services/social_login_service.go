@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"exercise-login-back-go/internal/model"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// socialLoginStateLifetime is how long the user has to complete the sign
+	// in with the upstream provider.
+	socialLoginStateLifetime = 10 * time.Minute
+	// usernameAttempts is how many numbered variants of a username are tried
+	// before falling back to a random suffix.
+	usernameAttempts = 20
+)
+
+var (
+	ErrUnknownProvider       = newError(KindNotFound, "unknown_provider", nil)
+	ErrInvalidSocialState    = newError(KindValidation, "invalid_state", nil)
+	ErrSocialLoginFailed     = newError(KindUpstream, "provider_error", nil)
+	ErrSocialEmailRequired   = newError(KindValidation, "email_required", nil)
+	ErrSocialEmailConflict   = newError(KindConflict, "email_conflict", nil)
+	ErrIdentityAlreadyLinked = newError(KindConflict, "identity_already_linked", nil)
+	ErrProviderAlreadyLinked = newError(KindConflict, "provider_already_linked", nil)
+	ErrIdentityNotFound      = newError(KindNotFound, "identity_not_found", nil)
+	ErrLastLoginMethod       = newError(KindConflict, "last_login_method", nil)
+)
+
+// usernameDisallowed matches the characters removed from upstream usernames.
+var usernameDisallowed = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+type SocialLoginService interface {
+	Providers() []string
+	StartLogin(ctx context.Context, provider string) (*model.SocialLoginStart, error)
+	StartLink(ctx context.Context, userID int, provider string) (*model.SocialLoginStart, error)
+	Callback(ctx context.Context, provider, state, browserState, code string, meta model.RequestMetadata) (*model.SocialLoginResult, error)
+	GetIdentities(ctx context.Context, userID int) ([]model.UserIdentity, error)
+	Unlink(ctx context.Context, userID int, username, provider string, meta model.RequestMetadata) error
+}
+
+type socialLoginServiceImpl struct {
+	repo        model.IdentityRepository
+	userRepo    model.UserRepository
+	userService UserService
+	auditLogger AuditLogger
+	providers   map[string]model.IdentityProvider
+	names       []string
+}
+
+func NewSocialLoginService(repo model.IdentityRepository, userRepo model.UserRepository, userService UserService, auditLogger AuditLogger, providers []model.IdentityProvider) *socialLoginServiceImpl {
+	service := &socialLoginServiceImpl{
+		repo:        repo,
+		userRepo:    userRepo,
+		userService: userService,
+		auditLogger: auditLogger,
+		providers:   map[string]model.IdentityProvider{},
+		names:       []string{},
+	}
+	for _, provider := range providers {
+		service.providers[provider.Name()] = provider
+		service.names = append(service.names, provider.Name())
+	}
+	return service
+}
+
+// Providers returns the names of the configured upstream providers.
+func (s *socialLoginServiceImpl) Providers() []string {
+	return s.names
+}
+
+// StartLogin begins a sign in with an upstream provider and returns the URL
+// the user must be sent to.
+func (s *socialLoginServiceImpl) StartLogin(ctx context.Context, provider string) (*model.SocialLoginStart, error) {
+	return s.start(ctx, provider, 0)
+}
+
+// StartLink begins linking an upstream account to the user and returns the
+// URL the user must be sent to.
+func (s *socialLoginServiceImpl) StartLink(ctx context.Context, userID int, provider string) (*model.SocialLoginStart, error) {
+	return s.start(ctx, provider, userID)
+}
+
+// start stores a pending sign in and builds the upstream authorization URL
+func (s *socialLoginServiceImpl) start(ctx context.Context, providerName string, userID int) (*model.SocialLoginStart, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	state, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = s.repo.CreateSocialLoginState(ctx, model.SocialLoginState{
+		StateHash:    hashSecret(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		UserID:       userID,
+		ExpiresAt:    now.Add(socialLoginStateLifetime),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(codeVerifier))
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(sum[:]))
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to build the authorization URL", "provider", providerName, "error", err)
+		return nil, ErrSocialLoginFailed
+	}
+	return &model.SocialLoginStart{
+		AuthorizationURL: authURL,
+		State:            state,
+		ExpiresAt:        now.Add(socialLoginStateLifetime),
+	}, nil
+}
+
+// Callback completes a sign in started by StartLogin or StartLink once the
+// upstream provider redirected the user back with an authorization code.
+// browserState is the state the browser kept when the sign in started; it
+// must match the state sent by the provider, so that an attacker cannot make
+// a victim complete a sign in the attacker started.
+func (s *socialLoginServiceImpl) Callback(ctx context.Context, providerName, state, browserState, code string, meta model.RequestMetadata) (*model.SocialLoginResult, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	if state == "" || code == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, ErrInvalidSocialState
+	}
+
+	pending, err := s.repo.ConsumeSocialLoginState(ctx, hashSecret(state))
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil || pending.Provider != providerName || !time.Now().UTC().Before(pending.ExpiresAt) {
+		return nil, ErrInvalidSocialState
+	}
+
+	identity, err := provider.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		slog.WarnContext(ctx, "Social sign in failed", "provider", providerName, "error", err)
+		return nil, ErrSocialLoginFailed
+	}
+
+	if pending.UserID != 0 {
+		if err := s.link(ctx, pending.UserID, *identity, meta); err != nil {
+			return nil, err
+		}
+		return &model.SocialLoginResult{LinkedProvider: providerName}, nil
+	}
+	token, err := s.login(ctx, *identity, meta)
+	if err != nil {
+		return nil, err
+	}
+	return &model.SocialLoginResult{Token: token}, nil
+}
+
+// login signs in the user linked to an upstream identity, creating a new user
+// the first time the identity is seen. An existing user with the same email is
+// never linked automatically: the user must link the provider from their
+// account, proving they own both.
+func (s *socialLoginServiceImpl) login(ctx context.Context, identity model.ExternalIdentity, meta model.RequestMetadata) (string, error) {
+	actor := identity.Provider + ":" + identity.Subject
+
+	link, err := s.repo.GetUserIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return "", err
+	}
+	if link != nil {
+		user, err := s.userRepo.GetUserByID(ctx, link.UserID)
+		if err != nil {
+			return "", err
+		}
+		if user == nil {
+			return "", ErrSocialLoginFailed
+		}
+		return s.userService.LoginExternalUser(ctx, *user, meta)
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		s.logEvent(ctx, model.AuditEventLogin, 0, actor, "social "+identity.Provider, meta, ErrSocialEmailRequired)
+		return "", ErrSocialEmailRequired
+	}
+	existing, err := s.userRepo.GetUserByEmail(ctx, identity.Email)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		s.logEvent(ctx, model.AuditEventLogin, existing.ID, actor, "social "+identity.Provider, meta, ErrSocialEmailConflict)
+		return "", ErrSocialEmailConflict
+	}
+
+	user, err := s.createUser(ctx, identity)
+	if err != nil {
+		s.logEvent(ctx, model.AuditEventRegistration, 0, actor, "social "+identity.Provider, meta, err)
+		return "", err
+	}
+	s.logEvent(ctx, model.AuditEventRegistration, user.ID, user.Username, "social "+identity.Provider, meta, nil)
+
+	return s.userService.LoginExternalUser(ctx, *user, meta)
+}
+
+// createUser registers a local user for an upstream identity. The user has no
+// password and can only sign in through the linked providers.
+func (s *socialLoginServiceImpl) createUser(ctx context.Context, identity model.ExternalIdentity) (*model.User, error) {
+	username, err := availableUsername(ctx, s.userRepo, identity.PreferredUsername, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.CreateUser(ctx, model.User{Username: username, Email: identity.Email}); err != nil {
+		slog.ErrorContext(ctx, "Failed to create the user", "error", err)
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+	user, err := s.userRepo.GetUserByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errCreatedUserMissing
+	}
+
+	err = s.repo.CreateUserIdentity(ctx, model.UserIdentity{
+		UserID:    user.ID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// availableUsername derives an unused username from the preferred one, or
+// from the local part of the email when there is none.
+func availableUsername(ctx context.Context, userRepo model.UserRepository, preferred, email string) (string, error) {
+	base := preferred
+	if base == "" {
+		base, _, _ = strings.Cut(email, "@")
+	}
+	base = usernameDisallowed.ReplaceAllString(base, "")
+	if base == "" {
+		base = "user"
+	}
+
+	for attempt := 1; attempt <= usernameAttempts; attempt++ {
+		candidate := base
+		if attempt > 1 {
+			candidate = fmt.Sprintf("%s%d", base, attempt)
+		}
+		existing, err := userRepo.GetUserByEmailOrUsername(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+	}
+
+	suffix, err := randomToken(4)
+	if err != nil {
+		return "", err
+	}
+	return base + "_" + suffix, nil
+}
+
+// link attaches an upstream identity to an existing user
+func (s *socialLoginServiceImpl) link(ctx context.Context, userID int, identity model.ExternalIdentity, meta model.RequestMetadata) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidSocialState
+	}
+
+	existing, err := s.repo.GetUserIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if existing.UserID == userID {
+			return nil
+		}
+		s.logEvent(ctx, model.AuditEventIdentityLink, userID, user.Username, "social "+identity.Provider, meta, ErrIdentityAlreadyLinked)
+		return ErrIdentityAlreadyLinked
+	}
+
+	identities, err := s.repo.GetUserIdentitiesByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, linked := range identities {
+		if linked.Provider == identity.Provider {
+			s.logEvent(ctx, model.AuditEventIdentityLink, userID, user.Username, "social "+identity.Provider, meta, ErrProviderAlreadyLinked)
+			return ErrProviderAlreadyLinked
+		}
+	}
+
+	err = s.repo.CreateUserIdentity(ctx, model.UserIdentity{
+		UserID:    userID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: time.Now().UTC(),
+	})
+	s.logEvent(ctx, model.AuditEventIdentityLink, userID, user.Username, "social "+identity.Provider, meta, err)
+	return err
+}
+
+// GetIdentities returns the upstream accounts linked to the user.
+func (s *socialLoginServiceImpl) GetIdentities(ctx context.Context, userID int) ([]model.UserIdentity, error) {
+	return s.repo.GetUserIdentitiesByUserID(ctx, userID)
+}
+
+// Unlink removes the link between the user and a provider. Users without a
+// password must keep at least one linked provider to be able to sign in.
+func (s *socialLoginServiceImpl) Unlink(ctx context.Context, userID int, username, provider string, meta model.RequestMetadata) error {
+	identities, err := s.repo.GetUserIdentitiesByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			found = true
+		}
+	}
+	if !found {
+		return ErrIdentityNotFound
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrIdentityNotFound
+	}
+	if user.Password == "" && len(identities) == 1 {
+		s.logEvent(ctx, model.AuditEventIdentityUnlink, userID, username, "social "+provider, meta, ErrLastLoginMethod)
+		return ErrLastLoginMethod
+	}
+
+	err = s.repo.DeleteUserIdentity(ctx, userID, provider)
+	s.logEvent(ctx, model.AuditEventIdentityUnlink, userID, username, "social "+provider, meta, err)
+	return err
+}
+
+// logEvent records the outcome of a social login action in the audit log.
+// A nil err means the action succeeded.
+func (s *socialLoginServiceImpl) logEvent(ctx context.Context, eventType string, userID int, actor, detail string, meta model.RequestMetadata, err error) {
+	event := model.AuditEvent{
+		EventType: eventType,
+		UserID:    userID,
+		Actor:     actor,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Outcome:   model.AuditOutcomeSuccess,
+		Detail:    detail,
+	}
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Detail = err.Error()
+	}
+	s.auditLogger.LogEvent(ctx, event)
+}
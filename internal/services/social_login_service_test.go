@@ -0,0 +1,264 @@
+package services_test
+
+import (
+	"context"
+	"exercise-login-back-go/internal/connectors/oidctest"
+	"exercise-login-back-go/internal/mocks"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/repositories"
+	"exercise-login-back-go/internal/services"
+	"net/url"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// socialLoginFixture wires the social login service to a stub upstream provider
+type socialLoginFixture struct {
+	idp         *oidctest.IdP
+	repo        *mocks.IdentityRepository
+	userRepo    *mocks.UserRepository
+	userService *mocks.UserService
+	service     services.SocialLoginService
+}
+
+func newSocialLoginFixture(t *testing.T) *socialLoginFixture {
+	idp := oidctest.NewIdP(t)
+	f := &socialLoginFixture{
+		idp:         idp,
+		repo:        new(mocks.IdentityRepository),
+		userRepo:    new(mocks.UserRepository),
+		userService: new(mocks.UserService),
+	}
+	auditLogger := new(mocks.AuditLogger)
+	auditLogger.On("LogEvent", mock.Anything, mock.AnythingOfType("model.AuditEvent")).Return()
+	f.service = services.NewSocialLoginService(f.repo, f.userRepo, f.userService, auditLogger, []model.IdentityProvider{idp.Connector("stub")})
+	return f
+}
+
+// start begins a sign in, makes the stub provider answer with the given
+// claims and returns the state to send to the callback.
+func (f *socialLoginFixture) start(t *testing.T, userID int, claims jwt.MapClaims) string {
+	var pending model.SocialLoginState
+	f.repo.On("CreateSocialLoginState", mock.Anything, mock.AnythingOfType("model.SocialLoginState")).
+		Run(func(args mock.Arguments) { pending = args.Get(1).(model.SocialLoginState) }).
+		Return(nil).Once()
+
+	var start *model.SocialLoginStart
+	var err error
+	if userID == 0 {
+		start, err = f.service.StartLogin(context.Background(), "stub")
+	} else {
+		start, err = f.service.StartLink(context.Background(), userID, "stub")
+	}
+	assert.NoError(t, err)
+	parsed, _ := url.Parse(start.AuthorizationURL)
+	query := parsed.Query()
+	assert.Equal(t, start.State, query.Get("state"))
+	assert.Equal(t, pending.Nonce, query.Get("nonce"))
+	assert.Equal(t, userID, pending.UserID)
+
+	claims["nonce"] = query.Get("nonce")
+	f.idp.Claims = claims
+	f.repo.On("ConsumeSocialLoginState", mock.Anything, mock.AnythingOfType("string")).Return(&pending, nil).Once()
+	return query.Get("state")
+}
+
+func TestSocialLoginCreatesUser(t *testing.T) {
+	f := newSocialLoginFixture(t)
+	state := f.start(t, 0, jwt.MapClaims{"email": "ana@example.com", "email_verified": true, "preferred_username": "ana"})
+	created := &model.User{ID: 9, Username: "ana", Email: "ana@example.com"}
+
+	f.repo.On("GetUserIdentity", mock.Anything, "stub", "upstream-42").Return(nil, nil)
+	f.userRepo.On("GetUserByEmail", mock.Anything, "ana@example.com").Return(nil, nil).Once()
+	f.userRepo.On("GetUserByEmailOrUsername", mock.Anything, "ana").Return(&model.User{ID: 3, Username: "ana", Email: "other@example.com"}, nil)
+	f.userRepo.On("GetUserByEmailOrUsername", mock.Anything, "ana2").Return(nil, nil)
+	f.userRepo.On("CreateUser", mock.Anything, model.User{Username: "ana2", Email: "ana@example.com"}).Return(nil)
+	f.userRepo.On("GetUserByEmail", mock.Anything, "ana@example.com").Return(created, nil).Once()
+	f.repo.On("CreateUserIdentity", mock.Anything, mock.MatchedBy(func(identity model.UserIdentity) bool {
+		return identity.UserID == 9 && identity.Provider == "stub" && identity.Subject == "upstream-42"
+	})).Return(nil)
+	f.userService.On("LoginExternalUser", mock.Anything, *created, mock.AnythingOfType("model.RequestMetadata")).Return("jwt", nil)
+
+	result, err := f.service.Callback(context.Background(), "stub", state, state, oidctest.Code, model.RequestMetadata{})
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt", result.Token)
+	assert.NotEmpty(t, f.idp.CodeVerifier())
+	f.userRepo.AssertExpectations(t)
+	f.repo.AssertExpectations(t)
+}
+
+// A username equal to the upstream email must not take over the identity.
+func TestSocialLoginIgnoresUsernameMatchingEmail(t *testing.T) {
+	f := newSocialLoginFixture(t)
+	users := repositories.NewMemoryUserRepository()
+	assert.NoError(t, users.CreateUser(context.Background(), model.User{Username: "ana@example.com", Email: "mallory@example.com"}))
+	auditLogger := new(mocks.AuditLogger)
+	auditLogger.On("LogEvent", mock.Anything, mock.AnythingOfType("model.AuditEvent")).Return()
+	f.service = services.NewSocialLoginService(f.repo, users, f.userService, auditLogger, []model.IdentityProvider{f.idp.Connector("stub")})
+	state := f.start(t, 0, jwt.MapClaims{"email": "ana@example.com", "email_verified": true, "preferred_username": "ana"})
+
+	var linked model.UserIdentity
+	f.repo.On("GetUserIdentity", mock.Anything, "stub", "upstream-42").Return(nil, nil)
+	f.repo.On("CreateUserIdentity", mock.Anything, mock.AnythingOfType("model.UserIdentity")).
+		Run(func(args mock.Arguments) { linked = args.Get(1).(model.UserIdentity) }).
+		Return(nil)
+	f.userService.On("LoginExternalUser", mock.Anything, mock.AnythingOfType("model.User"), mock.AnythingOfType("model.RequestMetadata")).Return("jwt", nil)
+
+	_, err := f.service.Callback(context.Background(), "stub", state, state, oidctest.Code, model.RequestMetadata{})
+	assert.NoError(t, err)
+	created, _ := users.GetUserByEmail(context.Background(), "ana@example.com")
+	if assert.NotNil(t, created) {
+		assert.Equal(t, "ana", created.Username)
+		assert.Equal(t, created.ID, linked.UserID)
+	}
+}
+
+func TestSocialLoginLinkedUser(t *testing.T) {
+	f := newSocialLoginFixture(t)
+	state := f.start(t, 0, jwt.MapClaims{"email": "ana@example.com", "email_verified": true})
+	user := &model.User{ID: 9, Username: "ana", Email: "ana@example.com"}
+
+	f.repo.On("GetUserIdentity", mock.Anything, "stub", "upstream-42").Return(&model.UserIdentity{UserID: 9, Provider: "stub", Subject: "upstream-42"}, nil)
+	f.userRepo.On("GetUserByID", mock.Anything, 9).Return(user, nil)
+	f.userService.On("LoginExternalUser", mock.Anything, *user, mock.AnythingOfType("model.RequestMetadata")).Return("jwt", nil)
+
+	result, err := f.service.Callback(context.Background(), "stub", state, state, oidctest.Code, model.RequestMetadata{})
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt", result.Token)
+}
+
+func TestSocialLoginEmailConflict(t *testing.T) {
+	f := newSocialLoginFixture(t)
+	state := f.start(t, 0, jwt.MapClaims{"email": "Ana@Example.com", "email_verified": true})
+
+	f.repo.On("GetUserIdentity", mock.Anything, "stub", "upstream-42").Return(nil, nil)
+	f.userRepo.On("GetUserByEmail", mock.Anything, "Ana@Example.com").Return(&model.User{ID: 3, Username: "ana", Email: "ana@example.com"}, nil)
+
+	_, err := f.service.Callback(context.Background(), "stub", state, state, oidctest.Code, model.RequestMetadata{})
+	assert.ErrorIs(t, err, services.ErrSocialEmailConflict)
+	f.userRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	f.userService.AssertNotCalled(t, "LoginExternalUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSocialLoginUnverifiedEmail(t *testing.T) {
+	f := newSocialLoginFixture(t)
+	state := f.start(t, 0, jwt.MapClaims{"email": "ana@example.com", "email_verified": false})
+
+	f.repo.On("GetUserIdentity", mock.Anything, "stub", "upstream-42").Return(nil, nil)
+
+	_, err := f.service.Callback(context.Background(), "stub", state, state, oidctest.Code, model.RequestMetadata{})
+	assert.ErrorIs(t, err, services.ErrSocialEmailRequired)
+}
+
+func TestSocialLoginInvalidState(t *testing.T) {
+	f := newSocialLoginFixture(t)
+	f.repo.On("ConsumeSocialLoginState", mock.Anything, mock.AnythingOfType("string")).Return(nil, nil)
+
+	_, err := f.service.Callback(context.Background(), "stub", "forged", "forged", oidctest.Code, model.RequestMetadata{})
+	assert.ErrorIs(t, err, services.ErrInvalidSocialState)
+
+	_, err = f.service.Callback(context.Background(), "unknown", "forged", "forged", oidctest.Code, model.RequestMetadata{})
+	assert.ErrorIs(t, err, services.ErrUnknownProvider)
+}
+
+func TestSocialLoginStateFromAnotherBrowser(t *testing.T) {
+	f := newSocialLoginFixture(t)
+	state := f.start(t, 0, jwt.MapClaims{"email": "ana@example.com", "email_verified": true})
+
+	_, err := f.service.Callback(context.Background(), "stub", state, "", oidctest.Code, model.RequestMetadata{})
+	assert.ErrorIs(t, err, services.ErrInvalidSocialState)
+
+	_, err = f.service.Callback(context.Background(), "stub", state, "other", oidctest.Code, model.RequestMetadata{})
+	assert.ErrorIs(t, err, services.ErrInvalidSocialState)
+	f.repo.AssertNotCalled(t, "ConsumeSocialLoginState", mock.Anything, mock.Anything)
+}
+
+func TestSocialLoginUpstreamFailure(t *testing.T) {
+	f := newSocialLoginFixture(t)
+	state := f.start(t, 0, jwt.MapClaims{})
+	// A different nonce makes the ID token fail validation.
+	f.idp.Claims["nonce"] = "replayed"
+
+	_, err := f.service.Callback(context.Background(), "stub", state, state, oidctest.Code, model.RequestMetadata{})
+	assert.ErrorIs(t, err, services.ErrSocialLoginFailed)
+}
+
+func TestLinkIdentity(t *testing.T) {
+	user := &model.User{ID: 5, Username: "luis", Email: "luis@example.com", Password: "hash"}
+
+	t.Run("links the upstream account", func(t *testing.T) {
+		f := newSocialLoginFixture(t)
+		state := f.start(t, 5, jwt.MapClaims{"email": "luis@gmail.com"})
+
+		f.userRepo.On("GetUserByID", mock.Anything, 5).Return(user, nil)
+		f.repo.On("GetUserIdentity", mock.Anything, "stub", "upstream-42").Return(nil, nil)
+		f.repo.On("GetUserIdentitiesByUserID", mock.Anything, 5).Return([]model.UserIdentity{}, nil)
+		f.repo.On("CreateUserIdentity", mock.Anything, mock.MatchedBy(func(identity model.UserIdentity) bool {
+			return identity.UserID == 5 && identity.Email == "luis@gmail.com"
+		})).Return(nil)
+
+		result, err := f.service.Callback(context.Background(), "stub", state, state, oidctest.Code, model.RequestMetadata{})
+		assert.NoError(t, err)
+		assert.Equal(t, "stub", result.LinkedProvider)
+		assert.Empty(t, result.Token)
+	})
+
+	t.Run("upstream account linked to another user", func(t *testing.T) {
+		f := newSocialLoginFixture(t)
+		state := f.start(t, 5, jwt.MapClaims{})
+
+		f.userRepo.On("GetUserByID", mock.Anything, 5).Return(user, nil)
+		f.repo.On("GetUserIdentity", mock.Anything, "stub", "upstream-42").Return(&model.UserIdentity{UserID: 8}, nil)
+
+		_, err := f.service.Callback(context.Background(), "stub", state, state, oidctest.Code, model.RequestMetadata{})
+		assert.ErrorIs(t, err, services.ErrIdentityAlreadyLinked)
+	})
+
+	t.Run("provider already linked with another account", func(t *testing.T) {
+		f := newSocialLoginFixture(t)
+		state := f.start(t, 5, jwt.MapClaims{})
+
+		f.userRepo.On("GetUserByID", mock.Anything, 5).Return(user, nil)
+		f.repo.On("GetUserIdentity", mock.Anything, "stub", "upstream-42").Return(nil, nil)
+		f.repo.On("GetUserIdentitiesByUserID", mock.Anything, 5).Return([]model.UserIdentity{{UserID: 5, Provider: "stub", Subject: "upstream-7"}}, nil)
+
+		_, err := f.service.Callback(context.Background(), "stub", state, state, oidctest.Code, model.RequestMetadata{})
+		assert.ErrorIs(t, err, services.ErrProviderAlreadyLinked)
+	})
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	linked := []model.UserIdentity{{UserID: 9, Provider: "stub", Subject: "upstream-42"}}
+
+	t.Run("not linked", func(t *testing.T) {
+		f := newSocialLoginFixture(t)
+		f.repo.On("GetUserIdentitiesByUserID", mock.Anything, 9).Return([]model.UserIdentity{}, nil)
+
+		err := f.service.Unlink(context.Background(), 9, "ana", "stub", model.RequestMetadata{})
+		assert.ErrorIs(t, err, services.ErrIdentityNotFound)
+	})
+
+	t.Run("last login method of a user without password", func(t *testing.T) {
+		f := newSocialLoginFixture(t)
+		f.repo.On("GetUserIdentitiesByUserID", mock.Anything, 9).Return(linked, nil)
+		f.userRepo.On("GetUserByID", mock.Anything, 9).Return(&model.User{ID: 9, Username: "ana"}, nil)
+
+		err := f.service.Unlink(context.Background(), 9, "ana", "stub", model.RequestMetadata{})
+		assert.ErrorIs(t, err, services.ErrLastLoginMethod)
+		f.repo.AssertNotCalled(t, "DeleteUserIdentity", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("user with password", func(t *testing.T) {
+		f := newSocialLoginFixture(t)
+		f.repo.On("GetUserIdentitiesByUserID", mock.Anything, 9).Return(linked, nil)
+		f.userRepo.On("GetUserByID", mock.Anything, 9).Return(&model.User{ID: 9, Username: "ana", Password: "hash"}, nil)
+		f.repo.On("DeleteUserIdentity", mock.Anything, 9, "stub").Return(nil)
+
+		err := f.service.Unlink(context.Background(), 9, "ana", "stub", model.RequestMetadata{})
+		assert.NoError(t, err)
+		f.repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans of the services
+var tracer = otel.Tracer("exercise-login-back-go/internal/services")
+
+// endSpan records the outcome of an operation and ends its span. Every error
+// is recorded with its code, but only unexpected failures mark the span as
+// failed: wrong credentials or a taken email are normal outcomes.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetAttributes(attribute.String("error.code", errorCode(err)))
+		var serviceErr *Error
+		if !errors.As(err, &serviceErr) || serviceErr.Kind == KindInternal || serviceErr.Kind == KindUpstream {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
@@ -1,39 +1,78 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/metrics"
 	"exercise-login-back-go/internal/model"
 	"fmt"
-	"log"
-	"regexp"
+	"log/slog"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
-	RegisterUser(user model.UserRegistrationRequest) error
-	LoginUser(emailOrUsername, password string) (string, error)
+	RegisterUser(ctx context.Context, user model.UserRegistrationRequest, meta model.RequestMetadata) error
+	LoginUser(ctx context.Context, emailOrUsername, password string, meta model.RequestMetadata) (string, error)
+	LoginExternalUser(ctx context.Context, user model.User, meta model.RequestMetadata) (string, error)
+	ValidateToken(ctx context.Context, tokenString string) (*model.Claims, error)
+	UpdateLocale(ctx context.Context, userID int, locale string) error
 }
 
+// tokenLifetime is how long an issued JSON Web Token (JWT) remains valid.
+const tokenLifetime = 1 * time.Hour
+
+var (
+	// ErrUserDisabled is returned when a disabled user tries to sign in.
+	ErrUserDisabled = newError(KindLocked, "user_disabled", nil)
+	// ErrUserAlreadyExists is returned when the email or phone of a new user is taken.
+	ErrUserAlreadyExists = newError(KindConflict, "user_already_exists", nil)
+	// ErrInvalidToken is returned for tokens that are malformed, expired or revoked.
+	ErrInvalidToken = newError(KindUnauthorized, "invalid_token", nil)
+	// ErrTokenExpired is returned for tokens that are valid but past their expiry.
+	ErrTokenExpired = newError(KindUnauthorized, "invalid_token.expired", nil)
+	// ErrUserNotFound is returned when the user of a request no longer exists.
+	ErrUserNotFound = newError(KindNotFound, "user_not_found", nil)
+)
+
+// errCreatedUserMissing is returned when a user cannot be read back right
+// after it was created.
+var errCreatedUserMissing = errors.New("created user not found")
+
 type userServiceImpl struct {
-	repo      model.UserRepository
-	SecretKey string
+	repo              model.UserRepository
+	authenticator     Authenticator
+	sessionService    SessionService
+	loginAlertService LoginAlertService
+	auditLogger       AuditLogger
+	SecretKey         string
 }
 
-func NewUserService(repo model.UserRepository, secretKey string) *userServiceImpl {
+func NewUserService(repo model.UserRepository, authenticator Authenticator, sessionService SessionService, loginAlertService LoginAlertService, auditLogger AuditLogger, secretKey string) *userServiceImpl {
 	return &userServiceImpl{
-		repo:      repo,
-		SecretKey: secretKey,
+		repo:              repo,
+		authenticator:     authenticator,
+		sessionService:    sessionService,
+		loginAlertService: loginAlertService,
+		auditLogger:       auditLogger,
+		SecretKey:         secretKey,
 	}
 }
 
 // RegisterUser creates a new user in the system.
-func (s *userServiceImpl) RegisterUser(req model.UserRegistrationRequest) error {
-	var err error
+func (s *userServiceImpl) RegisterUser(ctx context.Context, req model.UserRegistrationRequest, meta model.RequestMetadata) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.RegisterUser")
+	defer func() { endSpan(span, err) }()
+
 	// Validate the user registration request
-	if err := s.ValidateRegistration(req); err != nil {
+	if err := s.ValidateRegistration(ctx, req); err != nil {
+		s.logEvent(ctx, model.AuditEventRegistration, nil, req.Username, meta, err)
 		return err
 	}
 
@@ -45,90 +84,55 @@ func (s *userServiceImpl) RegisterUser(req model.UserRegistrationRequest) error
 	}
 
 	// Hash the password
-	user.Password, err = hashPassword(req.Password)
+	user.Password, err = hashPassword(ctx, req.Password)
 	if err != nil {
+		s.logEvent(ctx, model.AuditEventRegistration, nil, req.Username, meta, err)
 		return err
 	}
 
 	// Save the user
-	if err := s.repo.CreateUser(user); err != nil {
-		log.Println(err.Error())
-		return errors.New("error al crear el usuario")
+	if err := s.repo.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, model.ErrDuplicateUser) {
+			// Another registration took the email or phone after the validation
+			err = ErrUserAlreadyExists
+		} else {
+			slog.ErrorContext(ctx, "Failed to create the user", "error", err)
+			err = fmt.Errorf("create user: %w", err)
+		}
+		s.logEvent(ctx, model.AuditEventRegistration, nil, req.Username, meta, err)
+		return err
 	}
 
+	s.logEvent(ctx, model.AuditEventRegistration, nil, req.Username, meta, nil)
 	return nil
 }
 
-// ValidateRegistration validates the user registration request.
-func (s *userServiceImpl) ValidateRegistration(req model.UserRegistrationRequest) error {
-	if !isValidEmail(req.Email) {
-		return fmt.Errorf("el formato del correo electrónico no es válido")
-	}
-	if !isValidPhone(req.Phone) {
-		return fmt.Errorf("el teléfono debe tener 10 dígitos")
-	}
-	if err := isValidPassword(req.Password); err != nil {
-		return err
-	}
-	existingUser, err := s.repo.GetUserByEmailOrPhone(req.Email, req.Phone)
+// ValidateRegistration checks that the email and phone of a registration
+// request are not taken. The format of the fields is validated by the API
+// with the validate tags of the request.
+func (s *userServiceImpl) ValidateRegistration(ctx context.Context, req model.UserRegistrationRequest) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ValidateRegistration")
+	defer func() { endSpan(span, err) }()
+
+	existingUser, err := s.repo.GetUserByEmailOrPhone(ctx, req.Email, req.Phone)
 	if err != nil {
-		return fmt.Errorf("error al verificar la existencia del usuario: %v", err)
+		return fmt.Errorf("error al verificar la existencia del usuario: %w", err)
 	}
 	if existingUser != nil {
-		return errors.New("el correo/telefono ya se encuentra registrado")
-	}
-	return nil
-}
-
-// isValidEmail determines if the email is in the correct format
-func isValidEmail(email string) bool {
-	re := regexp.MustCompile(`^[^@]+@[^@]+\.[^@]+`)
-	return re.MatchString(email)
-}
-
-// isValidPhone determines if the phone number is in the correct format
-func isValidPhone(phone string) bool {
-	re := regexp.MustCompile(`^\d{10}$`)
-	return re.MatchString(phone)
-}
-
-// Check if the password meets the requirements
-func isValidPassword(password string) error {
-	var (
-		hasMinLen  = len(password) >= 6
-		hasMaxLen  = len(password) <= 12
-		hasUpper   = regexp.MustCompile(`[A-Z]`).MatchString(password)
-		hasLower   = regexp.MustCompile(`[a-z]`).MatchString(password)
-		hasNumber  = regexp.MustCompile(`[0-9]`).MatchString(password)
-		hasSpecial = regexp.MustCompile(`[@$&]`).MatchString(password)
-	)
-
-	if !hasMinLen {
-		return fmt.Errorf("la contraseña debe tener al menos 6 caracteres")
-	}
-	if !hasMaxLen {
-		return fmt.Errorf("la contraseña debe tener máximo 12 caracteres")
-	}
-	if !hasUpper {
-		return fmt.Errorf("la contraseña debe incluir al menos una letra mayúscula")
-	}
-	if !hasLower {
-		return fmt.Errorf("la contraseña debe incluir al menos una letra minúscula")
-	}
-	if !hasNumber {
-		return fmt.Errorf("la contraseña debe incluir al menos un número")
+		return ErrUserAlreadyExists
 	}
-	if !hasSpecial {
-		return fmt.Errorf("la contraseña debe incluir al menos un carácter especial")
-	}
-
 	return nil
 }
 
 // hashPassword takes a password as a string and returns a hashed version of it as a string.
 // It uses the bcrypt library to hash the password.
-func hashPassword(password string) (string, error) {
+func hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := tracer.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer span.End()
+
+	start := time.Now()
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	metrics.PasswordHashDuration.WithLabelValues(metrics.OperationHash).Observe(time.Since(start).Seconds())
 	if err != nil {
 		return "", err
 	}
@@ -137,47 +141,168 @@ func hashPassword(password string) (string, error) {
 
 // LoginUser authenticates a user using their email or username and password.
 // If the credentials are valid, a JSON Web Token (JWT) is generated and returned.
-func (s *userServiceImpl) LoginUser(emailOrUsername, password string) (string, error) {
-	// Retrieve the user from the database based on the provided email or username.
-	user, err := s.repo.GetUserByEmailOrUsername(emailOrUsername)
+func (s *userServiceImpl) LoginUser(ctx context.Context, emailOrUsername, password string, meta model.RequestMetadata) (token string, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.LoginUser")
+	defer func() { endSpan(span, err) }()
+
+	// Verify the credentials with the authenticator in charge of the user.
+	user, err := s.authenticator.Authenticate(ctx, emailOrUsername, password)
 	if err != nil {
+		s.logEvent(ctx, model.AuditEventLogin, user, emailOrUsername, meta, err)
 		return "", err
 	}
-	if user == nil {
-		return "", errors.New("user not found")
+
+	return s.startSession(ctx, user, emailOrUsername, meta)
+}
+
+// LoginExternalUser issues a token for a user already authenticated by an
+// upstream identity provider.
+func (s *userServiceImpl) LoginExternalUser(ctx context.Context, user model.User, meta model.RequestMetadata) (token string, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.LoginExternalUser")
+	defer func() { endSpan(span, err) }()
+
+	return s.startSession(ctx, &user, user.Username, meta)
+}
+
+// startSession creates a session for an authenticated user and returns the
+// JSON Web Token (JWT) bound to it.
+func (s *userServiceImpl) startSession(ctx context.Context, user *model.User, actor string, meta model.RequestMetadata) (token string, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.startSession", trace.WithAttributes(attribute.Int("enduser.id", user.ID)))
+	defer func() { endSpan(span, err) }()
+
+	if user.Disabled {
+		s.logEvent(ctx, model.AuditEventLogin, user, actor, meta, ErrUserDisabled)
+		return "", ErrUserDisabled
 	}
 
-	// Compare the provided password with the hashed password in the database.
-	if err := s.checkPassword(user.Password, password); err != nil {
+	// Alert the user if the login comes from an unfamiliar device or network.
+	s.loginAlertService.CheckLogin(ctx, *user, meta)
+
+	// Record the session the token belongs to.
+	expirationTime := time.Now().Add(tokenLifetime)
+	session, err := s.sessionService.CreateSession(ctx, *user, meta, expirationTime)
+	if err != nil {
+		s.logEvent(ctx, model.AuditEventLogin, user, actor, meta, err)
 		return "", err
 	}
 
 	// Generate a JSON Web Token (JWT) and return it to the caller.
-	tokenString, err := s.createToken(user.Username)
+	tokenString, err := s.createToken(*user, session.ID, expirationTime)
 	if err != nil {
+		s.logEvent(ctx, model.AuditEventLogin, user, actor, meta, err)
 		return "", err
 	}
+
+	s.logEvent(ctx, model.AuditEventLogin, user, actor, meta, nil)
 	return tokenString, nil
 }
 
-// Verify the provided password
-func (s *userServiceImpl) checkPassword(hashedPassword, providedPassword string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(providedPassword))
+// ValidateToken parses a JSON Web Token (JWT) issued by createToken and returns its claims.
+func (s *userServiceImpl) ValidateToken(ctx context.Context, tokenString string) (claims *model.Claims, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ValidateToken")
+	defer func() { endSpan(span, err) }()
+
+	claims = &model.Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.SecretKey), nil
+	})
+	var validationErr *jwt.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Errors == jwt.ValidationErrorExpired {
+		// The signature is valid and expiry is the only problem
+		return nil, ErrTokenExpired
+	}
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	// Tokens are only valid while their session has not been revoked.
+	if err := s.sessionService.ValidateSession(ctx, claims.Id, claims.UserID); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// UpdateLocale saves the language the user prefers for messages. An empty
+// locale removes the preference, so the Accept-Language header is followed.
+// Session tokens carry the locale of the login, so it applies to the tokens
+// issued from then on.
+func (s *userServiceImpl) UpdateLocale(ctx context.Context, userID int, locale string) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateLocale", trace.WithAttributes(attribute.Int("enduser.id", userID)))
+	defer func() { endSpan(span, err) }()
+
+	if locale != "" {
+		parsed, ok := i18n.ParseLocale(locale)
+		if !ok {
+			return invalidField("locale", "invalid_locale", nil)
+		}
+		locale = string(parsed)
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
 	if err != nil {
-		return errors.New("contraseña incorrecta")
+		return err
 	}
-	return nil
+	if user == nil {
+		return ErrUserNotFound
+	}
+	user.Locale = locale
+	return s.repo.UpdateUser(ctx, *user)
 }
 
-// Create a new JSON Web Token (JWT)
-func (s *userServiceImpl) createToken(username string) (string, error) {
-	// Set the expiration time for the token to be 1 hour from now
-	expirationTime := time.Now().Add(1 * time.Hour)
+// logEvent records the outcome of an authentication action in the audit log.
+// A nil err means the action succeeded.
+func (s *userServiceImpl) logEvent(ctx context.Context, eventType string, user *model.User, actor string, meta model.RequestMetadata, err error) {
+	event := model.AuditEvent{
+		EventType: eventType,
+		Actor:     actor,
+		IPAddress: meta.IPAddress,
+		UserAgent: meta.UserAgent,
+		Outcome:   model.AuditOutcomeSuccess,
+	}
+	if user != nil {
+		event.UserID = user.ID
+		event.Actor = user.Username
+	}
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Detail = err.Error()
+	}
+	s.auditLogger.LogEvent(ctx, event)
+	countEvent(eventType, err)
+}
+
+// countEvent updates the counter of logins or registrations with the outcome
+// of an action and, for failures, its error code
+func countEvent(eventType string, err error) {
+	var counter *prometheus.CounterVec
+	switch eventType {
+	case model.AuditEventLogin:
+		counter = metrics.Logins
+	case model.AuditEventRegistration:
+		counter = metrics.Registrations
+	default:
+		return
+	}
+	if err != nil {
+		counter.WithLabelValues(metrics.ResultFailure, errorCode(err)).Inc()
+		return
+	}
+	counter.WithLabelValues(metrics.ResultSuccess, "").Inc()
+}
 
+// Create a new JSON Web Token (JWT) bound to the given session
+func (s *userServiceImpl) createToken(user model.User, sessionID string, expirationTime time.Time) (string, error) {
 	// Create the claims for the token
 	claims := &model.Claims{
-		Username: username,
+		UserID:   user.ID,
+		Username: user.Username,
+		Locale:   user.Locale,
 		StandardClaims: jwt.StandardClaims{
+			Id:        sessionID,
+			IssuedAt:  time.Now().Unix(),
 			ExpiresAt: expirationTime.Unix(),
 			Issuer:    "LOGIN-EXERCISE-TOKEN",
 		},
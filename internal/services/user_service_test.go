@@ -1,62 +1,310 @@
 package services_test
 
 import (
+	"context"
+	"errors"
+	"exercise-login-back-go/internal/metrics"
 	"exercise-login-back-go/internal/mocks"
 	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/repositories"
 	"exercise-login-back-go/internal/services"
 	"testing"
+	"time"
 
+	"github.com/dgrijalva/jwt-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestValidateRegistration(t *testing.T) {
 	mockRepo := new(mocks.UserRepository)
-	service := services.NewUserService(mockRepo, "dummySecret")
+	service := services.NewUserService(mockRepo, services.NewPasswordAuthenticator(mockRepo), new(mocks.SessionService), new(mocks.LoginAlertService), new(mocks.AuditLogger), "dummySecret")
+
+	t.Run("User Exists", func(t *testing.T) {
+		mockRepo.On("GetUserByEmailOrPhone", mock.Anything, "test@example.com", "1234567890").Return(&model.User{}, nil)
 
-	t.Run("Invalid Email", func(t *testing.T) {
 		req := model.UserRegistrationRequest{
-			Email:    "invalidEmail",
+			Email:    "test@example.com",
 			Phone:    "1234567890",
 			Password: "Password@123",
 		}
-		err := service.ValidateRegistration(req)
+		err := service.ValidateRegistration(context.Background(), req)
 		assert.Error(t, err)
-		assert.Equal(t, "el formato del correo electrónico no es válido", err.Error())
+		assert.Equal(t, "el correo/telefono ya se encuentra registrado", err.Error())
+		assert.ErrorIs(t, err, services.ErrConflict)
+		mockRepo.AssertExpectations(t)
 	})
+}
 
-	t.Run("Invalid Phone", func(t *testing.T) {
-		req := model.UserRegistrationRequest{
-			Email:    "test@example.com",
-			Phone:    "123",
-			Password: "Password@123",
-		}
-		err := service.ValidateRegistration(req)
+func TestRegisterUserCreateErrors(t *testing.T) {
+	mockRepo := new(mocks.UserRepository)
+	mockAudit := new(mocks.AuditLogger)
+	service := services.NewUserService(mockRepo, services.NewPasswordAuthenticator(mockRepo), new(mocks.SessionService), new(mocks.LoginAlertService), mockAudit, "dummySecret")
+	req := model.UserRegistrationRequest{Username: "testuser", Email: "test@example.com", Password: "Password@123"}
+	mockRepo.On("GetUserByEmailOrPhone", mock.Anything, "test@example.com", "").Return(nil, nil)
+	mockAudit.On("LogEvent", mock.Anything, mock.Anything).Return()
+
+	t.Run("Unique Violation", func(t *testing.T) {
+		mockRepo.On("CreateUser", mock.Anything, mock.AnythingOfType("model.User")).Return(model.ErrDuplicateUser).Once()
+
+		err := service.RegisterUser(context.Background(), req, model.RequestMetadata{})
+		assert.ErrorIs(t, err, services.ErrUserAlreadyExists)
+		assert.ErrorIs(t, err, services.ErrConflict)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		dbErr := errors.New("connection reset")
+		mockRepo.On("CreateUser", mock.Anything, mock.AnythingOfType("model.User")).Return(dbErr).Once()
+
+		err := service.RegisterUser(context.Background(), req, model.RequestMetadata{})
+		assert.ErrorIs(t, err, dbErr)
+	})
+}
+
+func TestLoginUserAuditEvents(t *testing.T) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("Password@123"), bcrypt.MinCost)
+	meta := model.RequestMetadata{IPAddress: "10.0.0.1", UserAgent: "test-agent"}
+
+	t.Run("User Not Found", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockSessions := new(mocks.SessionService)
+		mockAlerts := new(mocks.LoginAlertService)
+		mockAudit := new(mocks.AuditLogger)
+		service := services.NewUserService(mockRepo, services.NewPasswordAuthenticator(mockRepo), mockSessions, mockAlerts, mockAudit, "dummySecret")
+
+		mockRepo.On("GetUserByEmailOrUsername", mock.Anything, "missing").Return(nil, nil)
+		mockAudit.On("LogEvent", mock.Anything, mock.MatchedBy(func(event model.AuditEvent) bool {
+			return event.EventType == model.AuditEventLogin &&
+				event.Outcome == model.AuditOutcomeFailure &&
+				event.Actor == "missing" &&
+				event.IPAddress == "10.0.0.1"
+		})).Return()
+
+		_, err := service.LoginUser(context.Background(), "missing", "Password@123", meta)
 		assert.Error(t, err)
-		assert.Equal(t, "el teléfono debe tener 10 dígitos", err.Error())
+		mockAudit.AssertExpectations(t)
 	})
 
-	t.Run("Invalid Password", func(t *testing.T) {
-		req := model.UserRegistrationRequest{
-			Email:    "test@example.com",
-			Phone:    "1234567890",
-			Password: "pass",
-		}
-		err := service.ValidateRegistration(req)
+	t.Run("Wrong Password", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockSessions := new(mocks.SessionService)
+		mockAlerts := new(mocks.LoginAlertService)
+		mockAudit := new(mocks.AuditLogger)
+		service := services.NewUserService(mockRepo, services.NewPasswordAuthenticator(mockRepo), mockSessions, mockAlerts, mockAudit, "dummySecret")
+
+		mockRepo.On("GetUserByEmailOrUsername", mock.Anything, "test@example.com").Return(&model.User{ID: 7, Username: "testuser", Password: string(hashedPassword)}, nil)
+		mockAudit.On("LogEvent", mock.Anything, mock.MatchedBy(func(event model.AuditEvent) bool {
+			return event.Outcome == model.AuditOutcomeFailure && event.UserID == 7
+		})).Return()
+
+		_, err := service.LoginUser(context.Background(), "test@example.com", "Wrong@123", meta)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "la contraseña debe tener al menos 6 caracteres")
+		mockAudit.AssertExpectations(t)
 	})
 
-	t.Run("User Exists", func(t *testing.T) {
-		mockRepo.On("GetUserByEmailOrPhone", "test@example.com", "1234567890").Return(&model.User{}, nil)
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockSessions := new(mocks.SessionService)
+		mockAlerts := new(mocks.LoginAlertService)
+		mockAudit := new(mocks.AuditLogger)
+		service := services.NewUserService(mockRepo, services.NewPasswordAuthenticator(mockRepo), mockSessions, mockAlerts, mockAudit, "dummySecret")
 
-		req := model.UserRegistrationRequest{
-			Email:    "test@example.com",
-			Phone:    "1234567890",
-			Password: "Password@123",
-		}
-		err := service.ValidateRegistration(req)
+		mockRepo.On("GetUserByEmailOrUsername", mock.Anything, "test@example.com").Return(&model.User{ID: 7, Username: "testuser", Password: string(hashedPassword)}, nil)
+		mockAudit.On("LogEvent", mock.Anything, mock.MatchedBy(func(event model.AuditEvent) bool {
+			return event.Outcome == model.AuditOutcomeSuccess &&
+				event.UserID == 7 &&
+				event.Actor == "testuser" &&
+				event.UserAgent == "test-agent"
+		})).Return()
+		mockAlerts.On("CheckLogin", mock.Anything, mock.AnythingOfType("model.User"), meta).Return()
+		mockSessions.On("CreateSession", mock.Anything, mock.AnythingOfType("model.User"), meta, mock.AnythingOfType("time.Time")).
+			Return(&model.Session{ID: "session-1", UserID: 7}, nil)
+		mockSessions.On("ValidateSession", mock.Anything, "session-1", 7).Return(nil)
+
+		token, err := service.LoginUser(context.Background(), "test@example.com", "Password@123", meta)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+
+		claims, err := service.ValidateToken(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, "testuser", claims.Username)
+		assert.Equal(t, 7, claims.UserID)
+		assert.Equal(t, "session-1", claims.Id)
+		mockAudit.AssertExpectations(t)
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("Revoked Session", func(t *testing.T) {
+		mockRepo := new(mocks.UserRepository)
+		mockSessions := new(mocks.SessionService)
+		mockAlerts := new(mocks.LoginAlertService)
+		mockAudit := new(mocks.AuditLogger)
+		service := services.NewUserService(mockRepo, services.NewPasswordAuthenticator(mockRepo), mockSessions, mockAlerts, mockAudit, "dummySecret")
+
+		mockRepo.On("GetUserByEmailOrUsername", mock.Anything, "test@example.com").Return(&model.User{ID: 7, Username: "testuser", Password: string(hashedPassword)}, nil)
+		mockAudit.On("LogEvent", mock.Anything, mock.Anything).Return()
+		mockAlerts.On("CheckLogin", mock.Anything, mock.AnythingOfType("model.User"), meta).Return()
+		mockSessions.On("CreateSession", mock.Anything, mock.AnythingOfType("model.User"), meta, mock.AnythingOfType("time.Time")).
+			Return(&model.Session{ID: "session-1", UserID: 7}, nil)
+		mockSessions.On("ValidateSession", mock.Anything, "session-1", 7).Return(services.ErrSessionNotFound)
+
+		token, err := service.LoginUser(context.Background(), "test@example.com", "Password@123", meta)
+		assert.NoError(t, err)
+
+		_, err = service.ValidateToken(context.Background(), token)
 		assert.Error(t, err)
-		assert.Equal(t, "el correo/telefono ya se encuentra registrado", err.Error())
-		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestRegisterAndLoginWithMemoryRepository(t *testing.T) {
+	repo := repositories.NewMemoryUserRepository()
+	mockSessions := new(mocks.SessionService)
+	mockAlerts := new(mocks.LoginAlertService)
+	mockAudit := new(mocks.AuditLogger)
+	service := services.NewUserService(repo, services.NewPasswordAuthenticator(repo), mockSessions, mockAlerts, mockAudit, "dummySecret")
+	meta := model.RequestMetadata{IPAddress: "10.0.0.1"}
+	mockAudit.On("LogEvent", mock.Anything, mock.Anything).Return()
+
+	req := model.UserRegistrationRequest{Username: "testuser", Email: "test@example.com", Phone: "1234567890", Password: "Password@123"}
+	assert.NoError(t, service.RegisterUser(context.Background(), req, meta))
+
+	t.Run("Duplicate Phone", func(t *testing.T) {
+		duplicate := model.UserRegistrationRequest{Username: "other", Email: "other@example.com", Phone: "1234567890", Password: "Password@123"}
+		err := service.RegisterUser(context.Background(), duplicate, meta)
+		assert.EqualError(t, err, "el correo/telefono ya se encuentra registrado")
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		mockAlerts.On("CheckLogin", mock.Anything, mock.AnythingOfType("model.User"), meta).Return()
+		mockSessions.On("CreateSession", mock.Anything, mock.MatchedBy(func(user model.User) bool { return user.ID == 1 }), meta, mock.AnythingOfType("time.Time")).
+			Return(&model.Session{ID: "session-1", UserID: 1}, nil)
+
+		token, err := service.LoginUser(context.Background(), "testuser", "Password@123", meta)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+
+		_, err = service.LoginUser(context.Background(), "test@example.com", "Wrong@123", meta)
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateTokenErrorKeys(t *testing.T) {
+	service := services.NewUserService(new(mocks.UserRepository), nil, new(mocks.SessionService), new(mocks.LoginAlertService), new(mocks.AuditLogger), "dummySecret")
+	sign := func(secret string, expiresAt time.Time) string {
+		claims := model.Claims{UserID: 7, StandardClaims: jwt.StandardClaims{Id: "session-1", ExpiresAt: expiresAt.Unix()}}
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"expired", sign("dummySecret", time.Now().Add(-time.Minute)), "invalid_token.expired"},
+		{"expired with wrong signature", sign("otherSecret", time.Now().Add(-time.Minute)), "invalid_token"},
+		{"malformed", "not-a-token", "invalid_token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ValidateToken(context.Background(), tt.token)
+			assert.ErrorIs(t, err, services.ErrInvalidToken)
+			var serviceErr *services.Error
+			if assert.ErrorAs(t, err, &serviceErr) {
+				assert.Equal(t, tt.want, serviceErr.Key)
+			}
+		})
+	}
+}
+
+func TestUpdateLocale(t *testing.T) {
+	repo := repositories.NewMemoryUserRepository()
+	service := services.NewUserService(repo, services.NewPasswordAuthenticator(repo), new(mocks.SessionService), new(mocks.LoginAlertService), new(mocks.AuditLogger), "dummySecret")
+	assert.NoError(t, repo.CreateUser(context.Background(), model.User{Username: "testuser", Email: "test@example.com", Password: "hash"}))
+
+	assert.NoError(t, service.UpdateLocale(context.Background(), 1, "en-US"))
+	user, _ := repo.GetUserByID(context.Background(), 1)
+	assert.Equal(t, "en", user.Locale)
+
+	err := service.UpdateLocale(context.Background(), 1, "fr")
+	assert.ErrorIs(t, err, services.ErrValidation)
+	var serviceErr *services.Error
+	if assert.ErrorAs(t, err, &serviceErr) {
+		assert.Equal(t, "locale", serviceErr.Field)
+		assert.Equal(t, "invalid_locale", serviceErr.Key)
+	}
+
+	assert.NoError(t, service.UpdateLocale(context.Background(), 1, ""))
+	user, _ = repo.GetUserByID(context.Background(), 1)
+	assert.Empty(t, user.Locale)
+
+	assert.ErrorIs(t, service.UpdateLocale(context.Background(), 99, "es"), services.ErrNotFound)
+}
+
+func TestAuthenticationMetrics(t *testing.T) {
+	repo := repositories.NewMemoryUserRepository()
+	mockSessions := new(mocks.SessionService)
+	mockAlerts := new(mocks.LoginAlertService)
+	mockAudit := new(mocks.AuditLogger)
+	service := services.NewUserService(repo, services.NewPasswordAuthenticator(repo), mockSessions, mockAlerts, mockAudit, "dummySecret")
+	meta := model.RequestMetadata{IPAddress: "10.0.0.1"}
+	mockAudit.On("LogEvent", mock.Anything, mock.Anything).Return()
+	mockAlerts.On("CheckLogin", mock.Anything, mock.Anything, meta).Return()
+	mockSessions.On("CreateSession", mock.Anything, mock.Anything, meta, mock.Anything).Return(&model.Session{ID: "session-1", UserID: 1}, nil)
+
+	// The metrics are global, so only their changes are checked.
+	registered := metrics.Registrations.WithLabelValues(metrics.ResultSuccess, "")
+	duplicated := metrics.Registrations.WithLabelValues(metrics.ResultFailure, "user_already_exists")
+	loggedIn := metrics.Logins.WithLabelValues(metrics.ResultSuccess, "")
+	rejected := metrics.Logins.WithLabelValues(metrics.ResultFailure, "invalid_credentials")
+	before := []float64{testutil.ToFloat64(registered), testutil.ToFloat64(duplicated), testutil.ToFloat64(loggedIn), testutil.ToFloat64(rejected)}
+
+	req := model.UserRegistrationRequest{Username: "metrics", Email: "metrics@example.com", Phone: "5512345678", Password: "Password@123"}
+	assert.NoError(t, service.RegisterUser(context.Background(), req, meta))
+	assert.Error(t, service.RegisterUser(context.Background(), req, meta))
+	_, err := service.LoginUser(context.Background(), "metrics", "Password@123", meta)
+	assert.NoError(t, err)
+	_, err = service.LoginUser(context.Background(), "metrics", "Wrong@123", meta)
+	assert.Error(t, err)
+
+	assert.Equal(t, before[0]+1, testutil.ToFloat64(registered))
+	assert.Equal(t, before[1]+1, testutil.ToFloat64(duplicated))
+	assert.Equal(t, before[2]+1, testutil.ToFloat64(loggedIn))
+	assert.Equal(t, before[3]+1, testutil.ToFloat64(rejected))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.PasswordHashDuration))
+}
+
+func TestLoginUserSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("Password@123"), bcrypt.MinCost)
+	mockRepo := new(mocks.UserRepository)
+	mockAudit := new(mocks.AuditLogger)
+	service := services.NewUserService(mockRepo, services.NewPasswordAuthenticator(mockRepo), new(mocks.SessionService), new(mocks.LoginAlertService), mockAudit, "dummySecret")
+	mockRepo.On("GetUserByEmailOrUsername", mock.Anything, "testuser").Return(&model.User{ID: 7, Username: "testuser", Password: string(hashedPassword)}, nil)
+	mockAudit.On("LogEvent", mock.Anything, mock.Anything).Return()
+
+	_, err := service.LoginUser(context.Background(), "testuser", "Wrong@123", model.RequestMetadata{})
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	compare, login := spans[0], spans[1]
+	assert.Equal(t, "bcrypt.CompareHashAndPassword", compare.Name())
+	assert.Equal(t, login.SpanContext().SpanID(), compare.Parent().SpanID())
+	assert.Equal(t, "UserService.LoginUser", login.Name())
+	assert.Contains(t, login.Attributes(), attribute.String("error.code", "invalid_credentials"))
+	// Wrong credentials are an expected outcome, not a failure of the service.
+	assert.Equal(t, codes.Unset, login.Status().Code)
+}
@@ -0,0 +1,74 @@
+// Package tracing configures OpenTelemetry: the exporter of the spans and
+// the W3C trace context propagation between services.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporters of the spans
+const (
+	// ExporterNone disables tracing; the trace context is still propagated.
+	ExporterNone = "none"
+	// ExporterOTLP sends the spans to an OpenTelemetry collector over
+	// OTLP/HTTP, configured by the standard OTEL_EXPORTER_OTLP_* variables.
+	ExporterOTLP = "otlp"
+	// ExporterStdout prints the spans, for local debugging.
+	ExporterStdout = "stdout"
+)
+
+// ServiceName identifies the spans of this server unless OTEL_SERVICE_NAME
+// says otherwise
+const ServiceName = "exercise-login-back-go"
+
+// Setup installs the global tracer provider with the given exporter and
+// the W3C trace context and baggage propagators. The returned function
+// flushes the pending spans and stops the exporter.
+func Setup(ctx context.Context, exporter string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The variables OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override
+	// the default attributes.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sampler follows the sampling decision of the caller, and honors
+	// OTEL_TRACES_SAMPLER.
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,40 @@
+package tracing_test
+
+import (
+	"context"
+	"exercise-login-back-go/internal/tracing"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("propagates the W3C trace context", func(t *testing.T) {
+		shutdown, err := tracing.Setup(context.Background(), tracing.ExporterNone)
+		assert.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+
+		header := http.Header{}
+		header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+
+		span := trace.SpanContextFromContext(ctx)
+		assert.True(t, span.IsRemote())
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID().String())
+	})
+
+	t.Run("stdout exporter", func(t *testing.T) {
+		shutdown, err := tracing.Setup(context.Background(), tracing.ExporterStdout)
+		assert.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := tracing.Setup(context.Background(), "zipkin")
+		assert.EqualError(t, err, `unknown tracing exporter "zipkin"`)
+	})
+}
@@ -0,0 +1,180 @@
+// Package validation checks request models against the rules declared in
+// the validate tags of their fields, such as
+//
+//	Email string `json:"email" validate:"required,email,max=255"`
+//
+// Rules run in order and stop at the first failure of each field, so every
+// invalid field is reported once. Fields without the required rule are only
+// checked when they have a value.
+package validation
+
+import (
+	"exercise-login-back-go/internal/i18n"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes why a field was rejected: Key and Params identify the
+// message in the i18n catalog, and Code is the key without the variant.
+type FieldError struct {
+	Field  string
+	Code   string
+	Key    string
+	Params i18n.Params
+}
+
+// Rule checks the value of a field, named as in JSON, with the parameter of
+// the rule (the text after "=" in the tag). It returns the key and params of
+// the message explaining why the value is invalid, or an empty key.
+type Rule func(field string, value reflect.Value, param string) (string, i18n.Params)
+
+// rules holds the rules available to validate tags by name
+var rules = map[string]Rule{
+	"required": required,
+	"email":    stringRule(func(s string) string { return unless(IsEmail(s), "invalid_email") }),
+	"phone":    stringRule(func(s string) string { return unless(IsPhone(s), "invalid_phone") }),
+	"password": stringRule(CheckPassword),
+	"min":      minLength,
+	"max":      maxLength,
+}
+
+// Register adds a rule for the validate tags of new request types.
+func Register(name string, rule Rule) {
+	rules[name] = rule
+}
+
+// Struct validates the fields of a struct, or a pointer to one, and returns
+// the errors of all its invalid fields. It panics when a tag names an
+// unknown rule, which is a programming error.
+func Struct(v interface{}) []FieldError {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	structType := value.Type()
+
+	var errs []FieldError
+	for i := 0; i < structType.NumField(); i++ {
+		tag := structType.Field(i).Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		field := jsonName(structType.Field(i))
+		fieldValue := value.Field(i)
+		names := strings.Split(tag, ",")
+		if names[0] != "required" && fieldValue.IsZero() {
+			continue
+		}
+
+		for _, name := range names {
+			name, param, _ := strings.Cut(name, "=")
+			rule, ok := rules[name]
+			if !ok {
+				panic(fmt.Sprintf("validation: unknown rule %q on %s.%s", name, structType.Name(), structType.Field(i).Name))
+			}
+			if key, params := rule(field, fieldValue, param); key != "" {
+				code, _, _ := strings.Cut(key, ".")
+				errs = append(errs, FieldError{Field: field, Code: code, Key: key, Params: params})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// IsEmail reports whether s has the format of an email address
+func IsEmail(s string) bool {
+	return emailFormat.MatchString(s)
+}
+
+// IsPhone reports whether s is a phone number of 10 digits
+func IsPhone(s string) bool {
+	return phoneFormat.MatchString(s)
+}
+
+// CheckPassword returns the key of the first password requirement s does not
+// meet, or an empty key.
+func CheckPassword(s string) string {
+	switch {
+	case len(s) < 6:
+		return "invalid_password.too_short"
+	case len(s) > 12:
+		return "invalid_password.too_long"
+	case !upperCase.MatchString(s):
+		return "invalid_password.uppercase"
+	case !lowerCase.MatchString(s):
+		return "invalid_password.lowercase"
+	case !digit.MatchString(s):
+		return "invalid_password.number"
+	case !specialCharacter.MatchString(s):
+		return "invalid_password.special"
+	}
+	return ""
+}
+
+var (
+	emailFormat      = regexp.MustCompile(`^[^@]+@[^@]+\.[^@]+`)
+	phoneFormat      = regexp.MustCompile(`^\d{10}$`)
+	upperCase        = regexp.MustCompile(`[A-Z]`)
+	lowerCase        = regexp.MustCompile(`[a-z]`)
+	digit            = regexp.MustCompile(`[0-9]`)
+	specialCharacter = regexp.MustCompile(`[@$&]`)
+)
+
+// required rejects zero values and blank strings. Its message names the
+// field through the {field} parameter.
+func required(field string, value reflect.Value, _ string) (string, i18n.Params) {
+	if value.IsZero() || (value.Kind() == reflect.String && strings.TrimSpace(value.String()) == "") {
+		return "missing_field", i18n.Params{"field": field}
+	}
+	return "", nil
+}
+
+// minLength rejects strings shorter than the parameter, in characters
+func minLength(field string, value reflect.Value, param string) (string, i18n.Params) {
+	if utf8.RuneCountInString(value.String()) < mustAtoi(param) {
+		return "invalid_length.too_short", i18n.Params{"field": field, "min": param}
+	}
+	return "", nil
+}
+
+// maxLength rejects strings longer than the parameter, in characters
+func maxLength(field string, value reflect.Value, param string) (string, i18n.Params) {
+	if utf8.RuneCountInString(value.String()) > mustAtoi(param) {
+		return "invalid_length.too_long", i18n.Params{"field": field, "max": param}
+	}
+	return "", nil
+}
+
+// stringRule adapts a check of a string that returns a message key
+func stringRule(check func(string) string) Rule {
+	return func(_ string, value reflect.Value, _ string) (string, i18n.Params) {
+		return check(value.String()), nil
+	}
+}
+
+// unless returns key when the condition does not hold
+func unless(ok bool, key string) string {
+	if ok {
+		return ""
+	}
+	return key
+}
+
+func mustAtoi(param string) int {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("validation: invalid rule parameter %q", param))
+	}
+	return n
+}
+
+// jsonName returns the name of a field in JSON
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
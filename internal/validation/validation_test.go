@@ -0,0 +1,83 @@
+package validation_test
+
+import (
+	"exercise-login-back-go/internal/i18n"
+	"exercise-login-back-go/internal/model"
+	"exercise-login-back-go/internal/validation"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrationRequest(t *testing.T) {
+	valid := model.UserRegistrationRequest{Username: "testuser", Email: "test@example.com", Phone: "1234567890", Password: "Password@123"}
+	assert.Empty(t, validation.Struct(valid))
+
+	tests := []struct {
+		name  string
+		edit  func(*model.UserRegistrationRequest)
+		field string
+		key   string
+	}{
+		{"missing username", func(r *model.UserRegistrationRequest) { r.Username = "  " }, "username", "missing_field"},
+		{"long username", func(r *model.UserRegistrationRequest) { r.Username = strings.Repeat("a", 256) }, "username", "invalid_length.too_long"},
+		{"invalid email", func(r *model.UserRegistrationRequest) { r.Email = "invalidEmail" }, "email", "invalid_email"},
+		{"invalid phone", func(r *model.UserRegistrationRequest) { r.Phone = "123" }, "phone", "invalid_phone"},
+		{"short password", func(r *model.UserRegistrationRequest) { r.Password = "pass" }, "password", "invalid_password.too_short"},
+		{"long password", func(r *model.UserRegistrationRequest) { r.Password = "Password@1234" }, "password", "invalid_password.too_long"},
+		{"password without uppercase", func(r *model.UserRegistrationRequest) { r.Password = "password@1" }, "password", "invalid_password.uppercase"},
+		{"password without lowercase", func(r *model.UserRegistrationRequest) { r.Password = "PASSWORD@1" }, "password", "invalid_password.lowercase"},
+		{"password without number", func(r *model.UserRegistrationRequest) { r.Password = "Password@" }, "password", "invalid_password.number"},
+		{"password without special character", func(r *model.UserRegistrationRequest) { r.Password = "Password1" }, "password", "invalid_password.special"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.edit(&req)
+			errs := validation.Struct(&req)
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, tt.field, errs[0].Field)
+				assert.Equal(t, tt.key, errs[0].Key)
+				assert.Equal(t, strings.SplitN(tt.key, ".", 2)[0], errs[0].Code)
+			}
+		})
+	}
+}
+
+func TestAllFieldsAreReported(t *testing.T) {
+	errs := validation.Struct(model.UserLoginRequest{})
+	assert.Equal(t, []validation.FieldError{
+		{Field: "emailOrUsername", Code: "missing_field", Key: "missing_field", Params: i18n.Params{"field": "emailOrUsername"}},
+		{Field: "password", Code: "missing_field", Key: "missing_field", Params: i18n.Params{"field": "password"}},
+	}, errs)
+}
+
+func TestOptionalFieldsAreOnlyCheckedWithAValue(t *testing.T) {
+	type request struct {
+		Phone string `json:"phone" validate:"phone"`
+	}
+	assert.Empty(t, validation.Struct(request{}))
+	assert.Len(t, validation.Struct(request{Phone: "123"}), 1)
+}
+
+func TestRegister(t *testing.T) {
+	validation.Register("lowercase", func(field string, value reflect.Value, _ string) (string, i18n.Params) {
+		if value.String() != strings.ToLower(value.String()) {
+			return "invalid_case", i18n.Params{"field": field}
+		}
+		return "", nil
+	})
+	type request struct {
+		Code string `json:"code" validate:"required,lowercase,min=3"`
+	}
+	assert.Empty(t, validation.Struct(request{Code: "abc"}))
+	assert.Equal(t, []validation.FieldError{{Field: "code", Code: "invalid_case", Key: "invalid_case", Params: i18n.Params{"field": "code"}}}, validation.Struct(request{Code: "ABC"}))
+	assert.Equal(t, "invalid_length.too_short", validation.Struct(request{Code: "ab"})[0].Key)
+
+	type unknown struct {
+		Name string `validate:"nonexistent"`
+	}
+	assert.Panics(t, func() { validation.Struct(unknown{Name: "x"}) })
+}
@@ -2,27 +2,59 @@ package db
 
 import (
 	"database/sql"
-	"log"
+	"fmt"
+	"log/slog"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 
 	_ "github.com/denisenkom/go-mssqldb" // Importa el driver de SQL Server
+	_ "github.com/lib/pq"                // Importa el driver de PostgreSQL
+	_ "modernc.org/sqlite"               // Importa el driver de SQLite
 )
 
+// dbSystems maps the drivers to the database names used by OpenTelemetry
+var dbSystems = map[string]attribute.KeyValue{
+	"sqlserver": semconv.DBSystemMSSQL,
+	"mssql":     semconv.DBSystemMSSQL,
+	"postgres":  semconv.DBSystemPostgreSQL,
+	"sqlite":    semconv.DBSystemSqlite,
+}
+
 // InitializeDatabase abre una conexión a la base de datos y la retorna.
 // InitializeDatabase establishes a connection to the database and returns it.
+// Every query is traced with a span that carries its statement.
 func InitializeDatabase(dbDriver, dbSource string) (*sql.DB, error) {
-	db, err := sql.Open(dbDriver, dbSource)
+	var attributes []attribute.KeyValue
+	if system, ok := dbSystems[dbDriver]; ok {
+		attributes = append(attributes, system)
+	}
+	db, err := otelsql.Open(dbDriver, dbSource,
+		otelsql.WithAttributes(attributes...),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			// Keep one span per query rather than one per connection step.
+			OmitConnResetSession: true,
+			OmitConnectorConnect: true,
+			OmitRows:             true,
+		}))
 	if err != nil {
-		log.Fatalf("Failed to open database connection: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	// SQLite allows a single writer, and each connection to ":memory:" opens
+	// a different database.
+	if dbDriver == "sqlite" {
+		db.SetMaxOpenConns(1)
 	}
 
 	// Tests the connection with db.Ping()
 	err = db.Ping()
 	if err != nil {
-		log.Printf("Failed to ping the database: %v", err)
-		return nil, err
+		db.Close()
+		return nil, fmt.Errorf("failed to ping the database: %w", err)
 	}
 
-	log.Println("Database connection established successfully")
+	slog.Info("Database connection established successfully", "driver", dbDriver)
 	return db, nil
 }